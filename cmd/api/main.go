@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,12 +11,14 @@ import (
 	"time"
 
 	"github.com/bobarin/episod/internal/api"
+	"github.com/bobarin/episod/internal/app"
 	"github.com/bobarin/episod/internal/config"
 	"github.com/bobarin/episod/internal/db"
+	"github.com/bobarin/episod/internal/metrics"
 	"github.com/bobarin/episod/internal/queue"
 	"github.com/bobarin/episod/internal/services"
 	"github.com/bobarin/episod/internal/storage"
-	"github.com/bobarin/episod/internal/worker"
+	"github.com/bobarin/episod/internal/tracing"
 )
 
 func main() {
@@ -27,6 +30,19 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Structured logging from here on — log.Printf output goes through slog too
+	slog.SetDefault(app.NewLogger(cfg))
+
+	// Tracing (no-op unless OTEL_EXPORTER_OTLP_ENDPOINT is set)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		OTLPEndpoint: cfg.OTLPEndpoint,
+		ServiceName:  cfg.OTELServiceName,
+		SampleRatio:  cfg.TraceSampleRatio,
+	})
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+
 	// Connect to database
 	database, err := db.New(cfg.DatabaseURL)
 	if err != nil {
@@ -42,13 +58,18 @@ func main() {
 	}
 	defer q.Close()
 	log.Println("Connected to Redis queue")
+	metrics.RegisterQueueDepth(q.GetQueueLength, queue.QueueGeneratePlan, queue.QueueProcessClip, queue.QueueRenderFinal)
 
 	// Initialize storage
 	stor := storage.New(cfg.SupabaseURL, cfg.SupabaseServiceKey, cfg.SupabaseStorageBucket)
 	log.Println("Initialized Supabase storage")
 
+	// TTS fallback chain — used by the worker and the voice catalogue endpoints
+	ttsSvc := app.NewTTSService(cfg)
+	voiceCatalog := services.NewVoiceCatalog(ttsSvc, time.Duration(cfg.VoiceCatalogTTLSec)*time.Second)
+
 	// Create API handler
-	handler := api.NewHandler(database, q, stor)
+	handler := api.NewHandler(database, q, stor).WithVoices(voiceCatalog)
 	router := api.NewRouter(handler, api.RouterConfig{
 		BackendAPIKey:      cfg.BackendAPIKey,
 		CorsAllowedOrigins: cfg.CorsAllowedOrigins,
@@ -66,49 +87,38 @@ func main() {
 		Handler: router,
 	}
 
-	// Start worker if enabled
-	var workerCtx context.Context
+	// Prometheus metrics on an internal port, never on the public API listener
+	var metricsServer *http.Server
+	if cfg.MetricsPort != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		metricsServer = &http.Server{Addr: ":" + cfg.MetricsPort, Handler: mux}
+		go func() {
+			log.Printf("Metrics listening on :%s", cfg.MetricsPort)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Metrics server error: %v", err)
+			}
+		}()
+	}
+
+	// Start the embedded worker if enabled (or run cmd/worker separately
+	// to scale workers independently of the API)
 	var workerCancel context.CancelFunc
+	workerDone := make(chan struct{})
 	if cfg.WorkerEnabled {
 		log.Println("Worker enabled, starting background processing...")
 
-		// Initialize services
-		openaiSvc := services.NewOpenAIService(cfg.OpenAIKey)
-		geminiSvc := services.NewGeminiService(cfg.GeminiKey)
-		ffmpegSvc := services.NewFFmpegService("/tmp/episod", services.ParseResolution(cfg.RenderResolution))
-
-		// Initialize TTS provider — ElevenLabs preferred, Cartesia as legacy fallback
-		var ttsSvc services.TTSService
-		if cfg.ElevenLabsKey != "" {
-			ttsSvc = services.NewElevenLabsServiceWithVoice(cfg.ElevenLabsKey, cfg.ElevenLabsVoiceID)
-			log.Printf("TTS provider: ElevenLabs (voice: %s, model: eleven_flash_v2_5)", cfg.ElevenLabsVoiceID)
-		} else {
-			ttsSvc = services.NewCartesiaServiceWithVoice(cfg.CartesiaKey, cfg.CartesiaURL, cfg.CartesiaVoiceID)
-			log.Printf("TTS provider: Cartesia (legacy, voice: %s)", cfg.CartesiaVoiceID)
-		}
-
-		// Initialize Veo service (legacy, optional — nil when disabled)
-		var veoSvc *services.VeoService
-		if cfg.VeoEnabled {
-			veoSvc = services.NewVeoService(cfg.GeminiKey, cfg.VeoModel)
-			log.Printf("Veo video generation enabled (model: %s)", cfg.VeoModel)
-		}
-
-		// Initialize xAI Video service (preferred over Veo — nil when disabled)
-		var xaiVideoSvc *services.XAIVideoService
-		if cfg.XAIEnabled && cfg.XAIAPIKey != "" {
-			xaiVideoSvc = services.NewXAIVideoService(cfg.XAIAPIKey)
-			log.Println("xAI Grok Imagine Video generation enabled")
-		} else if !cfg.VeoEnabled {
-			log.Println("AI video generation disabled — using Ken Burns effects")
-		}
-
-		// Create worker
-		w := worker.New(database, q, stor, openaiSvc, ttsSvc, geminiSvc, veoSvc, xaiVideoSvc, ffmpegSvc, cfg.BackgroundMusicPath)
+		w := app.NewWorker(cfg, database, q, stor, ttsSvc)
 
 		// Start worker in background
+		var workerCtx context.Context
 		workerCtx, workerCancel = context.WithCancel(context.Background())
-		go w.Start(workerCtx, cfg.MaxConcurrentJobs)
+		go func() {
+			defer close(workerDone)
+			w.Start(workerCtx, app.WorkerConcurrency(cfg))
+		}()
+	} else {
+		close(workerDone)
 	}
 
 	// Start server in goroutine
@@ -126,10 +136,11 @@ func main() {
 
 	log.Println("Shutting down server...")
 
-	// Shutdown worker
+	// Shutdown worker: stop taking jobs and drain the in-flight ones
 	if workerCancel != nil {
 		workerCancel()
 	}
+	<-workerDone
 
 	// Shutdown HTTP server
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -138,6 +149,16 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			log.Printf("Metrics server forced to shutdown: %v", err)
+		}
+	}
+
+	// Flush pending spans
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("Failed to flush traces: %v", err)
+	}
 
 	log.Println("Server exited")
 }
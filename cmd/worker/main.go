@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bobarin/episod/internal/app"
+	"github.com/bobarin/episod/internal/config"
+	"github.com/bobarin/episod/internal/db"
+	"github.com/bobarin/episod/internal/metrics"
+	"github.com/bobarin/episod/internal/queue"
+	"github.com/bobarin/episod/internal/storage"
+	"github.com/bobarin/episod/internal/tracing"
+	"github.com/bobarin/episod/internal/worker"
+)
+
+// The worker command runs only the job pipeline, without the HTTP API, so
+// workers can be scaled separately — e.g. many render workers with
+// -plan-concurrency=0, and a few light planning workers with
+// -clip-concurrency=0 -render-concurrency=0.
+func main() {
+	log.Println("Starting Episod worker...")
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// Flags override the env config
+	defaults := app.WorkerConcurrency(cfg)
+	planConcurrency := flag.Int("plan-concurrency", defaults.GeneratePlan, "generate_plan jobs processed at once (0 = don't consume)")
+	clipConcurrency := flag.Int("clip-concurrency", defaults.ProcessClip, "process_clip jobs processed at once (0 = don't consume)")
+	renderConcurrency := flag.Int("render-concurrency", defaults.RenderFinal, "render_final jobs processed at once (0 = don't consume)")
+	drainTimeout := flag.Duration("drain-timeout", time.Duration(cfg.WorkerDrainTimeoutSec)*time.Second, "how long in-flight jobs may finish on SIGTERM before being requeued")
+	healthPort := flag.String("health-port", cfg.WorkerHealthPort, "port serving /health and /metrics (empty = disabled)")
+	flag.Parse()
+	cfg.WorkerDrainTimeoutSec = int(drainTimeout.Seconds())
+
+	// Structured logging from here on — log.Printf output goes through slog too
+	slog.SetDefault(app.NewLogger(cfg))
+
+	// Tracing (no-op unless OTEL_EXPORTER_OTLP_ENDPOINT is set)
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		OTLPEndpoint: cfg.OTLPEndpoint,
+		ServiceName:  cfg.OTELServiceName,
+		SampleRatio:  cfg.TraceSampleRatio,
+	})
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+
+	// Connect to database
+	database, err := db.New(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close()
+	log.Println("Connected to database")
+
+	// Connect to Redis queue
+	q, err := queue.New(cfg.RedisURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to queue: %v", err)
+	}
+	defer q.Close()
+	log.Println("Connected to Redis queue")
+	metrics.RegisterQueueDepth(q.GetQueueLength, queue.QueueGeneratePlan, queue.QueueProcessClip, queue.QueueRenderFinal)
+
+	// Initialize storage
+	stor := storage.New(cfg.SupabaseURL, cfg.SupabaseServiceKey, cfg.SupabaseStorageBucket)
+	log.Println("Initialized Supabase storage")
+
+	w := app.NewWorker(cfg, database, q, stor, app.NewTTSService(cfg))
+
+	// Health and metrics for the orchestrator and Prometheus
+	var healthServer *http.Server
+	if *healthPort != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/health", w.HealthHandler())
+		mux.Handle("/metrics", metrics.Handler())
+		healthServer = &http.Server{Addr: ":" + *healthPort, Handler: mux}
+		go func() {
+			log.Printf("Worker health listening on :%s", *healthPort)
+			if err := healthServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Health server error: %v", err)
+			}
+		}()
+	}
+
+	// SIGINT/SIGTERM stop taking jobs; Start returns once in-flight jobs have
+	// finished or been requeued
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	w.Start(ctx, worker.Concurrency{
+		GeneratePlan: *planConcurrency,
+		ProcessClip:  *clipConcurrency,
+		RenderFinal:  *renderConcurrency,
+	})
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if healthServer != nil {
+		if err := healthServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Health server forced to shutdown: %v", err)
+		}
+	}
+
+	// Flush pending spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Printf("Failed to flush traces: %v", err)
+	}
+
+	log.Println("Worker exited")
+}
@@ -3,12 +3,18 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/netip"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/bobarin/episod/internal/db"
+	"github.com/bobarin/episod/internal/joblog"
 	"github.com/bobarin/episod/internal/models"
 	"github.com/bobarin/episod/internal/queue"
+	"github.com/bobarin/episod/internal/services"
 	"github.com/bobarin/episod/internal/storage"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -18,6 +24,7 @@ type Handler struct {
 	db      *db.DB
 	queue   *queue.Queue
 	storage *storage.Storage
+	voices  *services.VoiceCatalog // nil = voice endpoints unavailable
 }
 
 func NewHandler(database *db.DB, q *queue.Queue, stor *storage.Storage) *Handler {
@@ -28,6 +35,16 @@ func NewHandler(database *db.DB, q *queue.Queue, stor *storage.Storage) *Handler
 	}
 }
 
+// WithVoices enables the voice catalogue endpoints (GET /v1/voices and
+// POST /v1/voices/{id}/preview).
+func (h *Handler) WithVoices(catalog *services.VoiceCatalog) *Handler {
+	h.voices = catalog
+	return h
+}
+
+// maxResearchSources bounds the sources a project can be grounded in.
+const maxResearchSources = 10
+
 // CreateProject handles POST /v1/projects
 func (h *Handler) CreateProject(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateProjectRequest
@@ -42,6 +59,65 @@ func (h *Handler) CreateProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Dialogue speaker map: roles and voice IDs must both be non-empty
+	var speakerVoices models.JSONB
+	if len(req.SpeakerVoices) > 0 {
+		speakerVoices = make(models.JSONB, len(req.SpeakerVoices))
+		for role, voiceID := range req.SpeakerVoices {
+			if role == "" || voiceID == "" {
+				respondError(w, http.StatusBadRequest, "speaker_voices entries need a role and a voice ID")
+				return
+			}
+			speakerVoices[role] = voiceID
+		}
+	}
+
+	if req.TTSProvider != nil && !services.IsKnownTTSProvider(*req.TTSProvider) {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Unknown tts_provider %q (expected %q or %q)", *req.TTSProvider, services.TTSProviderElevenLabs, services.TTSProviderCartesia))
+		return
+	}
+
+	if req.ClipFailurePolicy != nil && !models.ClipFailurePolicy(*req.ClipFailurePolicy).IsValid() {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("Unknown clip_failure_policy %q (expected %q, %q, %q or %q)", *req.ClipFailurePolicy,
+			models.ClipFailurePolicyFail, models.ClipFailurePolicyRewritePrompt, models.ClipFailurePolicyFallbackVisual, models.ClipFailurePolicySkip))
+		return
+	}
+
+	// Research sources: an http(s) URL or text each
+	var sources models.ResearchSources
+	if len(req.Sources) > maxResearchSources {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("At most %d sources are allowed", maxResearchSources))
+		return
+	}
+	for i, source := range req.Sources {
+		source.Title = strings.TrimSpace(source.Title)
+		source.URL = strings.TrimSpace(source.URL)
+		source.Text = strings.TrimSpace(source.Text)
+		if (source.URL == "") == (source.Text == "") {
+			respondError(w, http.StatusBadRequest, fmt.Sprintf("sources[%d] needs either a url or a text", i))
+			return
+		}
+		if source.URL != "" {
+			u, err := url.Parse(source.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				respondError(w, http.StatusBadRequest, fmt.Sprintf("sources[%d].url must be an http(s) URL", i))
+				return
+			}
+			// The worker only connects to public addresses; catch the obvious
+			// internal hosts early
+			addr, err := netip.ParseAddr(u.Hostname())
+			if strings.EqualFold(u.Hostname(), "localhost") || (err == nil && !services.IsPublicAddr(addr)) {
+				respondError(w, http.StatusBadRequest, fmt.Sprintf("sources[%d].url must point to a public host", i))
+				return
+			}
+		}
+		sources = append(sources, source)
+	}
+	if req.CitationEndCard && len(sources) == 0 {
+		respondError(w, http.StatusBadRequest, "citation_end_card needs sources")
+		return
+	}
+
 	// Set defaults
 	targetDuration := 60
 	if req.TargetDurationSeconds != nil {
@@ -83,6 +159,12 @@ func (h *Handler) CreateProject(w http.ResponseWriter, r *http.Request) {
 		MusicMood:             req.MusicMood,       // nil = use default music
 		SampleImageURL:        req.SampleImageURL,  // nil = use default sample.jpeg
 		Language:              language,
+		SpeakerVoices:         speakerVoices,       // nil = single narrator (or series voice profile)
+		TTSProvider:           req.TTSProvider,     // nil = configured fallback chain
+		BypassCache:           req.BypassCache,
+		ClipFailurePolicy:     req.ClipFailurePolicy, // nil = failed clips stay failed
+		Sources:               sources,               // nil = no research step
+		CitationEndCard:       req.CitationEndCard,
 	}
 
 	if err := h.db.CreateProject(r.Context(), project); err != nil {
@@ -108,6 +190,7 @@ func (h *Handler) CreateProject(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "Failed to enqueue job")
 		return
 	}
+	joblog.Event(r.Context(), "Project created", map[string]interface{}{"project_id": project.ID, "job_id": jobID})
 
 	// Return response
 	respondJSON(w, http.StatusCreated, models.CreateProjectResponse{
@@ -189,8 +272,15 @@ func (h *Handler) ListProjects(w http.ResponseWriter, r *http.Request) {
 			summary.ClipCount = count
 		}
 
-		// Thumbnail: clip 0's image URL
-		if thumbAssetID, err := h.db.GetProjectThumbnailAssetID(r.Context(), project.ID); err == nil && thumbAssetID != nil {
+		// Thumbnail: the cover in the project's aspect ratio, or clip 0's
+		// image until the covers are rendered
+		summary.Covers = h.buildCoverURLs(r.Context(), project.ID)
+		if summary.Covers != nil {
+			summary.ThumbnailURL = summary.Covers.Portrait
+			if (project.AspectRatio != nil && *project.AspectRatio == "16:9") || summary.ThumbnailURL == nil {
+				summary.ThumbnailURL = summary.Covers.Landscape
+			}
+		} else if thumbAssetID, err := h.db.GetProjectThumbnailAssetID(r.Context(), project.ID); err == nil && thumbAssetID != nil {
 			if asset, err := h.db.GetAsset(r.Context(), *thumbAssetID); err == nil {
 				url := h.storage.GetPublicURL(asset.StoragePath)
 				summary.ThumbnailURL = &url
@@ -259,9 +349,102 @@ func (h *Handler) GetProject(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Add sidecar subtitle and cover URLs if exported
+	response.Subtitles = h.buildSubtitleURLs(r.Context(), projectID)
+	response.Covers = h.buildCoverURLs(r.Context(), projectID)
+
+	// Add hook variants with their rendered videos
+	if variants, err := h.db.GetHookVariants(r.Context(), projectID); err == nil {
+		response.HookVariants = h.buildClipResponses(r.Context(), variants)
+	}
+
 	respondJSON(w, http.StatusOK, response)
 }
 
+// RenderHookVariant handles POST /v1/projects/{id}/hooks/{variant}/render:
+// generates the hook variant's first clip and renders the completed video
+// again with it. Returns 202 with the job ID.
+func (h *Handler) RenderHookVariant(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+	n, err := strconv.Atoi(chi.URLParam(r, "variant"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid hook variant")
+		return
+	}
+
+	project, err := h.db.GetProject(r.Context(), projectID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Project not found")
+		return
+	}
+	if project.Status != models.ProjectStatusCompleted {
+		respondError(w, http.StatusConflict, "Hook variants can be rendered once the project is completed")
+		return
+	}
+
+	variants, err := h.db.GetHookVariants(r.Context(), projectID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get hook variants")
+		return
+	}
+	var variant *models.Clip
+	for i := range variants {
+		if *variants[i].HookVariant == n {
+			variant = &variants[i]
+		}
+	}
+	if variant == nil {
+		respondError(w, http.StatusNotFound, "Hook variant not found")
+		return
+	}
+	if variant.VariantVideoAssetID != nil {
+		respondError(w, http.StatusConflict, "Hook variant already rendered")
+		return
+	}
+
+	jobs, err := h.db.GetProjectJobs(r.Context(), projectID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to get jobs")
+		return
+	}
+	for _, job := range jobs {
+		if job.ClipID != nil && *job.ClipID == variant.ID &&
+			(job.Status == models.JobStatusQueued || job.Status == models.JobStatusRunning) {
+			respondError(w, http.StatusConflict, "Hook variant is already rendering")
+			return
+		}
+	}
+
+	jobID := uuid.New()
+	job := &models.Job{
+		ID:        jobID,
+		ProjectID: projectID,
+		ClipID:    &variant.ID,
+		Type:      "process_clip",
+		Status:    models.JobStatusQueued,
+	}
+
+	if err := h.db.CreateJob(r.Context(), job); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create job")
+		return
+	}
+
+	if err := h.queue.EnqueueProcessClip(r.Context(), projectID, variant.ID, jobID); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to enqueue job")
+		return
+	}
+	joblog.Event(r.Context(), "Hook variant render requested", map[string]interface{}{"project_id": projectID, "hook_variant": n, "job_id": jobID})
+
+	respondJSON(w, http.StatusAccepted, map[string]interface{}{
+		"job_id":       jobID,
+		"hook_variant": n,
+	})
+}
+
 // GetProjectDownload handles GET /v1/projects/{id}/download
 func (h *Handler) GetProjectDownload(w http.ResponseWriter, r *http.Request) {
 	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
@@ -311,7 +494,60 @@ func (h *Handler) GetProjectJobs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondJSON(w, http.StatusOK, jobs)
+	responses := make([]models.JobResponse, len(jobs))
+	for i, job := range jobs {
+		responses[i] = models.JobResponse{Job: job}
+		if job.LogsAssetID == nil {
+			continue
+		}
+		if asset, err := h.db.GetAsset(r.Context(), *job.LogsAssetID); err == nil {
+			if url, err := h.storage.GetSignedURL(r.Context(), asset.StoragePath, 3600); err == nil {
+				responses[i].LogsURL = &url
+			}
+		}
+	}
+
+	respondJSON(w, http.StatusOK, responses)
+}
+
+// GetJobLogs handles GET /v1/projects/{id}/debug/jobs/{jobId}/logs,
+// returning the job's captured log as NDJSON.
+func (h *Handler) GetJobLogs(w http.ResponseWriter, r *http.Request) {
+	projectID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid project ID")
+		return
+	}
+	jobID, err := uuid.Parse(chi.URLParam(r, "jobId"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid job ID")
+		return
+	}
+
+	job, err := h.db.GetJob(r.Context(), jobID)
+	if err != nil || job.ProjectID != projectID {
+		respondError(w, http.StatusNotFound, "Job not found")
+		return
+	}
+	if job.LogsAssetID == nil {
+		respondError(w, http.StatusNotFound, "No logs stored for this job")
+		return
+	}
+
+	asset, err := h.db.GetAsset(r.Context(), *job.LogsAssetID)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Logs asset not found")
+		return
+	}
+	data, err := h.storage.Download(r.Context(), asset.StoragePath)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to download logs")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
 }
 
 // GetClip handles GET /v1/projects/{projectId}/clips/{clipId}
@@ -368,9 +604,61 @@ func (h *Handler) buildClipResponse(ctx context.Context, clip models.Clip) model
 		}
 	}
 
+	if clip.VariantVideoAssetID != nil {
+		if asset, err := h.db.GetAsset(ctx, *clip.VariantVideoAssetID); err == nil {
+			url := h.storage.GetPublicURL(asset.StoragePath)
+			response.VariantVideoURL = &url
+		}
+	}
+
 	return response
 }
 
+// buildSubtitleURLs returns public URLs for the project's sidecar caption files,
+// or nil when none have been exported yet.
+func (h *Handler) buildSubtitleURLs(ctx context.Context, projectID uuid.UUID) *models.SubtitleURLs {
+	urlFor := func(assetType models.AssetType) *string {
+		asset, err := h.db.GetProjectAssetByType(ctx, projectID, assetType)
+		if err != nil {
+			return nil
+		}
+		url := h.storage.GetPublicURL(asset.StoragePath)
+		return &url
+	}
+
+	subs := &models.SubtitleURLs{
+		SRT: urlFor(models.AssetTypeSubtitlesSRT),
+		VTT: urlFor(models.AssetTypeSubtitlesVTT),
+		ASS: urlFor(models.AssetTypeSubtitlesASS),
+	}
+	if subs.SRT == nil && subs.VTT == nil && subs.ASS == nil {
+		return nil
+	}
+	return subs
+}
+
+// buildCoverURLs returns public URLs for the project's covers, or nil when
+// none have been rendered yet.
+func (h *Handler) buildCoverURLs(ctx context.Context, projectID uuid.UUID) *models.CoverURLs {
+	urlFor := func(assetType models.AssetType) *string {
+		asset, err := h.db.GetProjectAssetByType(ctx, projectID, assetType)
+		if err != nil {
+			return nil
+		}
+		url := h.storage.GetPublicURL(asset.StoragePath)
+		return &url
+	}
+
+	covers := &models.CoverURLs{
+		Landscape: urlFor(models.AssetTypeCoverLandscape),
+		Portrait:  urlFor(models.AssetTypeCoverPortrait),
+	}
+	if covers.Landscape == nil && covers.Portrait == nil {
+		return nil
+	}
+	return covers
+}
+
 func respondJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -411,6 +699,224 @@ func (h *Handler) ListVisualStylePresets(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// ListPronunciations handles GET /v1/pronunciations
+// Query params:
+//   - user_id:   include the user's own entries
+//   - series_id: include the series' own entries
+//
+// Returns the effective lexicon (global entries plus the requested scopes, most
+// specific entry per term) — what the worker would apply to such a project.
+func (h *Handler) ListPronunciations(w http.ResponseWriter, r *http.Request) {
+	userID, err := parseOptionalUUID(r.URL.Query().Get("user_id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid user_id")
+		return
+	}
+	seriesID, err := parseOptionalUUID(r.URL.Query().Get("series_id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid series_id")
+		return
+	}
+
+	entries, err := h.db.ListPronunciations(r.Context(), userID, seriesID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list pronunciations")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"pronunciations": entries,
+		"count":          len(entries),
+	})
+}
+
+// CreatePronunciation handles POST /v1/pronunciations
+func (h *Handler) CreatePronunciation(w http.ResponseWriter, r *http.Request) {
+	var req models.CreatePronunciationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	req.Term = strings.TrimSpace(req.Term)
+	if req.Term == "" {
+		respondError(w, http.StatusBadRequest, "term is required")
+		return
+	}
+	alias := trimmedOrNil(req.Alias)
+	ipa := trimmedOrNil(req.IPA)
+	if alias == nil && ipa == nil {
+		respondError(w, http.StatusBadRequest, "alias or ipa is required")
+		return
+	}
+
+	// The scope must exist, or the insert fails on its foreign key
+	if req.UserID != nil {
+		if _, err := h.db.GetUser(r.Context(), *req.UserID); err != nil {
+			respondError(w, http.StatusNotFound, "User not found")
+			return
+		}
+	}
+	if req.SeriesID != nil {
+		if _, err := h.db.GetSeries(r.Context(), *req.SeriesID); err != nil {
+			respondError(w, http.StatusNotFound, "Series not found")
+			return
+		}
+	}
+
+	entry := &models.Pronunciation{
+		ID:       uuid.New(),
+		UserID:   req.UserID,
+		SeriesID: req.SeriesID,
+		Term:     req.Term,
+		Alias:    alias,
+		IPA:      ipa,
+	}
+	if err := h.db.CreatePronunciation(r.Context(), entry); err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to create pronunciation")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, entry)
+}
+
+// DeletePronunciation handles DELETE /v1/pronunciations/{id}
+func (h *Handler) DeletePronunciation(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "Invalid pronunciation ID")
+		return
+	}
+
+	if err := h.db.DeletePronunciation(r.Context(), id); err != nil {
+		respondError(w, http.StatusNotFound, "Pronunciation not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListVoices handles GET /v1/voices
+// Query params (all optional, exact match):
+//   - provider: "elevenlabs" or "cartesia"
+//   - language: ISO 639-1 code; matches any language the voice is verified for
+//   - gender:   "male", "female" or "neutral"
+func (h *Handler) ListVoices(w http.ResponseWriter, r *http.Request) {
+	if h.voices == nil {
+		respondError(w, http.StatusServiceUnavailable, "Voice catalogue is not available")
+		return
+	}
+
+	voices, err := h.voices.Voices(r.Context())
+	if err != nil {
+		respondError(w, http.StatusBadGateway, "Failed to list voices")
+		return
+	}
+
+	query := r.URL.Query()
+	provider, language, gender := query.Get("provider"), strings.ToLower(query.Get("language")), query.Get("gender")
+	filtered := make([]services.Voice, 0, len(voices))
+	for _, v := range voices {
+		if provider != "" && v.Provider != provider {
+			continue
+		}
+		if gender != "" && v.Gender != gender {
+			continue
+		}
+		if language != "" && v.Language != language && !containsString(v.Languages, language) {
+			continue
+		}
+		filtered = append(filtered, v)
+	}
+
+	respondJSON(w, http.StatusOK, map[string]interface{}{
+		"voices": filtered,
+		"count":  len(filtered),
+	})
+}
+
+// PreviewVoice handles POST /v1/voices/{id}/preview
+// Synthesizes a short sample with the voice and returns its URL. The body is
+// optional: provider disambiguates IDs, text and language customize the sample.
+func (h *Handler) PreviewVoice(w http.ResponseWriter, r *http.Request) {
+	if h.voices == nil {
+		respondError(w, http.StatusServiceUnavailable, "Voice catalogue is not available")
+		return
+	}
+
+	var req models.VoicePreviewRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if len(text) > services.MaxVoicePreviewChars {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("text must be at most %d characters", services.MaxVoicePreviewChars))
+		return
+	}
+
+	voice, err := h.voices.FindVoice(r.Context(), req.Provider, chi.URLParam(r, "id"))
+	if err != nil {
+		respondError(w, http.StatusNotFound, "Voice not found")
+		return
+	}
+
+	url, err := h.voices.Preview(r.Context(), *voice, text, req.Language, func(path string, audio []byte) (string, error) {
+		if err := h.storage.Upload(r.Context(), path, audio, "audio/mpeg"); err != nil {
+			return "", err
+		}
+		return h.storage.GetPublicURL(path), nil
+	})
+	if err != nil {
+		joblog.Warnf(r.Context(), "Voice preview for %s failed: %v", voice.ID, err)
+		respondError(w, http.StatusBadGateway, "Failed to generate preview")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, models.VoicePreviewResponse{
+		VoiceID:  voice.ID,
+		Provider: voice.Provider,
+		URL:      url,
+	})
+}
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOptionalUUID parses s as a UUID, returning nil for an empty string.
+func parseOptionalUUID(s string) (*uuid.UUID, error) {
+	if s == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// trimmedOrNil trims s and returns nil if nothing is left.
+func trimmedOrNil(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*s)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
 // strPtrDefault returns a *string with the given default if the input is nil or empty.
 func strPtrDefault(s *string, defaultVal string) *string {
 	if s == nil || *s == "" {
@@ -423,3 +929,16 @@ func strPtrDefault(s *string, defaultVal string) *string {
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 }
+
+// ListWorkers returns the heartbeats of the live worker processes (embedded
+// and cmd/worker), for checking how many consume each queue and whether any
+// is draining.
+func (h *Handler) ListWorkers(w http.ResponseWriter, r *http.Request) {
+	workers, err := h.queue.ListHeartbeats(r.Context())
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "Failed to list workers")
+		return
+	}
+
+	respondJSON(w, http.StatusOK, workers)
+}
@@ -2,8 +2,19 @@ package api
 
 import (
 	"crypto/subtle"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/metrics"
+	"github.com/bobarin/episod/internal/tracing"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // APIKeyAuth is middleware that validates requests against a backend API key.
@@ -45,3 +56,59 @@ func APIKeyAuth(apiKey string) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// RequestLogger logs each request as a structured line, records it in the
+// HTTP metrics and a server span (continuing an incoming traceparent), and
+// tags the request context with chi's request ID (must run after middleware.RequestID). Work
+// queued by the request carries the same ID, so the API log line and every
+// worker log line of a project share request_id. The ID is also echoed in
+// the X-Request-Id response header.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := middleware.GetReqID(r.Context())
+		if requestID != "" {
+			w.Header().Set(middleware.RequestIDHeader, requestID)
+		}
+		ctx := joblog.WithRequestID(r.Context(), requestID)
+		ctx, span := tracing.StartKind(tracing.ExtractHTTP(ctx, r.Header), "HTTP "+r.Method, trace.SpanKindServer,
+			semconv.HTTPRequestMethodKey.String(r.Method),
+			semconv.URLPath(r.URL.Path),
+		)
+		defer span.End()
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		metrics.ObserveHTTPRequest(r.Method, route, status, time.Since(start))
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		slog.Log(ctx, level, "HTTP request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", route,
+			"status", status,
+			"bytes", ww.BytesWritten(),
+			"duration_ms", time.Since(start).Milliseconds(),
+			"remote_addr", r.RemoteAddr,
+		)
+	})
+}
@@ -24,9 +24,9 @@ func NewRouter(h *Handler, cfg RouterConfig) *chi.Mux {
 	r := chi.NewRouter()
 
 	// Global middleware (applied to all routes including /health)
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
 	r.Use(middleware.RequestID)
+	r.Use(RequestLogger) // After RequestID: tags the request context with its ID
+	r.Use(middleware.Recoverer)
 
 	// CORS: restrict origins when configured, otherwise allow all (dev mode)
 	allowedOrigins := []string{"*"}
@@ -66,7 +66,9 @@ func NewRouter(h *Handler, cfg RouterConfig) *chi.Mux {
 		r.Post("/projects", h.CreateProject)
 		r.Get("/projects/{id}", h.GetProject)
 		r.Get("/projects/{id}/download", h.GetProjectDownload)
+		r.Post("/projects/{id}/hooks/{variant}/render", h.RenderHookVariant)
 		r.Get("/projects/{id}/debug/jobs", h.GetProjectJobs)
+		r.Get("/projects/{id}/debug/jobs/{jobId}/logs", h.GetJobLogs)
 
 		// Clips
 		r.Get("/projects/{projectId}/clips/{clipId}", h.GetClip)
@@ -74,6 +76,18 @@ func NewRouter(h *Handler, cfg RouterConfig) *chi.Mux {
 		// Presets — available creative options for project creation
 		r.Get("/presets/tones", h.ListTonePresets)
 		r.Get("/presets/visual-styles", h.ListVisualStylePresets)
+
+		// Voices — catalogue of the configured TTS providers' voices
+		r.Get("/voices", h.ListVoices)
+		r.Post("/voices/{id}/preview", h.PreviewVoice)
+
+		// Workers — live worker processes and their heartbeats
+		r.Get("/workers", h.ListWorkers)
+
+		// Pronunciation lexicon — how TTS says names and jargon
+		r.Get("/pronunciations", h.ListPronunciations)
+		r.Post("/pronunciations", h.CreatePronunciation)
+		r.Delete("/pronunciations/{id}", h.DeletePronunciation)
 	})
 
 	return r
@@ -0,0 +1,148 @@
+// Package app builds the pieces shared by the api and worker commands from
+// env config: the process logger, the TTS fallback chain and the worker.
+package app
+
+import (
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/bobarin/episod/internal/config"
+	"github.com/bobarin/episod/internal/db"
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/queue"
+	"github.com/bobarin/episod/internal/services"
+	"github.com/bobarin/episod/internal/storage"
+	"github.com/bobarin/episod/internal/worker"
+)
+
+// NewLogger builds the process logger from LOG_FORMAT and LOG_LEVEL. Records
+// are tagged with the request/project/clip/job IDs carried by their context.
+func NewLogger(cfg *config.Config) *slog.Logger {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		level = slog.LevelInfo
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	if strings.EqualFold(cfg.LogFormat, "text") {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	}
+	return slog.New(joblog.NewHandler(handler))
+}
+
+// NewTTSService builds the TTS providers as a fallback chain in TTS_PROVIDERS
+// order (default: ElevenLabs, then Cartesia). Providers without a key are skipped.
+func NewTTSService(cfg *config.Config) *services.ChainTTSService {
+	var ttsProviders []services.NamedTTSService
+	for _, name := range strings.Split(cfg.TTSProviders, ",") {
+		switch strings.TrimSpace(name) {
+		case services.TTSProviderElevenLabs:
+			if cfg.ElevenLabsKey == "" {
+				continue
+			}
+			ttsProviders = append(ttsProviders, services.NamedTTSService{
+				Name: services.TTSProviderElevenLabs,
+				Service: services.NewElevenLabsServiceWithVoice(cfg.ElevenLabsKey, cfg.ElevenLabsVoiceID).
+					WithModel(cfg.ElevenLabsModelID).
+					WithTimestamps(cfg.ElevenLabsTimestamps).
+					WithLanguageVoices(services.ParseLanguageVoices(cfg.ElevenLabsLanguageVoices)).
+					WithVoiceLanguagePolicy(services.ParseVoiceLanguagePolicy(cfg.ElevenLabsVoiceLanguagePolicy)),
+			})
+			log.Printf("TTS provider: ElevenLabs (voice: %s, model: %s, timestamps: %v, voice language policy: %s)", cfg.ElevenLabsVoiceID, cfg.ElevenLabsModelID, cfg.ElevenLabsTimestamps, services.ParseVoiceLanguagePolicy(cfg.ElevenLabsVoiceLanguagePolicy))
+		case services.TTSProviderCartesia:
+			if cfg.CartesiaKey == "" {
+				continue
+			}
+			ttsProviders = append(ttsProviders, services.NamedTTSService{
+				Name: services.TTSProviderCartesia,
+				Service: services.NewCartesiaServiceWithVoice(cfg.CartesiaKey, cfg.CartesiaURL, cfg.CartesiaVoiceID).
+					WithLanguageVoices(services.ParseLanguageVoices(cfg.CartesiaLanguageVoices)).
+					WithVoiceLanguagePolicy(services.ParseVoiceLanguagePolicy(cfg.CartesiaVoiceLanguagePolicy)),
+			})
+			log.Printf("TTS provider: Cartesia (voice: %s, voice language policy: %s)", cfg.CartesiaVoiceID, services.ParseVoiceLanguagePolicy(cfg.CartesiaVoiceLanguagePolicy))
+		default:
+			log.Printf("WARNING: unknown TTS provider %q in TTS_PROVIDERS, ignoring", name)
+		}
+	}
+	if len(ttsProviders) == 0 {
+		log.Fatalf("No TTS provider configured: TTS_PROVIDERS=%q has no provider with an API key", cfg.TTSProviders)
+	}
+	ttsSvc := services.NewChainTTSService(ttsProviders, cfg.TTSBreakerThreshold, time.Duration(cfg.TTSBreakerCooldownSec)*time.Second)
+	log.Printf("TTS fallback order: %v", ttsSvc.Providers())
+	return ttsSvc
+}
+
+// NewWorker builds the provider services the pipeline needs and the worker
+// around them.
+func NewWorker(cfg *config.Config, database *db.DB, q *queue.Queue, stor *storage.Storage, ttsSvc services.TTSService) *worker.Worker {
+	openaiSvc := services.NewOpenAIService(cfg.OpenAIKey)
+	geminiSvc := services.NewGeminiService(cfg.GeminiKey)
+	ffmpegSvc := services.NewFFmpegService("/tmp/episod", services.ParseResolution(cfg.RenderResolution))
+
+	// Initialize Veo service (legacy, optional — nil when disabled)
+	var veoSvc *services.VeoService
+	if cfg.VeoEnabled {
+		veoSvc = services.NewVeoService(cfg.GeminiKey, cfg.VeoModel)
+		log.Printf("Veo video generation enabled (model: %s)", cfg.VeoModel)
+	}
+
+	// Initialize xAI Video service (preferred over Veo — nil when disabled)
+	var xaiVideoSvc *services.XAIVideoService
+	if cfg.XAIEnabled && cfg.XAIAPIKey != "" {
+		xaiVideoSvc = services.NewXAIVideoService(cfg.XAIAPIKey)
+		log.Println("xAI Grok Imagine Video generation enabled")
+	} else if !cfg.VeoEnabled {
+		log.Println("AI video generation disabled — using Ken Burns effects")
+	}
+
+	// Research sources come from a local directory when one is configured
+	var sourceFetcher services.SourceFetcher
+	if cfg.ResearchSourceDir != "" {
+		sourceFetcher = &services.FileSourceFetcher{Root: cfg.ResearchSourceDir}
+	}
+
+	return worker.New(database, q, stor, openaiSvc, ttsSvc, geminiSvc, veoSvc, xaiVideoSvc, ffmpegSvc, worker.Config{
+		BackgroundMusicPath:  cfg.BackgroundMusicPath,
+		SubtitleAlignment:    services.ParseAlignmentMode(cfg.SubtitleAlignment),
+		ImageRewriteAttempts: cfg.ImageRewriteAttempts,
+		CacheEnabled:         cfg.ProviderCacheEnabled,
+		CacheTTL:             time.Duration(cfg.ProviderCacheTTLHours) * time.Hour,
+		CacheMaxEntries:      cfg.ProviderCacheMaxEntries,
+		DrainTimeout:         time.Duration(cfg.WorkerDrainTimeoutSec) * time.Second,
+		Reaper: worker.ReaperConfig{
+			Interval:          time.Duration(cfg.ReaperIntervalSec) * time.Second,
+			PlanningTimeout:   time.Duration(cfg.StallTimeoutPlanningSec) * time.Second,
+			GeneratingTimeout: time.Duration(cfg.StallTimeoutGeneratingSec) * time.Second,
+			RenderingTimeout:  time.Duration(cfg.StallTimeoutRenderingSec) * time.Second,
+			MaxRecoveries:     cfg.ReaperMaxRecoveries,
+		},
+		DurationFit: worker.DurationFitConfig{
+			Tolerance:      float64(cfg.DurationFitTolerancePct) / 100,
+			MaxSpeedChange: float64(cfg.DurationFitMaxSpeedChangePct) / 100,
+			MaxRounds:      cfg.DurationFitMaxRounds,
+		},
+		SourceFetcher: sourceFetcher,
+	})
+}
+
+// WorkerConcurrency is the per-queue concurrency from WORKER_*_CONCURRENCY,
+// each defaulting to MAX_CONCURRENT_JOBS.
+func WorkerConcurrency(cfg *config.Config) worker.Concurrency {
+	orDefault := func(n int) int {
+		if n <= 0 {
+			return cfg.MaxConcurrentJobs
+		}
+		return n
+	}
+	return worker.Concurrency{
+		GeneratePlan: orDefault(cfg.WorkerPlanConcurrency),
+		ProcessClip:  orDefault(cfg.WorkerClipConcurrency),
+		RenderFinal:  orDefault(cfg.WorkerRenderConcurrency),
+	}
+}
@@ -11,6 +11,7 @@ import (
 type Config struct {
 	// Server
 	APIPort            string
+	MetricsPort        string // Internal port of the API command's /metrics (empty = disabled)
 	WorkerEnabled      bool
 	BackendAPIKey      string // API key for authenticating requests (empty = no auth, dev mode)
 	CorsAllowedOrigins string // Comma-separated allowed origins (empty = *, dev mode)
@@ -41,13 +42,25 @@ type Config struct {
 	XAIAPIKey  string // xAI API key for Grok Imagine Video
 
 	// ElevenLabs (preferred TTS provider)
-	ElevenLabsKey     string
-	ElevenLabsVoiceID string
-
-	// Cartesia (legacy TTS provider — used when ElevenLabs key is not set)
-	CartesiaKey     string
-	CartesiaURL     string
-	CartesiaVoiceID string
+	ElevenLabsKey                 string
+	ElevenLabsVoiceID             string
+	ElevenLabsModelID             string // TTS model (default eleven_flash_v2_5; eleven_v3 enables audio tags)
+	ElevenLabsTimestamps          bool   // Use the with-timestamps endpoint for subtitle timings (skips Whisper)
+	ElevenLabsLanguageVoices      string // Per-language default voices, e.g. "es=voiceA,fr=voiceB"
+	ElevenLabsVoiceLanguagePolicy string // "warn" (default) or "reject" when a voice isn't verified in the project language
+
+	// Cartesia (second in the default TTS fallback chain)
+	CartesiaKey                 string
+	CartesiaURL                 string
+	CartesiaVoiceID             string
+	CartesiaLanguageVoices      string // Per-language default voices, e.g. "es=voiceA,fr=voiceB"
+	CartesiaVoiceLanguagePolicy string // "warn" (default) or "reject" when a voice doesn't speak the project language
+
+	// TTS fallback chain
+	TTSProviders          string // Comma-separated fallback order, e.g. "elevenlabs,cartesia" (providers without a key are skipped)
+	TTSBreakerThreshold   int    // Consecutive 429/5xx failures before a provider is skipped
+	TTSBreakerCooldownSec int    // Seconds a tripped provider is skipped before a trial request
+	VoiceCatalogTTLSec    int    // Seconds the aggregated provider voice list is cached (GET /v1/voices)
 
 	// Audio
 	BackgroundMusicPath string // Path to default background music file
@@ -55,8 +68,48 @@ type Config struct {
 	// Rendering
 	RenderResolution string // "1080p" (default, fast, good for TikTok/Reels) or "4k" (high quality)
 
+	// Image prompt rewriting
+	ImageRewriteAttempts int // Rewrites of a refused image prompt before the image fails (0 = disabled)
+
+	// Subtitles
+	SubtitleAlignment string // "script" (default: Whisper timings mapped onto the exact script) or "transcript" (raw Whisper text)
+
+	// Provider output cache
+	ProviderCacheEnabled    bool // Reuse earlier Gemini/TTS/xAI outputs for identical inputs
+	ProviderCacheTTLHours   int  // Hours a cached output is reused (0 = never expires)
+	ProviderCacheMaxEntries int  // Least recently used entries beyond this are evicted (0 = unlimited)
+
+	// Logging
+	LogFormat string // "json" (default: one structured line per record) or "text"
+	LogLevel  string // "debug", "info" (default), "warn" or "error"
+
+	// Tracing
+	OTLPEndpoint     string  // OTLP/HTTP endpoint for traces (empty = tracing disabled)
+	OTELServiceName  string  // Service name on exported spans
+	TraceSampleRatio float64 // Fraction of new traces recorded (0–1]
+
 	// Worker
-	MaxConcurrentJobs int
+	MaxConcurrentJobs       int
+	WorkerPlanConcurrency   int    // generate_plan jobs at once (0 = MaxConcurrentJobs)
+	WorkerClipConcurrency   int    // process_clip jobs at once (0 = MaxConcurrentJobs)
+	WorkerRenderConcurrency int    // render_final jobs at once (0 = MaxConcurrentJobs)
+	WorkerDrainTimeoutSec   int    // Seconds in-flight jobs may finish on shutdown before being requeued
+	WorkerHealthPort        string // Port of the worker command's /health and /metrics
+
+	// Stalled-project reaper
+	ReaperIntervalSec         int // Seconds between passes (0 = disabled)
+	StallTimeoutPlanningSec   int // Idle seconds before a queued/planning project counts as stalled
+	StallTimeoutGeneratingSec int // Idle seconds before a generating project counts as stalled
+	StallTimeoutRenderingSec  int // Idle seconds before a rendering project counts as stalled
+	ReaperMaxRecoveries       int // Recoveries per project before it is failed
+
+	// Duration fitting
+	DurationFitTolerancePct      int // Allowed deviation from the target length in percent (0 = disabled)
+	DurationFitMaxSpeedChangePct int // Largest speech speed change in percent
+	DurationFitMaxRounds         int // Adjust-and-measure rounds before rendering anyway
+
+	// Research mode
+	ResearchSourceDir string // Serve research source URLs from this directory instead of fetching them (empty = HTTP)
 }
 
 func Load() (*Config, error) {
@@ -65,6 +118,7 @@ func Load() (*Config, error) {
 
 	cfg := &Config{
 		APIPort:               getEnv("API_PORT", "8080"),
+		MetricsPort:           getEnv("METRICS_PORT", "9090"),
 		WorkerEnabled:         getEnvBool("WORKER_ENABLED", true),
 		BackendAPIKey:         getEnv("BACKEND_API_KEY", ""),
 		CorsAllowedOrigins:    getEnv("CORS_ALLOWED_ORIGINS", ""),
@@ -81,12 +135,46 @@ func Load() (*Config, error) {
 		XAIAPIKey:                 getEnv("XAI_API_KEY", ""),
 		ElevenLabsKey:             getEnv("ELEVENLABS_API_KEY", ""),
 		ElevenLabsVoiceID:        getEnv("ELEVENLABS_VOICE_ID", ""),
+		ElevenLabsModelID:        getEnv("ELEVENLABS_MODEL_ID", "eleven_flash_v2_5"),
+		ElevenLabsTimestamps:     getEnvBool("ELEVENLABS_TIMESTAMPS", false),
+		ElevenLabsLanguageVoices: getEnv("ELEVENLABS_LANGUAGE_VOICES", ""),
+		ElevenLabsVoiceLanguagePolicy: getEnv("ELEVENLABS_VOICE_LANGUAGE_POLICY", "warn"),
 		CartesiaKey:               getEnv("CARTESIA_API_KEY", ""),
 		CartesiaURL:           getEnv("CARTESIA_API_URL", "https://api.cartesia.ai"),
 		CartesiaVoiceID:       getEnv("CARTESIA_VOICE_ID", ""),
+		CartesiaLanguageVoices:      getEnv("CARTESIA_LANGUAGE_VOICES", ""),
+		CartesiaVoiceLanguagePolicy: getEnv("CARTESIA_VOICE_LANGUAGE_POLICY", "warn"),
+		TTSProviders:          getEnv("TTS_PROVIDERS", "elevenlabs,cartesia"),
+		TTSBreakerThreshold:   getEnvInt("TTS_BREAKER_THRESHOLD", 3),
+		TTSBreakerCooldownSec: getEnvInt("TTS_BREAKER_COOLDOWN_SEC", 60),
+		VoiceCatalogTTLSec:    getEnvInt("VOICE_CATALOG_TTL_SEC", 3600),
 		BackgroundMusicPath:   getEnv("BACKGROUND_MUSIC_PATH", "assets/music/music.mp3"),
 		RenderResolution:     getEnv("RENDER_RESOLUTION", "1080p"),
+		SubtitleAlignment:    getEnv("SUBTITLE_ALIGNMENT", "script"),
+		ImageRewriteAttempts: getEnvInt("IMAGE_REWRITE_ATTEMPTS", 2),
+		ProviderCacheEnabled:    getEnvBool("PROVIDER_CACHE_ENABLED", true),
+		ProviderCacheTTLHours:   getEnvInt("PROVIDER_CACHE_TTL_HOURS", 720),
+		ProviderCacheMaxEntries: getEnvInt("PROVIDER_CACHE_MAX_ENTRIES", 0),
+		LogFormat:             getEnv("LOG_FORMAT", "json"),
+		LogLevel:              getEnv("LOG_LEVEL", "info"),
+		OTLPEndpoint:          getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+		OTELServiceName:       getEnv("OTEL_SERVICE_NAME", "episod"),
+		TraceSampleRatio:      getEnvFloat("OTEL_TRACES_SAMPLE_RATIO", 1.0),
 		MaxConcurrentJobs:     getEnvInt("MAX_CONCURRENT_JOBS", 5),
+		WorkerPlanConcurrency:   getEnvInt("WORKER_PLAN_CONCURRENCY", 0),
+		WorkerClipConcurrency:   getEnvInt("WORKER_CLIP_CONCURRENCY", 0),
+		WorkerRenderConcurrency: getEnvInt("WORKER_RENDER_CONCURRENCY", 0),
+		WorkerDrainTimeoutSec:   getEnvInt("WORKER_DRAIN_TIMEOUT_SEC", 120),
+		WorkerHealthPort:        getEnv("WORKER_HEALTH_PORT", "8081"),
+		ReaperIntervalSec:         getEnvInt("REAPER_INTERVAL_SEC", 60),
+		StallTimeoutPlanningSec:   getEnvInt("STALL_TIMEOUT_PLANNING_SEC", 900),
+		StallTimeoutGeneratingSec: getEnvInt("STALL_TIMEOUT_GENERATING_SEC", 1800),
+		StallTimeoutRenderingSec:  getEnvInt("STALL_TIMEOUT_RENDERING_SEC", 1200),
+		ReaperMaxRecoveries:       getEnvInt("REAPER_MAX_RECOVERIES", 3),
+		DurationFitTolerancePct:      getEnvInt("DURATION_FIT_TOLERANCE_PCT", 10),
+		DurationFitMaxSpeedChangePct: getEnvInt("DURATION_FIT_MAX_SPEED_CHANGE_PCT", 10),
+		DurationFitMaxRounds:         getEnvInt("DURATION_FIT_MAX_ROUNDS", 2),
+		ResearchSourceDir:            getEnv("RESEARCH_SOURCE_DIR", ""),
 	}
 
 	// Validate required fields
@@ -140,3 +228,13 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		f, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
@@ -83,3 +83,61 @@ func (db *DB) GetProjectAssets(ctx context.Context, projectID uuid.UUID) ([]mode
 
 	return assets, nil
 }
+
+// GetClipAssetByType returns the most recent asset of the given type for a clip.
+func (db *DB) GetClipAssetByType(ctx context.Context, clipID uuid.UUID, assetType models.AssetType) (*models.Asset, error) {
+	query := `
+		SELECT
+			id, project_id, clip_id, type, storage_bucket,
+			storage_path, content_type, byte_size, created_at
+		FROM assets
+		WHERE clip_id = $1 AND type = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	asset := &models.Asset{}
+	err := db.QueryRowContext(ctx, query, clipID, assetType).Scan(
+		&asset.ID, &asset.ProjectID, &asset.ClipID, &asset.Type,
+		&asset.StorageBucket, &asset.StoragePath, &asset.ContentType,
+		&asset.ByteSize, &asset.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("asset not found: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clip asset: %w", err)
+	}
+
+	return asset, nil
+}
+
+// GetProjectAssetByType returns the most recent asset of the given type for a project.
+func (db *DB) GetProjectAssetByType(ctx context.Context, projectID uuid.UUID, assetType models.AssetType) (*models.Asset, error) {
+	query := `
+		SELECT
+			id, project_id, clip_id, type, storage_bucket,
+			storage_path, content_type, byte_size, created_at
+		FROM assets
+		WHERE project_id = $1 AND type = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	asset := &models.Asset{}
+	err := db.QueryRowContext(ctx, query, projectID, assetType).Scan(
+		&asset.ID, &asset.ProjectID, &asset.ClipID, &asset.Type,
+		&asset.StorageBucket, &asset.StoragePath, &asset.ContentType,
+		&asset.ByteSize, &asset.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("asset not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project asset: %w", err)
+	}
+
+	return asset, nil
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 
 	"github.com/bobarin/episod/internal/models"
@@ -12,40 +13,42 @@ import (
 func (db *DB) CreateClip(ctx context.Context, clip *models.Clip) error {
 	query := `
 		INSERT INTO clips (
-			id, project_id, clip_index, script, voice_style_instruction,
-			image_prompt, video_prompt, estimated_duration_sec, status
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			id, project_id, clip_index, script, script_lines, voice_style_instruction,
+			image_prompt, video_prompt, estimated_duration_sec, status, hook_variant, citations
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING created_at, updated_at
 	`
 
 	return db.QueryRowContext(
 		ctx, query,
-		clip.ID, clip.ProjectID, clip.ClipIndex, clip.Script,
+		clip.ID, clip.ProjectID, clip.ClipIndex, clip.Script, clip.ScriptLines,
 		clip.VoiceStyleInstruction, clip.ImagePrompt, clip.VideoPrompt,
-		clip.EstimatedDurationSec, clip.Status,
+		clip.EstimatedDurationSec, clip.Status, clip.HookVariant, clip.Citations,
 	).Scan(&clip.CreatedAt, &clip.UpdatedAt)
 }
 
 func (db *DB) GetClip(ctx context.Context, id uuid.UUID) (*models.Clip, error) {
 	query := `
 		SELECT
-			id, project_id, clip_index, script, voice_style_instruction,
+			id, project_id, clip_index, script, script_lines, voice_style_instruction,
 			image_prompt, video_prompt, estimated_duration_sec, status,
 			audio_asset_id, image_asset_id, clip_video_asset_id,
 			audio_duration_ms, rendered_duration_ms, error_message,
-			created_at, updated_at
+			recovery, recovery_reason, image_prompt_rewrites, speech_pace,
+			hook_variant, variant_video_asset_id, citations, created_at, updated_at
 		FROM clips
 		WHERE id = $1
 	`
 
 	clip := &models.Clip{}
 	err := db.QueryRowContext(ctx, query, id).Scan(
-		&clip.ID, &clip.ProjectID, &clip.ClipIndex, &clip.Script,
+		&clip.ID, &clip.ProjectID, &clip.ClipIndex, &clip.Script, &clip.ScriptLines,
 		&clip.VoiceStyleInstruction, &clip.ImagePrompt, &clip.VideoPrompt,
 		&clip.EstimatedDurationSec, &clip.Status,
 		&clip.AudioAssetID, &clip.ImageAssetID, &clip.ClipVideoAssetID,
 		&clip.AudioDurationMs, &clip.RenderedDurationMs, &clip.ErrorMessage,
-		&clip.CreatedAt, &clip.UpdatedAt,
+		&clip.Recovery, &clip.RecoveryReason, &clip.ImagePromptRewrites, &clip.SpeechPace,
+		&clip.HookVariant, &clip.VariantVideoAssetID, &clip.Citations, &clip.CreatedAt, &clip.UpdatedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -58,18 +61,30 @@ func (db *DB) GetClip(ctx context.Context, id uuid.UUID) (*models.Clip, error) {
 	return clip, nil
 }
 
+// GetProjectClips returns the clips of a project's main video, ordered by index.
 func (db *DB) GetProjectClips(ctx context.Context, projectID uuid.UUID) ([]models.Clip, error) {
+	return db.queryProjectClips(ctx, projectID, `hook_variant IS NULL`, `clip_index`)
+}
+
+// GetHookVariants returns a project's alternative first clips, ordered by
+// variant number.
+func (db *DB) GetHookVariants(ctx context.Context, projectID uuid.UUID) ([]models.Clip, error) {
+	return db.queryProjectClips(ctx, projectID, `hook_variant IS NOT NULL`, `hook_variant`)
+}
+
+// queryProjectClips returns the project's clips matching filter, sorted by order.
+func (db *DB) queryProjectClips(ctx context.Context, projectID uuid.UUID, filter, order string) ([]models.Clip, error) {
 	query := `
 		SELECT
-			id, project_id, clip_index, script, voice_style_instruction,
+			id, project_id, clip_index, script, script_lines, voice_style_instruction,
 			image_prompt, video_prompt, estimated_duration_sec, status,
 			audio_asset_id, image_asset_id, clip_video_asset_id,
 			audio_duration_ms, rendered_duration_ms, error_message,
-			created_at, updated_at
+			recovery, recovery_reason, image_prompt_rewrites, speech_pace,
+			hook_variant, variant_video_asset_id, citations, created_at, updated_at
 		FROM clips
-		WHERE project_id = $1
-		ORDER BY clip_index
-	`
+		WHERE project_id = $1 AND ` + filter + `
+		ORDER BY ` + order
 
 	rows, err := db.QueryContext(ctx, query, projectID)
 	if err != nil {
@@ -81,12 +96,13 @@ func (db *DB) GetProjectClips(ctx context.Context, projectID uuid.UUID) ([]model
 	for rows.Next() {
 		var clip models.Clip
 		err := rows.Scan(
-			&clip.ID, &clip.ProjectID, &clip.ClipIndex, &clip.Script,
+			&clip.ID, &clip.ProjectID, &clip.ClipIndex, &clip.Script, &clip.ScriptLines,
 			&clip.VoiceStyleInstruction, &clip.ImagePrompt, &clip.VideoPrompt,
 			&clip.EstimatedDurationSec, &clip.Status,
 			&clip.AudioAssetID, &clip.ImageAssetID, &clip.ClipVideoAssetID,
 			&clip.AudioDurationMs, &clip.RenderedDurationMs, &clip.ErrorMessage,
-			&clip.CreatedAt, &clip.UpdatedAt,
+			&clip.Recovery, &clip.RecoveryReason, &clip.ImagePromptRewrites, &clip.SpeechPace,
+			&clip.HookVariant, &clip.VariantVideoAssetID, &clip.Citations, &clip.CreatedAt, &clip.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan clip: %w", err)
@@ -133,6 +149,22 @@ func (db *DB) UpdateClipVideo(ctx context.Context, id, assetID uuid.UUID) error
 	return err
 }
 
+// SetClipNarration sets a rendered clip's script, pace, audio and video in
+// one step: to a finished re-narration, or back to the previous one after
+// re-narrating failed (duration fitting).
+func (db *DB) SetClipNarration(ctx context.Context, clip *models.Clip) error {
+	query := `
+		UPDATE clips
+		SET script = $1, speech_pace = $2, audio_asset_id = $3, audio_duration_ms = $4,
+			clip_video_asset_id = $5, rendered_duration_ms = $6,
+			status = $7, error_message = NULL, updated_at = NOW()
+		WHERE id = $8
+	`
+	_, err := db.ExecContext(ctx, query, clip.Script, clip.SpeechPace, clip.AudioAssetID, clip.AudioDurationMs,
+		clip.ClipVideoAssetID, clip.RenderedDurationMs, models.ClipStatusRendered, clip.ID)
+	return err
+}
+
 func (db *DB) UpdateClipError(ctx context.Context, id uuid.UUID, errorMessage string) error {
 	query := `
 		UPDATE clips
@@ -143,10 +175,49 @@ func (db *DB) UpdateClipError(ctx context.Context, id uuid.UUID, errorMessage st
 	return err
 }
 
+// SetClipRecovery records how a failure of the clip was recovered.
+func (db *DB) SetClipRecovery(ctx context.Context, id uuid.UUID, recovery models.ClipRecovery, reason string) error {
+	query := `
+		UPDATE clips
+		SET recovery = $1, recovery_reason = $2, updated_at = NOW()
+		WHERE id = $3
+	`
+	_, err := db.ExecContext(ctx, query, recovery, reason, id)
+	return err
+}
+
+// AppendClipPromptRewrite adds a rewrite to the clip's image prompt history.
+func (db *DB) AppendClipPromptRewrite(ctx context.Context, id uuid.UUID, rewrite models.PromptRewrite) error {
+	rewriteJSON, err := json.Marshal(rewrite)
+	if err != nil {
+		return fmt.Errorf("failed to marshal prompt rewrite: %w", err)
+	}
+
+	query := `
+		UPDATE clips
+		SET image_prompt_rewrites = COALESCE(image_prompt_rewrites, '[]'::jsonb) || jsonb_build_array($1::jsonb),
+			updated_at = NOW()
+		WHERE id = $2
+	`
+	_, err = db.ExecContext(ctx, query, string(rewriteJSON), id)
+	return err
+}
+
+// SkipClip drops a failed clip from the video.
+func (db *DB) SkipClip(ctx context.Context, id uuid.UUID, reason string) error {
+	query := `
+		UPDATE clips
+		SET status = $1, recovery = $2, recovery_reason = $3, updated_at = NOW()
+		WHERE id = $4
+	`
+	_, err := db.ExecContext(ctx, query, models.ClipStatusSkipped, models.ClipRecoverySkipped, reason, id)
+	return err
+}
+
 // GetProjectThumbnailAssetID returns the image_asset_id of clip_index=0 for a project.
 // Returns nil if clip 0 doesn't exist or has no image yet.
 func (db *DB) GetProjectThumbnailAssetID(ctx context.Context, projectID uuid.UUID) (*uuid.UUID, error) {
-	query := `SELECT image_asset_id FROM clips WHERE project_id = $1 AND clip_index = 0 LIMIT 1`
+	query := `SELECT image_asset_id FROM clips WHERE project_id = $1 AND clip_index = 0 AND hook_variant IS NULL LIMIT 1`
 	var assetID *uuid.UUID
 	err := db.QueryRowContext(ctx, query, projectID).Scan(&assetID)
 	if err == sql.ErrNoRows {
@@ -158,27 +229,22 @@ func (db *DB) GetProjectThumbnailAssetID(ctx context.Context, projectID uuid.UUI
 	return assetID, nil
 }
 
-// GetProjectClipCount returns the number of clips for a project.
+// GetProjectClipCount returns the number of clips in a project's main video.
 func (db *DB) GetProjectClipCount(ctx context.Context, projectID uuid.UUID) (int, error) {
 	var count int
-	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM clips WHERE project_id = $1`, projectID).Scan(&count)
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM clips WHERE project_id = $1 AND hook_variant IS NULL`, projectID).Scan(&count)
 	return count, err
 }
 
+// SetClipVariantVideo records the full video rendered with a hook variant.
+func (db *DB) SetClipVariantVideo(ctx context.Context, id, assetID uuid.UUID) error {
+	query := `UPDATE clips SET variant_video_asset_id = $1, updated_at = NOW() WHERE id = $2`
+	_, err := db.ExecContext(ctx, query, assetID, id)
+	return err
+}
+
 func (db *DB) UpdateClipRenderedDuration(ctx context.Context, id uuid.UUID, durationMs int) error {
 	query := `UPDATE clips SET rendered_duration_ms = $1, updated_at = NOW() WHERE id = $2`
 	_, err := db.ExecContext(ctx, query, durationMs, id)
 	return err
 }
-
-func (db *DB) AreAllClipsRendered(ctx context.Context, projectID uuid.UUID) (bool, error) {
-	query := `
-		SELECT COUNT(*) = 0
-		FROM clips
-		WHERE project_id = $1 AND status != $2
-	`
-
-	var allRendered bool
-	err := db.QueryRowContext(ctx, query, projectID, models.ClipStatusRendered).Scan(&allRendered)
-	return allRendered, err
-}
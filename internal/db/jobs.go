@@ -13,14 +13,14 @@ import (
 func (db *DB) CreateJob(ctx context.Context, job *models.Job) error {
 	query := `
 		INSERT INTO jobs (
-			id, project_id, clip_id, type, status, attempts
-		) VALUES ($1, $2, $3, $4, $5, $6)
+			id, project_id, clip_id, type, status, attempts, plan_version
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING created_at
 	`
 
 	return db.QueryRowContext(
 		ctx, query,
-		job.ID, job.ProjectID, job.ClipID, job.Type, job.Status, job.Attempts,
+		job.ID, job.ProjectID, job.ClipID, job.Type, job.Status, job.Attempts, job.PlanVersion,
 	).Scan(&job.CreatedAt)
 }
 
@@ -28,7 +28,7 @@ func (db *DB) GetJob(ctx context.Context, id uuid.UUID) (*models.Job, error) {
 	query := `
 		SELECT
 			id, project_id, clip_id, type, status, attempts,
-			started_at, finished_at, error_message, logs_asset_id, created_at
+			started_at, finished_at, error_message, logs_asset_id, plan_version, created_at
 		FROM jobs
 		WHERE id = $1
 	`
@@ -37,7 +37,7 @@ func (db *DB) GetJob(ctx context.Context, id uuid.UUID) (*models.Job, error) {
 	err := db.QueryRowContext(ctx, query, id).Scan(
 		&job.ID, &job.ProjectID, &job.ClipID, &job.Type, &job.Status,
 		&job.Attempts, &job.StartedAt, &job.FinishedAt, &job.ErrorMessage,
-		&job.LogsAssetID, &job.CreatedAt,
+		&job.LogsAssetID, &job.PlanVersion, &job.CreatedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -54,7 +54,7 @@ func (db *DB) GetProjectJobs(ctx context.Context, projectID uuid.UUID) ([]models
 	query := `
 		SELECT
 			id, project_id, clip_id, type, status, attempts,
-			started_at, finished_at, error_message, logs_asset_id, created_at
+			started_at, finished_at, error_message, logs_asset_id, plan_version, created_at
 		FROM jobs
 		WHERE project_id = $1
 		ORDER BY created_at
@@ -72,7 +72,7 @@ func (db *DB) GetProjectJobs(ctx context.Context, projectID uuid.UUID) ([]models
 		err := rows.Scan(
 			&job.ID, &job.ProjectID, &job.ClipID, &job.Type, &job.Status,
 			&job.Attempts, &job.StartedAt, &job.FinishedAt, &job.ErrorMessage,
-			&job.LogsAssetID, &job.CreatedAt,
+			&job.LogsAssetID, &job.PlanVersion, &job.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan job: %w", err)
@@ -104,3 +104,64 @@ func (db *DB) UpdateJobError(ctx context.Context, id uuid.UUID, errorMessage str
 	_, err := db.ExecContext(ctx, query, models.JobStatusFailed, errorMessage, time.Now(), id)
 	return err
 }
+
+// UpdateJobLogs links a job to the asset holding its captured log.
+func (db *DB) UpdateJobLogs(ctx context.Context, id, logsAssetID uuid.UUID) error {
+	query := `UPDATE jobs SET logs_asset_id = $1 WHERE id = $2`
+	_, err := db.ExecContext(ctx, query, logsAssetID, id)
+	return err
+}
+
+// ClaimFinalRender moves a project from status `from` to rendering once all
+// its clips are rendered (or skipped), and returns the render_final job to enqueue.
+// Exactly one caller claims the transition: the status update is conditional
+// (concurrent callers block on the row and then no longer match), and the
+// job is unique per plan version — claiming again for the same version
+// (e.g. during recovery) requeues the existing job instead of adding one.
+// claimed is false when clips are still missing or the project has moved on.
+func (db *DB) ClaimFinalRender(ctx context.Context, projectID uuid.UUID, from models.ProjectStatus) (jobID uuid.UUID, claimed bool, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var planVersion int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE projects
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3
+		AND NOT EXISTS (SELECT 1 FROM clips WHERE project_id = $2 AND hook_variant IS NULL AND status NOT IN ($4, $5))
+		RETURNING plan_version
+	`, models.ProjectStatusRendering, projectID, from, models.ClipStatusRendered, models.ClipStatusSkipped).Scan(&planVersion)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, false, nil
+	}
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to claim final render: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO jobs (id, project_id, type, status, attempts, plan_version)
+		VALUES ($1, $2, 'render_final', $3, 0, $4)
+		ON CONFLICT (project_id, plan_version) WHERE type = 'render_final'
+		DO UPDATE SET status = EXCLUDED.status, error_message = NULL, started_at = NULL, finished_at = NULL
+		RETURNING id
+	`, uuid.New(), projectID, models.JobStatusQueued, planVersion).Scan(&jobID)
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to create final render job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to commit final render claim: %w", err)
+	}
+	return jobID, true, nil
+}
+
+// IncrementPlanVersion starts a new plan version for a project whose plan is
+// being regenerated.
+func (db *DB) IncrementPlanVersion(ctx context.Context, projectID uuid.UUID) error {
+	query := `UPDATE projects SET plan_version = plan_version + 1, updated_at = NOW() WHERE id = $1`
+	_, err := db.ExecContext(ctx, query, projectID)
+	return err
+}
@@ -15,8 +15,9 @@ func (db *DB) CreateProject(ctx context.Context, project *models.Project) error
 			id, user_id, series_id, topic, target_duration_seconds,
 			graphics_preset_id, status, plan_version,
 			tone, aspect_ratio, voice_id, cta,
-			music_mood, sample_image_url, language
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+			music_mood, sample_image_url, language, speaker_voices, tts_provider,
+			bypass_cache, clip_failure_policy, sources, citation_end_card
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
 		RETURNING created_at, updated_at
 	`
 
@@ -27,6 +28,8 @@ func (db *DB) CreateProject(ctx context.Context, project *models.Project) error
 		project.Status, project.PlanVersion,
 		project.Tone, project.AspectRatio, project.VoiceID,
 		project.CTA, project.MusicMood, project.SampleImageURL, project.Language,
+		project.SpeakerVoices, project.TTSProvider, project.BypassCache,
+		project.ClipFailurePolicy, project.Sources, project.CitationEndCard,
 	).Scan(&project.CreatedAt, &project.UpdatedAt)
 }
 
@@ -36,8 +39,10 @@ func (db *DB) GetProject(ctx context.Context, id uuid.UUID) (*models.Project, er
 			id, user_id, series_id, topic, target_duration_seconds,
 			graphics_preset_id, status, plan_version, final_video_asset_id,
 			tone, aspect_ratio, voice_id, cta,
-			music_mood, sample_image_url, language,
-			error_code, error_message, created_at, updated_at
+			music_mood, sample_image_url, language, speaker_voices, tts_provider,
+			bypass_cache, clip_failure_policy, plan_warnings, publishing, sources, citation_end_card,
+			error_code, error_message,
+			created_at, updated_at
 		FROM projects
 		WHERE id = $1
 	`
@@ -49,6 +54,9 @@ func (db *DB) GetProject(ctx context.Context, id uuid.UUID) (*models.Project, er
 		&project.Status, &project.PlanVersion, &project.FinalVideoAssetID,
 		&project.Tone, &project.AspectRatio, &project.VoiceID,
 		&project.CTA, &project.MusicMood, &project.SampleImageURL, &project.Language,
+		&project.SpeakerVoices, &project.TTSProvider, &project.BypassCache,
+		&project.ClipFailurePolicy, &project.PlanWarnings, &project.Publishing,
+		&project.Sources, &project.CitationEndCard,
 		&project.ErrorCode, &project.ErrorMessage,
 		&project.CreatedAt, &project.UpdatedAt,
 	)
@@ -76,8 +84,10 @@ func (db *DB) ListProjects(ctx context.Context, status string, limit, offset int
 			id, user_id, series_id, topic, target_duration_seconds,
 			graphics_preset_id, status, plan_version, final_video_asset_id,
 			tone, aspect_ratio, voice_id, cta,
-			music_mood, sample_image_url, language,
-			error_code, error_message, created_at, updated_at
+			music_mood, sample_image_url, language, speaker_voices, tts_provider,
+			bypass_cache, clip_failure_policy, plan_warnings, publishing, sources, citation_end_card,
+			error_code, error_message,
+			created_at, updated_at
 		FROM projects
 	`
 
@@ -103,6 +113,9 @@ func (db *DB) ListProjects(ctx context.Context, status string, limit, offset int
 			&p.Status, &p.PlanVersion, &p.FinalVideoAssetID,
 			&p.Tone, &p.AspectRatio, &p.VoiceID,
 			&p.CTA, &p.MusicMood, &p.SampleImageURL, &p.Language,
+			&p.SpeakerVoices, &p.TTSProvider, &p.BypassCache,
+			&p.ClipFailurePolicy, &p.PlanWarnings, &p.Publishing,
+			&p.Sources, &p.CitationEndCard,
 			&p.ErrorCode, &p.ErrorMessage,
 			&p.CreatedAt, &p.UpdatedAt,
 		); err != nil {
@@ -141,6 +154,22 @@ func (db *DB) UpdateProjectError(ctx context.Context, id uuid.UUID, errorCode, e
 	return err
 }
 
+// SetProjectPlanWarnings records the plan validation issues left after
+// repair (nil clears them).
+func (db *DB) SetProjectPlanWarnings(ctx context.Context, id uuid.UUID, warnings models.StringList) error {
+	query := `UPDATE projects SET plan_warnings = $1, updated_at = NOW() WHERE id = $2`
+	_, err := db.ExecContext(ctx, query, warnings, id)
+	return err
+}
+
+// SetProjectPublishing stores the per-platform titles, descriptions and
+// hashtags from the plan.
+func (db *DB) SetProjectPublishing(ctx context.Context, id uuid.UUID, publishing models.Publishing) error {
+	query := `UPDATE projects SET publishing = $1, updated_at = NOW() WHERE id = $2`
+	_, err := db.ExecContext(ctx, query, publishing, id)
+	return err
+}
+
 func (db *DB) SetProjectFinalVideo(ctx context.Context, projectID, assetID uuid.UUID) error {
 	query := `
 		UPDATE projects
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bobarin/episod/internal/models"
+	"github.com/google/uuid"
+)
+
+// CreatePronunciation inserts a new lexicon entry.
+func (db *DB) CreatePronunciation(ctx context.Context, p *models.Pronunciation) error {
+	query := `
+		INSERT INTO pronunciations (id, user_id, series_id, term, alias, ipa)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at
+	`
+
+	return db.QueryRowContext(
+		ctx, query,
+		p.ID, p.UserID, p.SeriesID, p.Term, p.Alias, p.IPA,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+}
+
+// ListPronunciations returns the lexicon entries that apply to a user and/or
+// series: global entries plus the user's and the series' own. When a term is
+// defined at several levels only the most specific entry is returned
+// (series beats user beats global). Either ID may be nil.
+func (db *DB) ListPronunciations(ctx context.Context, userID, seriesID *uuid.UUID) ([]models.Pronunciation, error) {
+	query := `
+		SELECT DISTINCT ON (term)
+			id, user_id, series_id, term, alias, ipa, created_at, updated_at
+		FROM pronunciations
+		WHERE (user_id IS NULL AND series_id IS NULL)
+		   OR ($1::uuid IS NOT NULL AND user_id = $1 AND series_id IS NULL)
+		   OR ($2::uuid IS NOT NULL AND series_id = $2)
+		ORDER BY term,
+			CASE WHEN series_id IS NOT NULL THEN 0 WHEN user_id IS NOT NULL THEN 1 ELSE 2 END,
+			updated_at DESC
+	`
+
+	rows, err := db.QueryContext(ctx, query, userID, seriesID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pronunciations: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.Pronunciation
+	for rows.Next() {
+		var p models.Pronunciation
+		if err := rows.Scan(
+			&p.ID, &p.UserID, &p.SeriesID, &p.Term, &p.Alias, &p.IPA,
+			&p.CreatedAt, &p.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan pronunciation: %w", err)
+		}
+		entries = append(entries, p)
+	}
+
+	return entries, rows.Err()
+}
+
+// DeletePronunciation removes a lexicon entry. Returns an error if it doesn't exist.
+func (db *DB) DeletePronunciation(ctx context.Context, id uuid.UUID) error {
+	result, err := db.ExecContext(ctx, `DELETE FROM pronunciations WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete pronunciation: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete pronunciation: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("pronunciation not found")
+	}
+	return nil
+}
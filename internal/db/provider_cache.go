@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/bobarin/episod/internal/models"
+)
+
+// UseProviderCacheEntry returns the unexpired cache entry for key and records
+// the hit. Returns nil (and no error) on a miss.
+func (db *DB) UseProviderCacheEntry(ctx context.Context, key string) (*models.ProviderCacheEntry, error) {
+	query := `
+		UPDATE provider_cache
+		SET hit_count = hit_count + 1, last_used_at = NOW()
+		WHERE cache_key = $1 AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING cache_key, provider, asset_id, metadata, hit_count,
+			created_at, last_used_at, expires_at
+	`
+
+	entry := &models.ProviderCacheEntry{}
+	err := db.QueryRowContext(ctx, query, key).Scan(
+		&entry.Key, &entry.Provider, &entry.AssetID, &entry.Metadata, &entry.HitCount,
+		&entry.CreatedAt, &entry.LastUsedAt, &entry.ExpiresAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provider cache: %w", err)
+	}
+
+	return entry, nil
+}
+
+// PutProviderCacheEntry records (or replaces) the asset cached under entry.Key.
+// ttl <= 0 means the entry never expires.
+func (db *DB) PutProviderCacheEntry(ctx context.Context, entry *models.ProviderCacheEntry, ttl time.Duration) error {
+	var expiresAt *time.Time
+	if ttl > 0 {
+		t := time.Now().Add(ttl)
+		expiresAt = &t
+	}
+
+	query := `
+		INSERT INTO provider_cache (cache_key, provider, asset_id, metadata, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (cache_key) DO UPDATE SET
+			provider = EXCLUDED.provider,
+			asset_id = EXCLUDED.asset_id,
+			metadata = EXCLUDED.metadata,
+			hit_count = 0,
+			created_at = NOW(),
+			last_used_at = NOW(),
+			expires_at = EXCLUDED.expires_at
+	`
+
+	_, err := db.ExecContext(ctx, query, entry.Key, entry.Provider, entry.AssetID, entry.Metadata, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to write provider cache: %w", err)
+	}
+	return nil
+}
+
+// DeleteProviderCacheEntry forgets a cache entry (e.g. when its asset can no
+// longer be downloaded).
+func (db *DB) DeleteProviderCacheEntry(ctx context.Context, key string) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM provider_cache WHERE cache_key = $1`, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete provider cache entry: %w", err)
+	}
+	return nil
+}
+
+// EvictProviderCache deletes expired entries and, when maxEntries > 0, the
+// least recently used entries beyond that limit. Returns the number removed.
+func (db *DB) EvictProviderCache(ctx context.Context, maxEntries int) (int64, error) {
+	result, err := db.ExecContext(ctx, `DELETE FROM provider_cache WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to evict expired cache entries: %w", err)
+	}
+	removed, _ := result.RowsAffected()
+
+	if maxEntries > 0 {
+		query := `
+			DELETE FROM provider_cache
+			WHERE cache_key IN (
+				SELECT cache_key FROM provider_cache
+				ORDER BY last_used_at DESC
+				OFFSET $1
+			)
+		`
+		result, err := db.ExecContext(ctx, query, maxEntries)
+		if err != nil {
+			return removed, fmt.Errorf("failed to evict least recently used cache entries: %w", err)
+		}
+		n, _ := result.RowsAffected()
+		removed += n
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/bobarin/episod/internal/models"
+	"github.com/google/uuid"
+)
+
+// ListStalledProjects returns the projects in status that have shown no
+// activity for at least idle. Activity is the latest change to the project,
+// any of its clips, or any of its jobs (created, started or finished).
+func (db *DB) ListStalledProjects(ctx context.Context, status models.ProjectStatus, idle time.Duration) ([]uuid.UUID, error) {
+	query := `
+		SELECT p.id
+		FROM projects p
+		WHERE p.status = $1
+		AND GREATEST(
+			p.updated_at,
+			COALESCE((SELECT MAX(c.updated_at) FROM clips c WHERE c.project_id = p.id), p.updated_at),
+			COALESCE((
+				SELECT MAX(GREATEST(j.created_at, COALESCE(j.started_at, j.created_at), COALESCE(j.finished_at, j.created_at)))
+				FROM jobs j WHERE j.project_id = p.id
+			), p.updated_at)
+		) < NOW() - make_interval(secs => $2)
+		ORDER BY p.updated_at
+	`
+
+	rows, err := db.QueryContext(ctx, query, status, idle.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stalled projects: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan stalled project: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// RecordProjectRecovery counts a recovery of a project that is still in
+// status and returns the new count. ok is false when the project has moved
+// on in the meantime (nothing to recover).
+func (db *DB) RecordProjectRecovery(ctx context.Context, id uuid.UUID, status models.ProjectStatus) (attempts int, ok bool, err error) {
+	query := `
+		UPDATE projects
+		SET recovery_attempts = recovery_attempts + 1, updated_at = NOW()
+		WHERE id = $1 AND status = $2
+		RETURNING recovery_attempts
+	`
+	err = db.QueryRowContext(ctx, query, id, status).Scan(&attempts)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to record project recovery: %w", err)
+	}
+	return attempts, true, nil
+}
+
+// FailUnfinishedJobs marks a project's queued and running jobs as failed
+// with message — used when they were lost and are being replaced.
+func (db *DB) FailUnfinishedJobs(ctx context.Context, projectID uuid.UUID, message string) (int64, error) {
+	query := `
+		UPDATE jobs
+		SET status = $1, error_message = $2, finished_at = NOW()
+		WHERE project_id = $3 AND status IN ($4, $5)
+	`
+	result, err := db.ExecContext(ctx, query, models.JobStatusFailed, message, projectID, models.JobStatusQueued, models.JobStatusRunning)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail unfinished jobs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// DeleteProjectClips removes all clips of a project (their assets and jobs
+// cascade), so an interrupted plan can be generated again from scratch.
+func (db *DB) DeleteProjectClips(ctx context.Context, projectID uuid.UUID) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM clips WHERE project_id = $1`, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to delete clips: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/bobarin/episod/internal/models"
+	"github.com/google/uuid"
+)
+
+// GetSeries retrieves a series by ID.
+func (db *DB) GetSeries(ctx context.Context, id uuid.UUID) (*models.Series, error) {
+	query := `
+		SELECT
+			id, user_id, name, description, guidance, sample_script,
+			default_graphics_preset_id, default_voice_profile,
+			created_at, updated_at
+		FROM series
+		WHERE id = $1
+	`
+
+	series := &models.Series{}
+	err := db.QueryRowContext(ctx, query, id).Scan(
+		&series.ID, &series.UserID, &series.Name, &series.Description,
+		&series.Guidance, &series.SampleScript,
+		&series.DefaultGraphicsPresetID, &series.DefaultVoiceProfile,
+		&series.CreatedAt, &series.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("series not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get series: %w", err)
+	}
+
+	return series, nil
+}
@@ -0,0 +1,75 @@
+package joblog
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ---------------------------------------------------------------------------
+// Correlation fields — request, project, clip, job and attempt IDs
+//
+// With attaches fields to a context; Handler adds them to every record
+// logged with that context, so one video can be followed from the API
+// request that created it through every worker job it spawned.
+// ---------------------------------------------------------------------------
+
+type fieldsKey struct{}
+type requestIDKey struct{}
+
+// With returns a context whose log records carry the given key/value pairs
+// (slog-style alternating keys and values) in addition to ctx's own.
+func With(ctx context.Context, args ...any) context.Context {
+	parent, _ := ctx.Value(fieldsKey{}).([]any)
+	fields := make([]any, 0, len(parent)+len(args))
+	fields = append(append(fields, parent...), args...)
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+// WithRequestID returns a context carrying the API request ID that started
+// the work. Queued jobs inherit it (see queue.Enqueue) and it is logged as
+// request_id.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	return With(ctx, "request_id", requestID)
+}
+
+// RequestID returns the request ID carried by ctx, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Handler is a slog.Handler that adds the context's correlation fields (and
+// the current trace ID, if any) to each record before passing it on.
+type Handler struct {
+	slog.Handler
+}
+
+// NewHandler wraps inner with correlation fields.
+func NewHandler(inner slog.Handler) *Handler {
+	return &Handler{Handler: inner}
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if fields, ok := ctx.Value(fieldsKey{}).([]any); ok {
+		r.Add(fields...)
+	}
+	// Link log lines to their trace when tracing is on
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.Add("trace_id", sc.TraceID().String())
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,202 @@
+// Package joblog captures the log output of a single worker job — worker
+// messages, provider request IDs and timings, FFmpeg stderr — so it can be
+// stored with the job and inspected after the process has moved on.
+//
+// A Log travels on the job's context. Printf, Warnf, Errorf and Event always
+// write to the process log (slog, tagged with the context's correlation
+// fields — see With), and additionally append to the job's Log when the
+// context carries one, so services can log without knowing about jobs.
+package joblog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLogBytes bounds the message bytes a Log keeps. When exceeded the oldest
+// entries are dropped — the end of a log (where failures are) matters most.
+const maxLogBytes = 1 << 20
+
+// Entry is one structured log line.
+type Entry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`  // "INFO", "WARN", "ERROR"
+	Source  string                 `json:"source"` // "worker" or "ffmpeg"
+	Message string                 `json:"msg"`
+	Fields  map[string]interface{} `json:"fields,omitempty"` // e.g. request_id, duration_ms
+}
+
+// Log collects the entries of one job. Safe for concurrent use.
+type Log struct {
+	mu      sync.Mutex
+	entries []Entry
+	size    int
+	dropped int
+}
+
+// New creates an empty job log.
+func New() *Log {
+	return &Log{}
+}
+
+type contextKey struct{}
+
+// WithLog returns a context that carries l.
+func WithLog(ctx context.Context, l *Log) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the job log carried by ctx, or nil.
+func FromContext(ctx context.Context) *Log {
+	l, _ := ctx.Value(contextKey{}).(*Log)
+	return l
+}
+
+// Add appends an info entry.
+func (l *Log) Add(source, message string, fields map[string]interface{}) {
+	l.add(slog.LevelInfo, source, message, fields)
+}
+
+func (l *Log) add(level slog.Level, source, message string, fields map[string]interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, Entry{Time: time.Now(), Level: level.String(), Source: source, Message: message, Fields: fields})
+	l.size += len(message)
+	for l.size > maxLogBytes && len(l.entries) > 1 {
+		l.size -= len(l.entries[0].Message)
+		l.entries = l.entries[1:]
+		l.dropped++
+	}
+}
+
+// Len returns the number of entries kept.
+func (l *Log) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.entries)
+}
+
+// NDJSON encodes the log as newline-delimited JSON, one entry per line.
+func (l *Log) NDJSON() ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	if l.dropped > 0 {
+		first := l.entries[0].Time
+		if err := enc.Encode(Entry{Time: first, Level: slog.LevelWarn.String(), Source: "joblog", Message: fmt.Sprintf("%d earlier entries dropped (log size limit)", l.dropped)}); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range l.entries {
+		if err := enc.Encode(e); err != nil {
+			return nil, fmt.Errorf("failed to encode log entry: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// Printf logs an info message to the process log and, inside a job, the job log.
+func Printf(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, slog.LevelInfo, format, args...)
+}
+
+// Warnf logs a warning — something failed but the job carries on without it.
+func Warnf(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, slog.LevelWarn, format, args...)
+}
+
+// Errorf logs an error — the job (or request) failed.
+func Errorf(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, slog.LevelError, format, args...)
+}
+
+// Event logs an info message with structured fields (provider request IDs,
+// timings, ...), kept as attributes in both the process and the job log.
+func Event(ctx context.Context, msg string, fields map[string]interface{}) {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attrs := make([]any, 0, 2*len(fields))
+	for _, k := range keys {
+		attrs = append(attrs, k, fields[k])
+	}
+	slog.Log(ctx, slog.LevelInfo, msg, attrs...)
+	if l := FromContext(ctx); l != nil {
+		l.add(slog.LevelInfo, "worker", msg, fields)
+	}
+}
+
+func logf(ctx context.Context, level slog.Level, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	slog.Log(ctx, level, msg)
+	if l := FromContext(ctx); l != nil {
+		l.add(level, "worker", msg, nil)
+	}
+}
+
+// Stderr returns the writer for a subprocess's stderr: the process stderr
+// and, inside a job, the job log (one entry per output line, tagged source).
+// Call Flush on it once the subprocess has exited.
+func Stderr(ctx context.Context, source string) io.Writer {
+	l := FromContext(ctx)
+	if l == nil {
+		return os.Stderr
+	}
+	return &lineWriter{log: l, source: source}
+}
+
+// Flush logs the last line held by a writer from Stderr when the output
+// didn't end with a newline. Other writers are left alone.
+func Flush(w io.Writer) {
+	if lw, ok := w.(*lineWriter); ok {
+		lw.flush()
+	}
+}
+
+// lineWriter copies output to the process stderr, splits it into lines (on
+// \n and on the \r FFmpeg uses for progress updates) and appends each
+// non-empty line to the log. A trailing partial line is kept until the next
+// write completes it, or flush.
+type lineWriter struct {
+	log     *Log
+	source  string
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	os.Stderr.Write(p)
+	data := append(w.partial, p...)
+	for {
+		i := bytes.IndexAny(data, "\r\n")
+		if i < 0 {
+			break
+		}
+		if line := strings.TrimSpace(string(data[:i])); line != "" {
+			w.log.Add(w.source, line, nil)
+		}
+		data = data[i+1:]
+	}
+	w.partial = append(w.partial[:0], data...)
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	if line := strings.TrimSpace(string(w.partial)); line != "" {
+		w.log.Add(w.source, line, nil)
+	}
+	w.partial = w.partial[:0]
+}
@@ -0,0 +1,60 @@
+package joblog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestStderrSplitsLines(t *testing.T) {
+	l := New()
+	w := Stderr(WithLog(context.Background(), l), "ffmpeg")
+
+	fmt.Fprint(w, "Input #0, mp3\nframe=  10\rframe=  2")
+	fmt.Fprint(w, "0\r\nError while decoding\nConversion failed!")
+	Flush(w)
+
+	data, err := l.NDJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 5 || !strings.Contains(lines[4], `"msg":"Conversion failed!"`) || !strings.Contains(lines[2], `"msg":"frame=  20"`) || !strings.Contains(lines[3], `"source":"ffmpeg"`) {
+		t.Errorf("unexpected log:\n%s", data)
+	}
+}
+
+func TestLogKeepsNewestEntries(t *testing.T) {
+	l := New()
+	chunk := strings.Repeat("x", maxLogBytes/4)
+	for i := 0; i < 6; i++ {
+		l.Add("worker", chunk, nil)
+	}
+	l.Add("worker", "final error", nil)
+
+	data, _ := l.NDJSON()
+	if !bytes.Contains(data, []byte("earlier entries dropped")) || !bytes.HasSuffix(bytes.TrimSpace(data), []byte(`"msg":"final error"}`)) {
+		t.Errorf("oldest entries should be dropped, newest kept")
+	}
+}
+
+func TestHandlerAddsContextFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewJSONHandler(&buf, nil)))
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = With(ctx, "project_id", "p1", "attempt", 2)
+	logger.InfoContext(ctx, "hello")
+
+	if RequestID(ctx) != "req-1" {
+		t.Errorf("request ID not carried: %q", RequestID(ctx))
+	}
+	for _, want := range []string{`"request_id":"req-1"`, `"project_id":"p1"`, `"attempt":2`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %s in %s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package metrics defines the Prometheus metrics exported on /metrics: HTTP
+// traffic, queue depth, job outcomes, provider calls, worker semaphore
+// waits, FFmpeg runs, AI video fallbacks and image refusals.
+//
+// Collectors are registered on the default registry at init; callers use the
+// Observe*/Inc* helpers so label sets stay consistent across packages.
+package metrics
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "episod"
+
+// Buckets for calls that take seconds to minutes (provider calls, jobs, renders).
+var slowBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300, 600}
+
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "API request latency by route pattern, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	jobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_duration_seconds",
+		Help:      "Worker job duration by job type and outcome (succeeded/failed).",
+		Buckets:   slowBuckets,
+	}, []string{"type", "outcome"})
+
+	providerCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provider_call_duration_seconds",
+		Help:      "External provider call latency by provider and operation.",
+		Buckets:   slowBuckets,
+	}, []string{"provider", "operation"})
+
+	providerCallErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_call_errors_total",
+		Help:      "Failed external provider calls by provider and operation.",
+	}, []string{"provider", "operation"})
+
+	semaphoreWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "worker_semaphore_wait_seconds",
+		Help:      "Time spent waiting for a worker concurrency slot, by semaphore.",
+		Buckets:   slowBuckets,
+	}, []string{"semaphore"})
+
+	ffmpegDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ffmpeg_duration_seconds",
+		Help:      "FFmpeg invocation duration by operation and outcome.",
+		Buckets:   slowBuckets,
+	}, []string{"operation", "outcome"})
+
+	aiVideoFallbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ai_video_fallbacks_total",
+		Help:      "Clips rendered with Ken Burns effects because AI video generation failed, by provider.",
+	}, []string{"provider"})
+
+	imageRefusals = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "image_refusals_total",
+		Help:      "Image generations the model refused, by block or finish reason.",
+	}, []string{"reason"})
+)
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// outcome maps an error to the outcome label.
+func outcome(err error) string {
+	if err != nil {
+		return "failed"
+	}
+	return "succeeded"
+}
+
+// ObserveHTTPRequest records one API request. route is the matched route
+// pattern (e.g. /v1/projects/{id}), never the raw path, to bound cardinality.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// ObserveJob records a finished worker job.
+func ObserveJob(jobType string, duration time.Duration, err error) {
+	jobDuration.WithLabelValues(jobType, outcome(err)).Observe(duration.Seconds())
+}
+
+// ObserveProviderCall records an external provider call started at start.
+// Designed for defer with a named error result:
+//
+//	defer metrics.ObserveProviderCall("gemini", "generate_image", time.Now(), &err)
+func ObserveProviderCall(provider, operation string, start time.Time, err *error) {
+	providerCallDuration.WithLabelValues(provider, operation).Observe(time.Since(start).Seconds())
+	if err != nil && *err != nil {
+		providerCallErrors.WithLabelValues(provider, operation).Inc()
+	}
+}
+
+// ObserveSemaphoreWait records how long a worker waited for a slot.
+func ObserveSemaphoreWait(semaphore string, wait time.Duration) {
+	semaphoreWait.WithLabelValues(semaphore).Observe(wait.Seconds())
+}
+
+// ObserveFFmpeg records one FFmpeg invocation.
+func ObserveFFmpeg(operation string, duration time.Duration, err error) {
+	ffmpegDuration.WithLabelValues(operation, outcome(err)).Observe(duration.Seconds())
+}
+
+// IncAIVideoFallback counts a clip that fell back to Ken Burns effects.
+func IncAIVideoFallback(provider string) {
+	aiVideoFallbacks.WithLabelValues(provider).Inc()
+}
+
+// IncImageRefusal counts an image the model refused to generate.
+func IncImageRefusal(reason string) {
+	imageRefusals.WithLabelValues(reason).Inc()
+}
+
+// QueueLengthFunc returns the number of jobs waiting in a queue.
+type QueueLengthFunc func(ctx context.Context, queueName string) (int64, error)
+
+// RegisterQueueDepth exports episod_queue_depth{queue} for the given queues,
+// read with length at scrape time.
+func RegisterQueueDepth(length QueueLengthFunc, queues ...string) {
+	prometheus.MustRegister(&queueDepthCollector{length: length, queues: queues})
+}
+
+var queueDepthDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "queue_depth"),
+	"Jobs waiting in each Redis queue.",
+	[]string{"queue"}, nil,
+)
+
+type queueDepthCollector struct {
+	length QueueLengthFunc
+	queues []string
+}
+
+func (c *queueDepthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueDepthDesc
+}
+
+func (c *queueDepthCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	for _, name := range c.queues {
+		n, err := c.length(ctx, name)
+		if err != nil {
+			log.Printf("[Metrics] Could not read length of %s: %v", name, err)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(n), name)
+	}
+}
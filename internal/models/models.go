@@ -28,6 +28,36 @@ const (
 	ClipStatusImaged   ClipStatus = "imaged"
 	ClipStatusRendered ClipStatus = "rendered"
 	ClipStatusFailed   ClipStatus = "failed"
+	ClipStatusSkipped  ClipStatus = "skipped" // Dropped by the skip failure policy
+)
+
+// ClipFailurePolicy is what happens to a clip that fails (projects.clip_failure_policy).
+type ClipFailurePolicy string
+
+const (
+	ClipFailurePolicyFail           ClipFailurePolicy = "fail"            // Clip stays failed (default)
+	ClipFailurePolicyRewritePrompt  ClipFailurePolicy = "rewrite_prompt"  // Image failures retry with a rewritten prompt
+	ClipFailurePolicyFallbackVisual ClipFailurePolicy = "fallback_visual" // Image failures use a neighbour's image or a text card
+	ClipFailurePolicySkip           ClipFailurePolicy = "skip"            // Clip is dropped from the video
+)
+
+// IsValid reports whether p is a known policy.
+func (p ClipFailurePolicy) IsValid() bool {
+	switch p {
+	case ClipFailurePolicyFail, ClipFailurePolicyRewritePrompt, ClipFailurePolicyFallbackVisual, ClipFailurePolicySkip:
+		return true
+	}
+	return false
+}
+
+// ClipRecovery records how a failed clip was recovered (clips.recovery).
+type ClipRecovery string
+
+const (
+	ClipRecoveryRewrittenPrompt ClipRecovery = "rewritten_prompt"
+	ClipRecoveryNeighbourImage  ClipRecovery = "neighbour_image"
+	ClipRecoveryTextCard        ClipRecovery = "text_card"
+	ClipRecoverySkipped         ClipRecovery = "skipped"
 )
 
 type AssetType string
@@ -39,6 +69,17 @@ const (
 	AssetTypeClipVideo  AssetType = "clip_video"
 	AssetTypeFinalVideo AssetType = "final_video"
 	AssetTypeLogs       AssetType = "logs"
+	AssetTypeAIVideo    AssetType = "ai_video" // xAI clip video, kept for the provider cache
+
+	// Subtitle artifacts — per-clip word timings and project-level sidecar captions
+	AssetTypeWordTimestamps AssetType = "word_timestamps"
+	AssetTypeSubtitlesSRT   AssetType = "subtitles_srt"
+	AssetTypeSubtitlesVTT   AssetType = "subtitles_vtt"
+	AssetTypeSubtitlesASS   AssetType = "subtitles_ass"
+
+	// Project covers (thumbnails) with the title overlaid, per platform size
+	AssetTypeCoverLandscape AssetType = "cover_landscape" // 1280x720
+	AssetTypeCoverPortrait  AssetType = "cover_portrait"  // 1080x1920
 )
 
 type JobStatus string
@@ -69,6 +110,189 @@ func (j *JSONB) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, j)
 }
 
+// ScriptLine is one speaker-tagged line of a clip's narration.
+// Dialogue-style clips are voiced line by line, each with its speaker's voice.
+type ScriptLine struct {
+	Speaker               string `json:"speaker"`                           // Role, e.g. "narrator", "character_a"
+	Text                  string `json:"text"`                              // Spoken text for this line
+	VoiceStyleInstruction string `json:"voice_style_instruction,omitempty"` // Optional per-line delivery override
+}
+
+// ScriptLines is a custom type for the clips.script_lines JSONB array
+type ScriptLines []ScriptLine
+
+func (l ScriptLines) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+func (l *ScriptLines) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// PromptRewrite is one rewrite of a clip's image prompt after the image
+// model refused it, kept on the clip for auditing.
+type PromptRewrite struct {
+	Prompt    string    `json:"prompt"`    // The rewritten prompt
+	Reason    string    `json:"reason"`    // The refusal that led to it
+	Succeeded bool      `json:"succeeded"` // Whether the image model accepted it
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PromptRewrites is a custom type for the clips.image_prompt_rewrites JSONB array
+type PromptRewrites []PromptRewrite
+
+func (r PromptRewrites) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	return json.Marshal(r)
+}
+
+func (r *PromptRewrites) Scan(value interface{}) error {
+	if value == nil {
+		*r = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+// Publishing platforms planning writes titles, descriptions and hashtags for.
+const (
+	PlatformTikTok         = "tiktok"
+	PlatformYouTubeShorts  = "youtube_shorts"
+	PlatformInstagramReels = "instagram_reels"
+)
+
+// PublishingPlatforms lists the platforms in Publishing, in display order.
+var PublishingPlatforms = []string{PlatformTikTok, PlatformYouTubeShorts, PlatformInstagramReels}
+
+// PublishingMetadata is what a video is posted with on one platform.
+type PublishingMetadata struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Hashtags    []string `json:"hashtags"` // With the leading "#"
+}
+
+// Publishing is a custom type for the projects.publishing JSONB column:
+// platform → metadata.
+type Publishing map[string]PublishingMetadata
+
+func (p Publishing) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
+
+func (p *Publishing) Scan(value interface{}) error {
+	if value == nil {
+		*p = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, p)
+}
+
+// ResearchSource is a document a project's plan is grounded in: a URL to
+// fetch, or text supplied with the request.
+type ResearchSource struct {
+	Title string `json:"title,omitempty"` // Defaults to the fetched page's title
+	URL   string `json:"url,omitempty"`
+	Text  string `json:"text,omitempty"`
+}
+
+// ResearchSources is a custom type for the projects.sources JSONB column.
+type ResearchSources []ResearchSource
+
+func (s ResearchSources) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+func (s *ResearchSources) Scan(value interface{}) error {
+	if value == nil {
+		*s = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+// Citation is a source snippet backing a clip's claims.
+type Citation struct {
+	Source  int    `json:"source"` // 1-based position in the project's sources
+	Title   string `json:"title"`
+	URL     string `json:"url,omitempty"`
+	Snippet string `json:"snippet"`
+}
+
+// Citations is a custom type for the clips.citations JSONB column.
+type Citations []Citation
+
+func (c Citations) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+func (c *Citations) Scan(value interface{}) error {
+	if value == nil {
+		*c = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, c)
+}
+
+// StringList is a custom type for JSONB arrays of strings (projects.plan_warnings)
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
+
 // Models
 
 type User struct {
@@ -114,6 +338,19 @@ type TonePreset struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// Pronunciation is a lexicon entry telling TTS how to say a term.
+// Scope: series_id set → that series; user_id set → that user's projects; neither → global.
+type Pronunciation struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	SeriesID  *uuid.UUID `json:"series_id,omitempty"`
+	Term      string     `json:"term"`            // Matched case-sensitively as a whole word
+	Alias     *string    `json:"alias,omitempty"` // Phonetic respelling, e.g. "Kee-AH-no"
+	IPA       *string    `json:"ipa,omitempty"`   // IPA transcription, e.g. "kiˈɑːnoʊ"
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
 type Project struct {
 	ID                     uuid.UUID      `json:"id"`
 	UserID                 *uuid.UUID     `json:"user_id,omitempty"`
@@ -132,6 +369,14 @@ type Project struct {
 	MusicMood              *string        `json:"music_mood,omitempty"`       // "calm", "epic", "upbeat", etc.
 	SampleImageURL         *string        `json:"sample_image_url,omitempty"` // Custom style reference image URL
 	Language               *string        `json:"language,omitempty"`         // ISO 639-1: "en", "es", "fr", etc.
+	SpeakerVoices          JSONB          `json:"speaker_voices,omitempty"`   // Speaker role → voice ID for dialogue narration
+	TTSProvider            *string        `json:"tts_provider,omitempty"`     // "elevenlabs" or "cartesia" (nil = fallback chain)
+	BypassCache            bool           `json:"bypass_cache"`               // Always call providers instead of reusing cached outputs
+	ClipFailurePolicy      *string        `json:"clip_failure_policy,omitempty"` // What happens to a failed clip (nil = "fail")
+	PlanWarnings           StringList     `json:"plan_warnings,omitempty"`       // Plan validation issues left after repair
+	Publishing             Publishing     `json:"publishing,omitempty"`          // Per-platform title, description and hashtags
+	Sources                ResearchSources `json:"sources,omitempty"`            // Research mode: documents the plan is grounded in
+	CitationEndCard        bool           `json:"citation_end_card"`             // Append an end card listing the cited sources
 	ErrorCode              *string        `json:"error_code,omitempty"`
 	ErrorMessage           *string        `json:"error_message,omitempty"`
 	CreatedAt              time.Time      `json:"created_at"`
@@ -139,23 +384,31 @@ type Project struct {
 }
 
 type Clip struct {
-	ID                    uuid.UUID   `json:"id"`
-	ProjectID             uuid.UUID   `json:"project_id"`
-	ClipIndex             int         `json:"clip_index"`
-	Script                string      `json:"script"`
-	VoiceStyleInstruction *string     `json:"voice_style_instruction,omitempty"`
-	ImagePrompt           string      `json:"image_prompt"`
-	VideoPrompt           *string     `json:"video_prompt,omitempty"`
-	EstimatedDurationSec  *int        `json:"estimated_duration_sec,omitempty"` // From AI plan
-	Status                ClipStatus  `json:"status"`
-	AudioAssetID          *uuid.UUID  `json:"audio_asset_id,omitempty"`
-	ImageAssetID          *uuid.UUID  `json:"image_asset_id,omitempty"`
-	ClipVideoAssetID      *uuid.UUID  `json:"clip_video_asset_id,omitempty"`
-	AudioDurationMs       *int        `json:"audio_duration_ms,omitempty"`
-	RenderedDurationMs    *int        `json:"rendered_duration_ms,omitempty"` // Actual rendered clip duration
-	ErrorMessage          *string     `json:"error_message,omitempty"`
-	CreatedAt             time.Time   `json:"created_at"`
-	UpdatedAt             time.Time   `json:"updated_at"`
+	ID                    uuid.UUID      `json:"id"`
+	ProjectID             uuid.UUID      `json:"project_id"`
+	ClipIndex             int            `json:"clip_index"`
+	Script                string         `json:"script"`
+	ScriptLines           ScriptLines    `json:"script_lines,omitempty"` // Speaker-tagged lines (nil = single narrator)
+	VoiceStyleInstruction *string        `json:"voice_style_instruction,omitempty"`
+	ImagePrompt           string         `json:"image_prompt"`
+	VideoPrompt           *string        `json:"video_prompt,omitempty"`
+	EstimatedDurationSec  *int           `json:"estimated_duration_sec,omitempty"` // From AI plan
+	Status                ClipStatus     `json:"status"`
+	AudioAssetID          *uuid.UUID     `json:"audio_asset_id,omitempty"`
+	ImageAssetID          *uuid.UUID     `json:"image_asset_id,omitempty"`
+	ClipVideoAssetID      *uuid.UUID     `json:"clip_video_asset_id,omitempty"`
+	AudioDurationMs       *int           `json:"audio_duration_ms,omitempty"`
+	RenderedDurationMs    *int           `json:"rendered_duration_ms,omitempty"` // Actual rendered clip duration
+	ErrorMessage          *string        `json:"error_message,omitempty"`
+	Recovery              *string        `json:"recovery,omitempty"`               // How a failure was recovered (see ClipRecovery)
+	RecoveryReason        *string        `json:"recovery_reason,omitempty"`        // The error that triggered the recovery
+	ImagePromptRewrites   PromptRewrites `json:"image_prompt_rewrites,omitempty"`  // Prompts tried after image refusals
+	SpeechPace            *float64       `json:"speech_pace,omitempty"`            // Narration speed factor set by duration fitting (nil = 1.0)
+	HookVariant           *int           `json:"hook_variant,omitempty"`           // Alternative first clip number (nil = main video clip)
+	VariantVideoAssetID   *uuid.UUID     `json:"variant_video_asset_id,omitempty"` // Hook variants: full video with this first clip
+	Citations             Citations      `json:"citations,omitempty"`              // Research mode: source snippets backing the script
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
 }
 
 type Asset struct {
@@ -170,6 +423,19 @@ type Asset struct {
 	CreatedAt     time.Time  `json:"created_at"`
 }
 
+// ProviderCacheEntry maps a content hash of a provider call's inputs to the
+// asset holding its output.
+type ProviderCacheEntry struct {
+	Key        string     `json:"cache_key"`
+	Provider   string     `json:"provider"` // "gemini", "tts", "xai"
+	AssetID    uuid.UUID  `json:"asset_id"`
+	Metadata   JSONB      `json:"metadata,omitempty"`
+	HitCount   int        `json:"hit_count"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"` // nil = never expires
+}
+
 type Job struct {
 	ID           uuid.UUID  `json:"id"`
 	ProjectID    uuid.UUID  `json:"project_id"`
@@ -181,6 +447,7 @@ type Job struct {
 	FinishedAt   *time.Time `json:"finished_at,omitempty"`
 	ErrorMessage *string    `json:"error_message,omitempty"`
 	LogsAssetID  *uuid.UUID `json:"logs_asset_id,omitempty"`
+	PlanVersion  *int       `json:"plan_version,omitempty"` // render_final only: the plan version it renders (one job per version)
 	CreatedAt    time.Time  `json:"created_at"`
 }
 
@@ -188,19 +455,46 @@ type Job struct {
 type ProjectResponse struct {
 	Project
 	Clips           []ClipResponse `json:"clips,omitempty"`
+	HookVariants    []ClipResponse `json:"hook_variants,omitempty"` // Alternative first clips
 	FinalVideoURL   *string        `json:"final_video_url,omitempty"`
+	Subtitles       *SubtitleURLs  `json:"subtitles,omitempty"`
+	Covers          *CoverURLs     `json:"covers,omitempty"`
 	GraphicsPreset  *GraphicsPreset `json:"graphics_preset,omitempty"`
 }
 
+// SubtitleURLs holds public URLs for the project-level sidecar caption files
+// exported alongside the final video (soft captions for accessibility/uploads).
+type SubtitleURLs struct {
+	SRT *string `json:"srt,omitempty"`
+	VTT *string `json:"vtt,omitempty"`
+	ASS *string `json:"ass,omitempty"`
+}
+
+// CoverURLs holds public URLs for the project's cover images, one per
+// platform size.
+type CoverURLs struct {
+	Landscape *string `json:"landscape,omitempty"` // 1280x720
+	Portrait  *string `json:"portrait,omitempty"`  // 1080x1920
+}
+
 type ClipResponse struct {
 	Clip
-	AudioURL     *string `json:"audio_url,omitempty"`
-	ImageURL     *string `json:"image_url,omitempty"`
-	ClipVideoURL *string `json:"clip_video_url,omitempty"`
+	AudioURL        *string `json:"audio_url,omitempty"`
+	ImageURL        *string `json:"image_url,omitempty"`
+	ClipVideoURL    *string `json:"clip_video_url,omitempty"`
+	VariantVideoURL *string `json:"variant_video_url,omitempty"` // Hook variants only
+}
+
+// JobResponse is a job as shown by the debug endpoint, with a link to its
+// captured log (NDJSON: one {time, source, msg, fields} entry per line).
+type JobResponse struct {
+	Job
+	LogsURL *string `json:"logs_url,omitempty"` // Signed, valid for 1 hour
 }
 
 // ProjectSummary is a lightweight DTO for the list endpoint — no clips array,
-// just core project fields plus a thumbnail URL from clip 0's image.
+// just core project fields plus the cover URLs and a thumbnail URL (the
+// cover in the project's aspect ratio, or clip 0's image before there is one).
 type ProjectSummary struct {
 	ID                    uuid.UUID      `json:"id"`
 	Topic                 string         `json:"topic"`
@@ -209,6 +503,7 @@ type ProjectSummary struct {
 	Language              *string        `json:"language,omitempty"`
 	Status                ProjectStatus  `json:"status"`
 	ThumbnailURL          *string        `json:"thumbnail_url,omitempty"`
+	Covers                *CoverURLs     `json:"covers,omitempty"`
 	FinalVideoURL         *string        `json:"final_video_url,omitempty"`
 	ClipCount             int            `json:"clip_count"`
 	ErrorCode             *string        `json:"error_code,omitempty"`
@@ -236,9 +531,35 @@ type CreateProjectRequest struct {
 	MusicMood             *string    `json:"music_mood,omitempty"`       // Optional music mood hint
 	SampleImageURL        *string    `json:"sample_image_url,omitempty"` // Optional custom style reference
 	Language              *string    `json:"language,omitempty"`         // Default: "en"
+	SpeakerVoices         map[string]string `json:"speaker_voices,omitempty"` // Optional speaker role → voice ID (2+ roles enables dialogue)
+	TTSProvider           *string    `json:"tts_provider,omitempty"`     // Optional: pin "elevenlabs" or "cartesia" (voice IDs belong to it)
+	BypassCache           bool       `json:"bypass_cache,omitempty"`     // Optional: skip the provider output cache
+	ClipFailurePolicy     *string    `json:"clip_failure_policy,omitempty"` // Optional: "fail" (default), "rewrite_prompt", "fallback_visual" or "skip"
+	Sources               []ResearchSource `json:"sources,omitempty"`         // Optional: URLs or texts to ground the script in (research mode)
+	CitationEndCard       bool       `json:"citation_end_card,omitempty"` // Optional: end the video with a card listing the cited sources
 }
 
 type CreateProjectResponse struct {
 	ProjectID uuid.UUID     `json:"project_id"`
 	Status    ProjectStatus `json:"status"`
 }
+
+type VoicePreviewRequest struct {
+	Provider string `json:"provider,omitempty"` // Needed only if the voice ID exists at several providers
+	Text     string `json:"text,omitempty"`     // Default: a short sentence in the language
+	Language string `json:"language,omitempty"` // Default: the voice's own language
+}
+
+type VoicePreviewResponse struct {
+	VoiceID  string `json:"voice_id"`
+	Provider string `json:"provider"`
+	URL      string `json:"url"` // Public URL of the MP3 sample
+}
+
+type CreatePronunciationRequest struct {
+	UserID   *uuid.UUID `json:"user_id,omitempty"`
+	SeriesID *uuid.UUID `json:"series_id,omitempty"`
+	Term     string     `json:"term"`
+	Alias    *string    `json:"alias,omitempty"` // At least one of alias and ipa is required
+	IPA      *string    `json:"ipa,omitempty"`
+}
@@ -72,6 +72,7 @@ func TestClipStatus(t *testing.T) {
 		ClipStatusImaged,
 		ClipStatusRendered,
 		ClipStatusFailed,
+		ClipStatusSkipped,
 	}
 
 	for _, status := range statuses {
@@ -80,3 +81,22 @@ func TestClipStatus(t *testing.T) {
 		}
 	}
 }
+
+func TestClipFailurePolicyIsValid(t *testing.T) {
+	for _, p := range []ClipFailurePolicy{
+		ClipFailurePolicyFail,
+		ClipFailurePolicyRewritePrompt,
+		ClipFailurePolicyFallbackVisual,
+		ClipFailurePolicySkip,
+	} {
+		if !p.IsValid() {
+			t.Errorf("%q should be valid", p)
+		}
+	}
+
+	for _, p := range []ClipFailurePolicy{"", "retry", "SKIP"} {
+		if p.IsValid() {
+			t.Errorf("%q should be invalid", p)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// ---------------------------------------------------------------------------
+// Worker heartbeats — each worker process periodically writes its status to
+// a Redis key with a TTL, so live workers can be listed and a dead one drops
+// out on its own.
+// ---------------------------------------------------------------------------
+
+const heartbeatKeyPrefix = "worker:heartbeat:"
+
+// WorkerHeartbeat is the status a worker process reports.
+type WorkerHeartbeat struct {
+	ID          string         `json:"id"`
+	Hostname    string         `json:"hostname"`
+	Concurrency map[string]int `json:"concurrency"` // Consumers per queue
+	InFlight    int64          `json:"in_flight"`
+	JobIDs      []uuid.UUID    `json:"job_ids,omitempty"` // The in-flight jobs
+	Processed   int64          `json:"processed"` // Jobs succeeded since start
+	Failed      int64          `json:"failed"`    // Jobs failed since start
+	Draining    bool           `json:"draining"`  // Shutting down: finishing in-flight jobs, taking no new ones
+	StartedAt   time.Time      `json:"started_at"`
+	LastSeen    time.Time      `json:"last_seen"`
+}
+
+// PutHeartbeat records hb; it expires after ttl unless refreshed.
+func (q *Queue) PutHeartbeat(ctx context.Context, hb *WorkerHeartbeat, ttl time.Duration) error {
+	data, err := json.Marshal(hb)
+	if err != nil {
+		return fmt.Errorf("failed to marshal heartbeat: %w", err)
+	}
+	return q.client.Set(ctx, heartbeatKeyPrefix+hb.ID, data, ttl).Err()
+}
+
+// DeleteHeartbeat removes a worker's heartbeat (on clean shutdown).
+func (q *Queue) DeleteHeartbeat(ctx context.Context, id string) error {
+	return q.client.Del(ctx, heartbeatKeyPrefix+id).Err()
+}
+
+// ListHeartbeats returns the heartbeats of all live workers.
+func (q *Queue) ListHeartbeats(ctx context.Context) ([]WorkerHeartbeat, error) {
+	var keys []string
+	iter := q.client.Scan(ctx, 0, heartbeatKeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list heartbeats: %w", err)
+	}
+	if len(keys) == 0 {
+		return []WorkerHeartbeat{}, nil
+	}
+
+	values, err := q.client.MGet(ctx, keys...).Result()
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to read heartbeats: %w", err)
+	}
+
+	heartbeats := make([]WorkerHeartbeat, 0, len(values))
+	for _, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			continue // Expired between SCAN and MGET
+		}
+		var hb WorkerHeartbeat
+		if err := json.Unmarshal([]byte(data), &hb); err != nil {
+			continue
+		}
+		heartbeats = append(heartbeats, hb)
+	}
+	return heartbeats, nil
+}
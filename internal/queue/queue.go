@@ -6,8 +6,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/tracing"
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -25,6 +29,8 @@ type Job struct {
 	Type      string                 `json:"type"`
 	ProjectID uuid.UUID              `json:"project_id"`
 	ClipID    *uuid.UUID             `json:"clip_id,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"` // API request that started the project's work
+	Trace     map[string]string      `json:"trace,omitempty"`      // W3C trace context of the enqueuing span
 	Data      map[string]interface{} `json:"data,omitempty"`
 	CreatedAt time.Time              `json:"created_at"`
 }
@@ -52,8 +58,22 @@ func (q *Queue) Close() error {
 	return q.client.Close()
 }
 
-func (q *Queue) Enqueue(ctx context.Context, queueName string, job *Job) error {
+// Enqueue pushes a job. The job inherits the request ID and trace context
+// carried by ctx (the API request, or the job that enqueues it), keeping one
+// log trail and one trace per project.
+func (q *Queue) Enqueue(ctx context.Context, queueName string, job *Job) (err error) {
+	ctx, span := tracing.StartKind(ctx, "enqueue "+job.Type, trace.SpanKindProducer,
+		attribute.String("queue", queueName),
+		attribute.String("job.id", job.ID.String()),
+		attribute.String("project.id", job.ProjectID.String()),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	job.CreatedAt = time.Now()
+	if job.RequestID == "" {
+		job.RequestID = joblog.RequestID(ctx)
+	}
+	job.Trace = tracing.Inject(ctx)
 
 	data, err := json.Marshal(job)
 	if err != nil {
@@ -84,6 +104,17 @@ func (q *Queue) Dequeue(ctx context.Context, queueName string, timeout time.Dura
 	return &job, nil
 }
 
+// Requeue puts a job back at the head of its queue unchanged (same ID, request
+// and trace), so the next free worker picks it up first.
+func (q *Queue) Requeue(ctx context.Context, queueName string, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	return q.client.LPush(ctx, queueName, data).Err()
+}
+
 func (q *Queue) GetQueueLength(ctx context.Context, queueName string) (int64, error) {
 	return q.client.LLen(ctx, queueName).Result()
 }
@@ -118,3 +149,27 @@ func (q *Queue) EnqueueRenderFinal(ctx context.Context, projectID, jobID uuid.UU
 	}
 	return q.Enqueue(ctx, QueueRenderFinal, job)
 }
+
+// PendingJobIDs returns the IDs of all jobs waiting in the given queues.
+func (q *Queue) PendingJobIDs(ctx context.Context, queueNames ...string) (map[uuid.UUID]bool, error) {
+	ids := make(map[uuid.UUID]bool)
+	for _, name := range queueNames {
+		items, err := q.client.LRange(ctx, name, 0, -1).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		for _, item := range items {
+			var job Job
+			if err := json.Unmarshal([]byte(item), &job); err == nil {
+				ids[job.ID] = true
+			}
+		}
+	}
+	return ids, nil
+}
+
+// TryLock takes a named lock shared by all processes using this Redis, held
+// until it expires after ttl. Returns false if another process holds it.
+func (q *Queue) TryLock(ctx context.Context, name string, ttl time.Duration) (bool, error) {
+	return q.client.SetNX(ctx, "lock:"+name, time.Now().Format(time.RFC3339), ttl).Result()
+}
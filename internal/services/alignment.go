@@ -0,0 +1,221 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ---------------------------------------------------------------------------
+// Script Alignment — subtitle timings for text we already know
+//
+// The narration audio is synthesized from Clip.Script, so a free transcription
+// is only useful for its timings: Whisper sometimes mishears words, drops
+// punctuation, or formats numbers differently ("1,000" vs "one thousand").
+// Alignment keeps the exact script tokens (casing and punctuation included)
+// and borrows timings from whichever source is available:
+//
+//   - Whisper word timestamps, matched to script tokens with an edit-distance
+//     alignment (AlignWordsToScript)
+//   - Character-level timings from providers that return them, e.g. the
+//     ElevenLabs with-timestamps endpoint (WordsFromCharacterTimings)
+// ---------------------------------------------------------------------------
+
+// AlignmentMode selects how subtitle word timings are produced.
+type AlignmentMode string
+
+const (
+	// AlignmentScript maps transcription timings onto the exact script tokens (default).
+	AlignmentScript AlignmentMode = "script"
+	// AlignmentTranscript uses Whisper's free transcription as-is (legacy behavior).
+	AlignmentTranscript AlignmentMode = "transcript"
+)
+
+// ParseAlignmentMode converts a config string into an AlignmentMode.
+// Unknown values fall back to AlignmentScript.
+func ParseAlignmentMode(s string) AlignmentMode {
+	switch AlignmentMode(strings.ToLower(strings.TrimSpace(s))) {
+	case AlignmentTranscript:
+		return AlignmentTranscript
+	default:
+		return AlignmentScript
+	}
+}
+
+// AlignWordsToScript returns one WordTimestamp per whitespace-separated script
+// token, timed from the closest matching transcribed words.
+//
+// Script and transcript tokens are compared after normalization (lowercase,
+// letters and digits only) and aligned with a minimum edit-distance path:
+//   - matched or substituted tokens take the transcribed word's timing
+//   - extra transcribed words ("one thousand" for "1,000") extend the previous
+//     script token's end time
+//   - script tokens with no transcribed counterpart share the gap between
+//     their timed neighbors evenly
+//
+// If the transcript is empty, or the script has no tokens, the transcript is
+// returned unchanged.
+func AlignWordsToScript(script string, words []WordTimestamp) []WordTimestamp {
+	tokens := strings.Fields(script)
+	if len(tokens) == 0 || len(words) == 0 {
+		return words
+	}
+
+	scriptNorm := make([]string, len(tokens))
+	for i, t := range tokens {
+		scriptNorm[i] = normalizeAlignToken(t)
+	}
+	wordNorm := make([]string, len(words))
+	for i, w := range words {
+		wordNorm[i] = normalizeAlignToken(w.Word)
+	}
+
+	// Edit-distance table: cost[i][j] aligns tokens[:i] with words[:j]
+	n, m := len(tokens), len(words)
+	cost := make([][]int, n+1)
+	for i := range cost {
+		cost[i] = make([]int, m+1)
+		cost[i][0] = i
+	}
+	for j := 0; j <= m; j++ {
+		cost[0][j] = j
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			sub := cost[i-1][j-1]
+			if scriptNorm[i-1] != wordNorm[j-1] {
+				sub++
+			}
+			cost[i][j] = min(sub, min(cost[i-1][j]+1, cost[i][j-1]+1))
+		}
+	}
+
+	// Walk back through the table to recover the alignment path. On ties,
+	// exact matches win, then extra transcribed words, then substitutions —
+	// so a multi-word rendering like "one thousand" lands on a single script
+	// token instead of leaking into its neighbor.
+	type step struct{ script, word int } // -1 = gap on that side
+	var path []step
+	for i, j := n, m; i > 0 || j > 0; {
+		switch {
+		case i > 0 && j > 0 && scriptNorm[i-1] == wordNorm[j-1] && cost[i][j] == cost[i-1][j-1]:
+			path = append(path, step{i - 1, j - 1})
+			i, j = i-1, j-1
+		case j > 0 && cost[i][j] == cost[i][j-1]+1:
+			path = append(path, step{-1, j - 1})
+			j--
+		case i > 0 && cost[i][j] == cost[i-1][j]+1:
+			path = append(path, step{i - 1, -1})
+			i--
+		default:
+			path = append(path, step{i - 1, j - 1})
+			i, j = i-1, j-1
+		}
+	}
+
+	aligned := make([]WordTimestamp, n)
+	timed := make([]bool, n)
+	for i, t := range tokens {
+		aligned[i].Word = t
+	}
+
+	lastTimed := -1
+	pendingStart := -1.0 // start of transcribed words seen before the first timed script token
+	for k := len(path) - 1; k >= 0; k-- {
+		s := path[k]
+		switch {
+		case s.script >= 0 && s.word >= 0:
+			aligned[s.script].Start = words[s.word].Start
+			aligned[s.script].End = words[s.word].End
+			if pendingStart >= 0 && lastTimed < 0 {
+				aligned[s.script].Start = pendingStart
+			}
+			timed[s.script] = true
+			lastTimed = s.script
+		case s.word >= 0:
+			// Extra transcribed word — fold its time into the previous script token
+			if lastTimed >= 0 {
+				aligned[lastTimed].End = words[s.word].End
+			} else if pendingStart < 0 {
+				pendingStart = words[s.word].Start
+			}
+		}
+	}
+
+	// Fill runs of untimed script tokens by splitting the gap between neighbors
+	for i := 0; i < n; {
+		if timed[i] {
+			i++
+			continue
+		}
+		runStart := i
+		for i < n && !timed[i] {
+			i++
+		}
+		runEnd := i // exclusive
+
+		from := words[0].Start
+		if runStart > 0 {
+			from = aligned[runStart-1].End
+		}
+		to := words[m-1].End
+		if runEnd < n {
+			to = aligned[runEnd].Start
+		}
+		if to < from {
+			to = from
+		}
+
+		slot := (to - from) / float64(runEnd-runStart)
+		for k := runStart; k < runEnd; k++ {
+			aligned[k].Start = from + slot*float64(k-runStart)
+			aligned[k].End = aligned[k].Start + slot
+		}
+	}
+
+	return aligned
+}
+
+// WordsFromCharacterTimings groups character-level timings (as returned by the
+// ElevenLabs with-timestamps endpoint) into words split on whitespace.
+// The characters are the synthesized input text, so words keep the script's
+// exact spelling, casing and punctuation.
+func WordsFromCharacterTimings(chars []string, starts, ends []float64) []WordTimestamp {
+	if len(starts) < len(chars) || len(ends) < len(chars) {
+		return nil
+	}
+
+	var words []WordTimestamp
+	var current strings.Builder
+	var start, end float64
+	for i, c := range chars {
+		if strings.TrimSpace(c) == "" {
+			if current.Len() > 0 {
+				words = append(words, WordTimestamp{Word: current.String(), Start: start, End: end})
+				current.Reset()
+			}
+			continue
+		}
+		if current.Len() == 0 {
+			start = starts[i]
+		}
+		current.WriteString(c)
+		end = ends[i]
+	}
+	if current.Len() > 0 {
+		words = append(words, WordTimestamp{Word: current.String(), Start: start, End: end})
+	}
+
+	return words
+}
+
+// normalizeAlignToken lowercases a token and keeps only letters and digits,
+// so "Coffee," and "coffee" compare equal.
+func normalizeAlignToken(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,87 @@
+package services
+
+import "testing"
+
+func TestAlignWordsToScriptKeepsScriptTokens(t *testing.T) {
+	script := "In 1,000 years, Rome fell."
+	words := []WordTimestamp{
+		{Word: "in", Start: 0.0, End: 0.2},
+		{Word: "one", Start: 0.2, End: 0.5},
+		{Word: "thousand", Start: 0.5, End: 0.9},
+		{Word: "years", Start: 0.9, End: 1.3},
+		{Word: "roam", Start: 1.4, End: 1.8},
+		{Word: "fell", Start: 1.8, End: 2.2},
+	}
+
+	aligned := AlignWordsToScript(script, words)
+
+	want := []string{"In", "1,000", "years,", "Rome", "fell."}
+	if len(aligned) != len(want) {
+		t.Fatalf("expected %d words, got %d: %+v", len(want), len(aligned), aligned)
+	}
+	for i, w := range want {
+		if aligned[i].Word != w {
+			t.Errorf("word %d: expected %q, got %q", i, w, aligned[i].Word)
+		}
+	}
+
+	// "1,000" should cover both "one" and "thousand"
+	if aligned[1].Start != 0.2 || aligned[1].End != 0.9 {
+		t.Errorf("expected 1,000 to span 0.2-0.9, got %.2f-%.2f", aligned[1].Start, aligned[1].End)
+	}
+	if aligned[3].Start != 1.4 || aligned[4].End != 2.2 {
+		t.Errorf("unexpected trailing timings: %+v", aligned[3:])
+	}
+}
+
+func TestAlignWordsToScriptInterpolatesMissingWords(t *testing.T) {
+	aligned := AlignWordsToScript("The quiet old city", []WordTimestamp{
+		{Word: "The", Start: 0.0, End: 0.2},
+		{Word: "city", Start: 1.0, End: 1.4},
+	})
+
+	if len(aligned) != 4 {
+		t.Fatalf("expected 4 words, got %d", len(aligned))
+	}
+	if aligned[1].Start != 0.2 || aligned[2].End != 1.0 {
+		t.Errorf("expected untimed words to fill 0.2-1.0, got %+v", aligned[1:3])
+	}
+}
+
+func TestElevenLabsAlignmentTrimsAudioTag(t *testing.T) {
+	text := "[excited] Hi you"
+	var alignment elevenLabsAlignment
+	for i, r := range text {
+		alignment.Characters = append(alignment.Characters, string(r))
+		alignment.CharacterStartTimesSeconds = append(alignment.CharacterStartTimesSeconds, float64(i)/10)
+		alignment.CharacterEndTimesSeconds = append(alignment.CharacterEndTimesSeconds, float64(i+1)/10)
+	}
+
+	alignment.trimPrefix("[whispers] ") // Not the sent prefix: left as is
+	if len(alignment.Characters) != len(text) {
+		t.Fatalf("a prefix that doesn't match was trimmed")
+	}
+
+	alignment.trimPrefix("[excited] ")
+	words := WordsFromCharacterTimings(alignment.Characters, alignment.CharacterStartTimesSeconds, alignment.CharacterEndTimesSeconds)
+	if len(words) != 2 || words[0].Word != "Hi" || words[0].Start != 1.0 || words[1].Word != "you" {
+		t.Errorf("got words %+v, want Hi (from 1.0s) and you", words)
+	}
+}
+
+func TestWordsFromCharacterTimings(t *testing.T) {
+	chars := []string{"H", "i", ",", " ", "y", "o", "u"}
+	starts := []float64{0.0, 0.1, 0.2, 0.25, 0.3, 0.4, 0.5}
+	ends := []float64{0.1, 0.2, 0.25, 0.3, 0.4, 0.5, 0.6}
+
+	words := WordsFromCharacterTimings(chars, starts, ends)
+	if len(words) != 2 {
+		t.Fatalf("expected 2 words, got %d", len(words))
+	}
+	if words[0].Word != "Hi," || words[0].Start != 0.0 || words[0].End != 0.25 {
+		t.Errorf("unexpected first word: %+v", words[0])
+	}
+	if words[1].Word != "you" || words[1].Start != 0.3 || words[1].End != 0.6 {
+		t.Errorf("unexpected second word: %+v", words[1])
+	}
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// ---------------------------------------------------------------------------
+// Audio probing — exact duration and stream info from TTS output bytes
+//
+// TTS providers return encoded audio without duration metadata, so we read it
+// from the bytes themselves instead of estimating from word count:
+//   - MP3: walk every MPEG audio frame header and sum samples per frame
+//     (handles CBR and VBR, skips ID3v2 tags and the Xing/Info header frame)
+//   - WAV: read the fmt and data chunks of the RIFF container
+//
+// This runs in-process on bytes we already hold — no temp file or ffprobe call.
+// ---------------------------------------------------------------------------
+
+// AudioInfo describes a decoded audio stream.
+type AudioInfo struct {
+	DurationMs int
+	SampleRate int
+	Channels   int
+}
+
+// ProbeAudio measures the duration and stream parameters of encoded audio.
+// format is the container reported by the provider ("mp3" or "wav").
+func ProbeAudio(data []byte, format string) (*AudioInfo, error) {
+	switch format {
+	case "mp3":
+		return probeMP3(data)
+	case "wav":
+		return probeWAV(data)
+	default:
+		return nil, fmt.Errorf("unsupported audio format for probing: %q", format)
+	}
+}
+
+// MPEG audio lookup tables (kbps / Hz), indexed by header fields
+var (
+	mp3BitratesV1L1 = [16]int{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 0}
+	mp3BitratesV1L2 = [16]int{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 0}
+	mp3BitratesV1L3 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3BitratesV2L1 = [16]int{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, 0}
+	mp3BitratesV2L2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+
+	mp3SampleRatesV1  = [4]int{44100, 48000, 32000, 0}
+	mp3SampleRatesV2  = [4]int{22050, 24000, 16000, 0}
+	mp3SampleRatesV25 = [4]int{11025, 12000, 8000, 0}
+)
+
+// mp3Frame holds the fields of one parsed MPEG audio frame header.
+type mp3Frame struct {
+	length     int // bytes, including the 4-byte header
+	samples    int // PCM samples per channel in this frame
+	sampleRate int
+	channels   int
+	xingOffset int // Where a Xing/Info tag would start (Layer III only), 0 if none
+}
+
+// parseMP3FrameHeader decodes a 4-byte MPEG audio frame header.
+// Returns ok=false if the bytes are not a valid header.
+func parseMP3FrameHeader(h []byte) (mp3Frame, bool) {
+	if len(h) < 4 || h[0] != 0xFF || h[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
+	}
+
+	version := (h[1] >> 3) & 0x03 // 0 = 2.5, 2 = 2, 3 = 1
+	layer := (h[1] >> 1) & 0x03   // 1 = III, 2 = II, 3 = I
+	bitrateIdx := h[2] >> 4
+	sampleRateIdx := (h[2] >> 2) & 0x03
+	padding := int((h[2] >> 1) & 0x01)
+	channelMode := h[3] >> 6
+
+	if version == 1 || layer == 0 || bitrateIdx == 0 || bitrateIdx == 15 || sampleRateIdx == 3 {
+		return mp3Frame{}, false
+	}
+
+	var sampleRate, bitrate int
+	switch version {
+	case 3:
+		sampleRate = mp3SampleRatesV1[sampleRateIdx]
+		switch layer {
+		case 3:
+			bitrate = mp3BitratesV1L1[bitrateIdx]
+		case 2:
+			bitrate = mp3BitratesV1L2[bitrateIdx]
+		default:
+			bitrate = mp3BitratesV1L3[bitrateIdx]
+		}
+	case 2:
+		sampleRate = mp3SampleRatesV2[sampleRateIdx]
+	default:
+		sampleRate = mp3SampleRatesV25[sampleRateIdx]
+	}
+	if version != 3 {
+		if layer == 3 {
+			bitrate = mp3BitratesV2L1[bitrateIdx]
+		} else {
+			bitrate = mp3BitratesV2L2[bitrateIdx]
+		}
+	}
+	bitrate *= 1000
+
+	f := mp3Frame{sampleRate: sampleRate, channels: 2}
+	if channelMode == 3 {
+		f.channels = 1
+	}
+
+	// A Xing/Info tag follows the Layer III side information, whose size
+	// depends on the MPEG version and channel mode
+	if layer == 1 {
+		switch {
+		case version == 3 && f.channels == 1:
+			f.xingOffset = 4 + 17
+		case version == 3:
+			f.xingOffset = 4 + 32
+		case f.channels == 1:
+			f.xingOffset = 4 + 9
+		default:
+			f.xingOffset = 4 + 17
+		}
+	}
+
+	switch {
+	case layer == 3: // Layer I
+		f.samples = 384
+		f.length = (12*bitrate/sampleRate + padding) * 4
+	case layer == 1 && version != 3: // Layer III, MPEG-2/2.5
+		f.samples = 576
+		f.length = 72*bitrate/sampleRate + padding
+	default: // Layer II, or Layer III MPEG-1
+		f.samples = 1152
+		f.length = 144*bitrate/sampleRate + padding
+	}
+
+	if f.length < 4 {
+		return mp3Frame{}, false
+	}
+	return f, true
+}
+
+// probeMP3 sums the samples of every MPEG frame in data.
+func probeMP3(data []byte) (*AudioInfo, error) {
+	pos := 0
+
+	// Skip ID3v2 tag: "ID3" + version(2) + flags(1) + syncsafe size(4)
+	if len(data) >= 10 && bytes.Equal(data[:3], []byte("ID3")) {
+		size := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+		pos = 10 + size
+		if data[5]&0x10 != 0 {
+			pos += 10 // footer present
+		}
+	}
+
+	var totalSamples int64
+	var sampleRate, channels, frames int
+	for pos+4 <= len(data) {
+		frame, ok := parseMP3FrameHeader(data[pos : pos+4])
+		if !ok {
+			pos++ // lost sync — scan forward to the next frame header
+			continue
+		}
+		if pos+frame.length > len(data) {
+			break // truncated final frame
+		}
+
+		// The first frame of a LAME/Xing encode is a metadata frame with no audio
+		if frames == 0 && isXingFrame(data[pos:pos+frame.length], frame) {
+			pos += frame.length
+			frames++
+			continue
+		}
+
+		if sampleRate == 0 {
+			sampleRate = frame.sampleRate
+			channels = frame.channels
+		}
+		totalSamples += int64(frame.samples)
+		frames++
+		pos += frame.length
+	}
+
+	if sampleRate == 0 || totalSamples == 0 {
+		return nil, fmt.Errorf("no MPEG audio frames found (%d bytes)", len(data))
+	}
+
+	return &AudioInfo{
+		DurationMs: int(totalSamples * 1000 / int64(sampleRate)),
+		SampleRate: sampleRate,
+		Channels:   channels,
+	}, nil
+}
+
+// isXingFrame reports whether frame (its bytes in body) is a Xing/Info
+// metadata frame: the tag sits right after the side information.
+func isXingFrame(body []byte, frame mp3Frame) bool {
+	if frame.xingOffset == 0 || frame.xingOffset+4 > len(body) {
+		return false
+	}
+	tag := body[frame.xingOffset : frame.xingOffset+4]
+	return bytes.Equal(tag, []byte("Xing")) || bytes.Equal(tag, []byte("Info"))
+}
+
+// probeWAV reads the fmt and data chunks of a RIFF/WAVE file.
+func probeWAV(data []byte) (*AudioInfo, error) {
+	if len(data) < 12 || !bytes.Equal(data[:4], []byte("RIFF")) || !bytes.Equal(data[8:12], []byte("WAVE")) {
+		return nil, fmt.Errorf("not a RIFF/WAVE file")
+	}
+
+	var channels, sampleRate, byteRate int
+	pos := 12
+	for pos+8 <= len(data) {
+		chunkID := string(data[pos : pos+4])
+		chunkSize := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := pos + 8
+
+		switch chunkID {
+		case "fmt ":
+			if body+16 > len(data) {
+				return nil, fmt.Errorf("truncated fmt chunk")
+			}
+			channels = int(binary.LittleEndian.Uint16(data[body+2 : body+4]))
+			sampleRate = int(binary.LittleEndian.Uint32(data[body+4 : body+8]))
+			byteRate = int(binary.LittleEndian.Uint32(data[body+8 : body+12]))
+		case "data":
+			if byteRate == 0 {
+				return nil, fmt.Errorf("data chunk before fmt chunk")
+			}
+			// Streaming encoders may write a placeholder size — clamp to what we have
+			if chunkSize == 0 || body+chunkSize > len(data) {
+				chunkSize = len(data) - body
+			}
+			return &AudioInfo{
+				DurationMs: int(int64(chunkSize) * 1000 / int64(byteRate)),
+				SampleRate: sampleRate,
+				Channels:   channels,
+			}, nil
+		}
+
+		pos = body + chunkSize + chunkSize%2 // chunks are word-aligned
+	}
+
+	return nil, fmt.Errorf("no data chunk found")
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// MPEG audio frame headers used by the tests
+var (
+	mp3Header128  = []byte{0xFF, 0xFB, 0x90, 0x00} // MPEG-1 Layer III, 128 kbps, 44.1 kHz, stereo: 417 bytes
+	mp3Header192  = []byte{0xFF, 0xFB, 0xB0, 0x00} // MPEG-1 Layer III, 192 kbps, 44.1 kHz, stereo: 626 bytes
+	mp3HeaderMono = []byte{0xFF, 0xFB, 0x90, 0xC0} // MPEG-1 Layer III, 128 kbps, 44.1 kHz, mono: 417 bytes
+	mp3HeaderV2   = []byte{0xFF, 0xF3, 0x80, 0xC0} // MPEG-2 Layer III, 64 kbps, 22.05 kHz, mono: 208 bytes
+)
+
+// mp3TestFrame builds a frame of header with zeroed audio data and tag
+// written at offset (when tag is set).
+func mp3TestFrame(t *testing.T, header []byte, tag string, offset int) []byte {
+	t.Helper()
+	frame, ok := parseMP3FrameHeader(header)
+	if !ok {
+		t.Fatalf("invalid test header % X", header)
+	}
+	data := make([]byte, frame.length)
+	copy(data, header)
+	copy(data[offset:], tag)
+	return data
+}
+
+// mp3TestStream concatenates n frames of header.
+func mp3TestStream(t *testing.T, header []byte, n int) []byte {
+	var data []byte
+	for i := 0; i < n; i++ {
+		data = append(data, mp3TestFrame(t, header, "", 0)...)
+	}
+	return data
+}
+
+func TestProbeMP3(t *testing.T) {
+	join := func(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+	id3 := append([]byte("ID3\x04\x00\x00\x00\x00\x00\x14"), make([]byte, 20)...) // 20-byte tag
+
+	tests := []struct {
+		name       string
+		data       []byte
+		durationMs int
+		channels   int
+	}{
+		{
+			name:       "CBR",
+			data:       mp3TestStream(t, mp3Header128, 10), // 10 x 1152 samples
+			durationMs: 261,
+			channels:   2,
+		},
+		{
+			name: "VBR with Xing header",
+			data: join(
+				mp3TestFrame(t, mp3Header128, "Xing", 36),
+				mp3TestStream(t, mp3Header128, 5),
+				mp3TestStream(t, mp3Header192, 5),
+			),
+			durationMs: 261,
+			channels:   2,
+		},
+		{
+			name:       "mono Info header",
+			data:       join(mp3TestFrame(t, mp3HeaderMono, "Info", 21), mp3TestStream(t, mp3HeaderMono, 10)),
+			durationMs: 261,
+			channels:   1,
+		},
+		{
+			name:       "MPEG-2 Xing header",
+			data:       join(mp3TestFrame(t, mp3HeaderV2, "Xing", 13), mp3TestStream(t, mp3HeaderV2, 10)), // 10 x 576 samples
+			durationMs: 261,
+			channels:   1,
+		},
+		{
+			name:       "audio frame containing a tag's bytes",
+			data:       join(mp3TestFrame(t, mp3Header128, "Info", 200), mp3TestStream(t, mp3Header128, 10)),
+			durationMs: 287,
+			channels:   2,
+		},
+		{
+			name:       "ID3v2 prefixed",
+			data:       join(id3, mp3TestStream(t, mp3Header128, 10)),
+			durationMs: 261,
+			channels:   2,
+		},
+		{
+			name:       "truncated final frame",
+			data:       join(mp3TestStream(t, mp3Header128, 10), mp3TestFrame(t, mp3Header128, "", 0)[:200]),
+			durationMs: 261,
+			channels:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ProbeAudio(tt.data, "mp3")
+			if err != nil {
+				t.Fatalf("ProbeAudio: %v", err)
+			}
+			if info.DurationMs != tt.durationMs || info.Channels != tt.channels {
+				t.Errorf("got %d ms, %d channels; want %d ms, %d channels", info.DurationMs, info.Channels, tt.durationMs, tt.channels)
+			}
+		})
+	}
+
+	for name, data := range map[string][]byte{
+		"empty":           nil,
+		"no frames":       []byte("not an mp3 file at all"),
+		"only Xing frame": mp3TestFrame(t, mp3Header128, "Xing", 36),
+	} {
+		if _, err := ProbeAudio(data, "mp3"); err == nil {
+			t.Errorf("%s: ProbeAudio succeeded", name)
+		}
+	}
+}
+
+// wavChunk builds a RIFF chunk, padded to an even length.
+func wavChunk(id string, body []byte) []byte {
+	chunk := append([]byte(id), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	chunk = append(chunk, body...)
+	if len(body)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+// wavFile builds a RIFF/WAVE file of chunks.
+func wavFile(chunks ...[]byte) []byte {
+	body := append([]byte("WAVE"), bytes.Join(chunks, nil)...)
+	return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+}
+
+func TestProbeWAV(t *testing.T) {
+	// PCM, mono, 16 kHz, 16-bit: 32000 bytes per second
+	fmtChunk := wavChunk("fmt ", []byte{
+		1, 0, 1, 0, 0x80, 0x3E, 0, 0, 0x00, 0x7D, 0, 0, 2, 0, 16, 0,
+	})
+	second := make([]byte, 32000)
+
+	placeholder := wavFile(fmtChunk, wavChunk("data", second))
+	binary.LittleEndian.PutUint32(placeholder[len(placeholder)-len(second)-4:], 0)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		durationMs int
+	}{
+		{"plain", wavFile(fmtChunk, wavChunk("data", second)), 1000},
+		{"extra chunks", wavFile(wavChunk("JUNK", make([]byte, 27)), fmtChunk, wavChunk("LIST", []byte("INFOisft")), wavChunk("data", second[:16000])), 500},
+		{"placeholder data size", placeholder, 1000},
+		{"truncated data", wavFile(fmtChunk, wavChunk("data", second))[:44+8000], 250},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ProbeAudio(tt.data, "wav")
+			if err != nil {
+				t.Fatalf("ProbeAudio: %v", err)
+			}
+			if info.DurationMs != tt.durationMs || info.SampleRate != 16000 || info.Channels != 1 {
+				t.Errorf("got %+v, want %d ms at 16000 Hz mono", info, tt.durationMs)
+			}
+		})
+	}
+
+	for name, data := range map[string][]byte{
+		"not RIFF":        []byte("RIFX\x00\x00\x00\x00WAVE"),
+		"data before fmt": wavFile(wavChunk("data", second), fmtChunk),
+		"no data chunk":   wavFile(fmtChunk),
+		"truncated fmt":   wavFile(fmtChunk[:12]),
+	} {
+		if _, err := ProbeAudio(data, "wav"); err == nil {
+			t.Errorf("%s: ProbeAudio succeeded", name)
+		}
+	}
+	if _, err := ProbeAudio([]byte("fLaC"), "flac"); err == nil {
+		t.Error("ProbeAudio of an unsupported format succeeded")
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Sidecar Caption Export (SRT / WebVTT)
+//
+// The burned-in ASS subtitles are per-clip and styled for short-form video.
+// For accessibility and platform uploads (YouTube, etc.) we also export soft
+// captions for the whole project. Each clip's word timestamps are shifted onto
+// the final video's timeline, then grouped into readable cues.
+//
+// Cue rules:
+//   - At most captionMaxWords words per cue
+//   - A cue ends early at sentence-ending punctuation (., !, ?)
+//   - A cue never spans a clip boundary (there is a silence gap between clips)
+//   - A cue never spans a change of speaker in dialogue clips
+// ---------------------------------------------------------------------------
+
+const (
+	// Maximum words in a single sidecar caption cue (~2 lines of text on screen)
+	captionMaxWords = 8
+)
+
+// ClipWordTrack holds one clip's word timestamps and where that clip starts
+// on the final video's timeline.
+type ClipWordTrack struct {
+	Words     []WordTimestamp
+	OffsetSec float64 // Clip start in the final video + prepended silence
+}
+
+// CaptionCue is a single timed caption line in a sidecar subtitle file.
+type CaptionCue struct {
+	Start   float64 // seconds
+	End     float64 // seconds
+	Text    string
+	Speaker string // Dialogue speaker role, empty for single-narrator clips
+}
+
+// MergeWordTracks flattens per-clip word tracks onto a single project timeline
+// by adding each track's offset to its words.
+func MergeWordTracks(tracks []ClipWordTrack) []WordTimestamp {
+	var merged []WordTimestamp
+	for _, track := range tracks {
+		for _, w := range track.Words {
+			merged = append(merged, WordTimestamp{
+				Word:    w.Word,
+				Start:   w.Start + track.OffsetSec,
+				End:     w.End + track.OffsetSec,
+				Speaker: w.Speaker,
+			})
+		}
+	}
+	return merged
+}
+
+// BuildCaptionCues groups per-clip word tracks into caption cues on the
+// project timeline. Cues are built per track so none straddle a clip boundary.
+func BuildCaptionCues(tracks []ClipWordTrack) []CaptionCue {
+	var cues []CaptionCue
+	for _, track := range tracks {
+		var current []WordTimestamp
+		flush := func() {
+			if len(current) == 0 {
+				return
+			}
+			words := make([]string, 0, len(current))
+			for _, w := range current {
+				if t := strings.TrimSpace(w.Word); t != "" {
+					words = append(words, t)
+				}
+			}
+			if len(words) > 0 {
+				cues = append(cues, CaptionCue{
+					Start:   current[0].Start + track.OffsetSec,
+					End:     current[len(current)-1].End + track.OffsetSec,
+					Text:    strings.Join(words, " "),
+					Speaker: current[0].Speaker,
+				})
+			}
+			current = nil
+		}
+
+		for _, w := range track.Words {
+			if len(current) > 0 && current[0].Speaker != w.Speaker {
+				flush()
+			}
+			current = append(current, w)
+			if len(current) >= captionMaxWords || strings.ContainsAny(w.Word, ".!?") {
+				flush()
+			}
+		}
+		flush()
+	}
+	return cues
+}
+
+// FormatSRT renders caption cues as a SubRip (.srt) document.
+func FormatSRT(cues []CaptionCue) string {
+	var sb strings.Builder
+	for i, cue := range cues {
+		sb.WriteString(fmt.Sprintf("%d\n", i+1))
+		sb.WriteString(fmt.Sprintf("%s --> %s\n", formatCaptionTime(cue.Start, ","), formatCaptionTime(cue.End, ",")))
+		sb.WriteString(cue.Text)
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+// FormatWebVTT renders caption cues as a WebVTT (.vtt) document.
+// Dialogue cues carry a <v> voice span naming the speaker.
+func FormatWebVTT(cues []CaptionCue) string {
+	var sb strings.Builder
+	sb.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		sb.WriteString(fmt.Sprintf("%s --> %s\n", formatCaptionTime(cue.Start, "."), formatCaptionTime(cue.End, ".")))
+		if cue.Speaker != "" {
+			sb.WriteString(fmt.Sprintf("<v %s>", cue.Speaker))
+		}
+		sb.WriteString(cue.Text)
+		sb.WriteString("\n\n")
+	}
+	return sb.String()
+}
+
+// formatCaptionTime converts seconds to HH:MM:SS<sep>mmm.
+// SRT uses a comma before the milliseconds, WebVTT uses a period.
+func formatCaptionTime(seconds float64, msSep string) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	totalMs := int(seconds*1000 + 0.5)
+	hours := totalMs / 3600000
+	minutes := (totalMs % 3600000) / 60000
+	secs := (totalMs % 60000) / 1000
+	ms := totalMs % 1000
+
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", hours, minutes, secs, msSep, ms)
+}
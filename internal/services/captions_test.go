@@ -0,0 +1,61 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildCaptionCuesOffsetsAndSplits(t *testing.T) {
+	tracks := []ClipWordTrack{
+		{
+			OffsetSec: 0.5,
+			Words: []WordTimestamp{
+				{Word: "Coffee", Start: 0.0, End: 0.4},
+				{Word: "changed", Start: 0.4, End: 0.8},
+				{Word: "everything.", Start: 0.8, End: 1.3},
+				{Word: "Really", Start: 1.5, End: 1.9},
+			},
+		},
+		{
+			OffsetSec: 10.5,
+			Words: []WordTimestamp{
+				{Word: "Then", Start: 0.0, End: 0.3},
+			},
+		},
+	}
+
+	cues := BuildCaptionCues(tracks)
+	if len(cues) != 3 {
+		t.Fatalf("expected 3 cues, got %d: %+v", len(cues), cues)
+	}
+
+	if cues[0].Text != "Coffee changed everything." {
+		t.Errorf("unexpected first cue text: %q", cues[0].Text)
+	}
+	if cues[0].Start != 0.5 || cues[0].End != 1.8 {
+		t.Errorf("unexpected first cue timing: %.2f-%.2f", cues[0].Start, cues[0].End)
+	}
+	if cues[2].Text != "Then" || cues[2].Start != 10.5 {
+		t.Errorf("second clip cue should start at its offset, got %+v", cues[2])
+	}
+}
+
+func TestFormatSRTAndWebVTT(t *testing.T) {
+	cues := []CaptionCue{
+		{Start: 0.5, End: 1.8, Text: "Coffee changed everything."},
+		{Start: 3661.25, End: 3662.0, Text: "Later"},
+	}
+
+	srt := FormatSRT(cues)
+	if !strings.HasPrefix(srt, "1\n00:00:00,500 --> 00:00:01,800\nCoffee changed everything.\n\n") {
+		t.Errorf("unexpected SRT output:\n%s", srt)
+	}
+	if !strings.Contains(srt, "2\n01:01:01,250 --> 01:01:02,000\nLater\n") {
+		t.Errorf("SRT missing second cue:\n%s", srt)
+	}
+
+	vtt := FormatWebVTT(cues)
+	if !strings.HasPrefix(vtt, "WEBVTT\n\n00:00:00.500 --> 00:00:01.800\n") {
+		t.Errorf("unexpected WebVTT output:\n%s", vtt)
+	}
+}
@@ -7,7 +7,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
+	"unicode"
+
+	"github.com/bobarin/episod/internal/tracing"
 )
 
 const (
@@ -16,14 +20,32 @@ const (
 
 	// Default voice ID (you should replace with actual voice IDs from Cartesia)
 	DefaultVoiceID = "a0e99841-438c-4a64-b679-ae501e7d6091" // Example voice ID
+
+	// Models: sonic-english for English, sonic-multilingual for everything else
+	cartesiaEnglishModel      = "sonic-english"
+	cartesiaMultilingualModel = "sonic-multilingual"
 )
 
+// cartesiaLanguages are the languages supported by sonic-multilingual (ISO 639-1).
+var cartesiaLanguages = map[string]bool{
+	"en": true, "de": true, "es": true, "fr": true, "ja": true,
+	"pt": true, "zh": true, "hi": true, "it": true, "ko": true,
+	"nl": true, "pl": true, "ru": true, "sv": true, "tr": true,
+}
+
 type CartesiaService struct {
 	apiKey         string
 	apiURL         string
 	apiVersion     string
 	defaultVoiceID string
 	client         *http.Client
+
+	// languageVoices maps a language to the default voice used for it when
+	// the request has no voice override (CARTESIA_LANGUAGE_VOICES).
+	languageVoices map[string]string
+	// languageCheck applies the voice language policy, caching each voice's
+	// native language (GET /voices/{id}).
+	languageCheck voiceLanguageCheck
 }
 
 func NewCartesiaService(apiKey, apiURL string) *CartesiaService {
@@ -32,7 +54,8 @@ func NewCartesiaService(apiKey, apiURL string) *CartesiaService {
 		apiURL:         apiURL,
 		apiVersion:     CartesiaAPIVersion,
 		defaultVoiceID: DefaultVoiceID,
-		client:         &http.Client{Timeout: 60 * time.Second},
+		client:         &http.Client{Timeout: 60 * time.Second, Transport: tracing.Transport(nil)},
+		languageCheck:  voiceLanguageCheck{provider: "Cartesia"},
 	}
 }
 
@@ -46,10 +69,26 @@ func NewCartesiaServiceWithVoice(apiKey, apiURL, voiceID string) *CartesiaServic
 		apiURL:         apiURL,
 		apiVersion:     CartesiaAPIVersion,
 		defaultVoiceID: voiceID,
-		client:         &http.Client{Timeout: 60 * time.Second},
+		client:         &http.Client{Timeout: 60 * time.Second, Transport: tracing.Transport(nil)},
+		languageCheck:  voiceLanguageCheck{provider: "Cartesia"},
 	}
 }
 
+// WithLanguageVoices sets per-language default voices, keyed by ISO 639-1 code.
+// They apply when a request has no voice override; the service default voice
+// is used for languages without an entry.
+func (s *CartesiaService) WithLanguageVoices(voices map[string]string) *CartesiaService {
+	s.languageVoices = voices
+	return s
+}
+
+// WithVoiceLanguagePolicy sets how a voice/language mismatch is handled
+// (default: VoiceLanguageWarn).
+func (s *CartesiaService) WithVoiceLanguagePolicy(policy VoiceLanguagePolicy) *CartesiaService {
+	s.languageCheck.policy = policy
+	return s
+}
+
 // CartesiaRequest matches the actual Cartesia API specification
 type CartesiaRequest struct {
 	ModelID      string                  `json:"model_id"`
@@ -101,32 +140,58 @@ type GenerateSpeechOptions struct {
 
 // GenerateSpeech generates audio from text using Cartesia TTS.
 // Implements the TTSService interface.
-// voiceID overrides the default voice when non-empty (Cartesia uses its own voice IDs).
-func (s *CartesiaService) GenerateSpeech(ctx context.Context, text, voiceStyle, voiceID string) (*TTSResponse, error) {
-	// Parse emotion from voiceStyle (simple heuristic)
-	emotion := parseEmotionFromStyle(voiceStyle)
+// req.VoiceID overrides the default voice when non-empty (Cartesia uses its own voice IDs).
+// req.Language selects the model (sonic-english or sonic-multilingual) and, without
+// a voice override, the language's default voice. Script markup and the lexicon
+// are rendered into Cartesia's transcript tags (see cartesiaMarkup).
+func (s *CartesiaService) GenerateSpeech(ctx context.Context, req SpeechRequest) (*TTSResponse, error) {
+	lang := normalizeTTSLanguage(req.Language)
+	if !cartesiaLanguages[lang] {
+		return nil, fmt.Errorf("%w: Cartesia does not support language %q", ErrTTSLanguageMismatch, lang)
+	}
 
 	effectiveVoice := s.defaultVoiceID
-	if voiceID != "" {
-		effectiveVoice = voiceID
+	if languageVoice, ok := s.languageVoices[lang]; ok {
+		effectiveVoice = languageVoice
+	}
+	if req.VoiceID != "" {
+		effectiveVoice = req.VoiceID
+	}
+
+	if err := s.languageCheck.check(ctx, effectiveVoice, lang, s.voiceLanguage); err != nil {
+		return nil, err
 	}
 
+	// Defaults: slower pace for clear narration, louder output for mobile
+	// viewing — overridden by the project's base settings, then adjusted for
+	// the style instruction
+	delivery := ParseVoiceStyle(req.VoiceStyle)
+	delivery.Pace *= req.paceFactor()
+	base := req.Settings
+	if base == nil {
+		base = &VoiceSettings{}
+	}
+	emotion := delivery.Emotion
+	if emotion == "" {
+		emotion = "neutral"
+	}
 	opts := GenerateSpeechOptions{
 		VoiceID:  effectiveVoice,
-		Language: "en",
+		Language: lang,
 		Emotion:  emotion,
-		Speed:    0.85, // Slower pace for clear, natural-sounding narration
-		Volume:   1.4,  // Louder output for mobile viewing
+		Speed:    clampFloat(valueOr(base.Speed, 0.85)*delivery.Pace, 0.6, 1.5),
+		Volume:   clampFloat(valueOr(base.Volume, 1.4), 0.5, 2.0),
 	}
 
-	return s.GenerateSpeechWithOptions(ctx, text, opts)
+	transcript := renderScriptMarkup(req.Text, cartesiaMarkup{lexicon: req.Lexicon})
+	return s.GenerateSpeechWithOptions(ctx, transcript, opts)
 }
 
 // GenerateSpeechWithOptions generates audio with detailed Cartesia-specific configuration.
 func (s *CartesiaService) GenerateSpeechWithOptions(ctx context.Context, text string, opts GenerateSpeechOptions) (*TTSResponse, error) {
 	// Build request body
 	reqBody := CartesiaRequest{
-		ModelID:    "sonic-english", // Use sonic-english or sonic-multilingual
+		ModelID:    cartesiaModelForLanguage(opts.Language),
 		Transcript: text,
 		Voice: CartesiaVoiceSpecifier{
 			Mode: "id",
@@ -191,7 +256,7 @@ func (s *CartesiaService) GenerateSpeechWithOptions(ctx context.Context, text st
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("cartesia returned status %d: %s", resp.StatusCode, string(body))
+		return nil, &TTSStatusError{Provider: TTSProviderCartesia, StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// Read audio data
@@ -200,51 +265,112 @@ func (s *CartesiaService) GenerateSpeechWithOptions(ctx context.Context, text st
 		return nil, fmt.Errorf("failed to read audio: %w", err)
 	}
 
-	// Calculate duration (approximate based on text length)
-	durationMs := estimateAudioDuration(text, opts.Speed)
+	// Measure duration from the returned MP3 (falls back to a text-length estimate)
+	return newTTSResponse(ctx, audioData, reqBody.OutputFormat.Container, text, opts.Speed), nil
+}
+
+// ModelName names both Cartesia models; the request language picks between
+// them. Implements TTSModelNamer.
+func (s *CartesiaService) ModelName() string {
+	return cartesiaEnglishModel + "/" + cartesiaMultilingualModel
+}
+
+// cartesiaModelForLanguage picks sonic-english for English (or unspecified)
+// text and sonic-multilingual for any other language.
+func cartesiaModelForLanguage(language string) string {
+	if normalizeTTSLanguage(language) == "en" {
+		return cartesiaEnglishModel
+	}
+	return cartesiaMultilingualModel
+}
+
+// voiceLanguage looks up a voice's native language (none if unknown).
+func (s *CartesiaService) voiceLanguage(ctx context.Context, voiceID string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/voices/%s", s.apiURL, voiceID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Cartesia-Version", s.apiVersion)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &TTSStatusError{Provider: TTSProviderCartesia, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var voice struct {
+		Language string `json:"language"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&voice); err != nil {
+		return nil, fmt.Errorf("failed to parse voice: %w", err)
+	}
+
+	if voice.Language == "" {
+		return nil, nil
+	}
+	return []string{normalizeTTSLanguage(voice.Language)}, nil
+}
 
-	return &TTSResponse{
-		AudioData:  audioData,
-		DurationMs: durationMs,
-		Format:     "mp3",
-	}, nil
+// cartesiaMarkup renders script markup as Cartesia transcript tags:
+// <break time="500ms"/> for pauses and <spell>…</spell> for spelled words.
+// Lexicon aliases are substituted inline; IPA-only entries use Cartesia's
+// custom pronunciation syntax (<<k|i|ˈɑ|n|o|ʊ>>). Cartesia has no emphasis
+// control, so emphasized words are spoken plainly.
+type cartesiaMarkup struct {
+	lexicon Lexicon
 }
 
-// parseEmotionFromStyle attempts to extract emotion from voice style instruction
-func parseEmotionFromStyle(style string) string {
-	// Map common descriptive words to Cartesia emotions
-	emotionMap := map[string]string{
-		"energetic":     "excited",
-		"engaging":      "enthusiastic",
-		"mysterious":    "mysterious",
-		"serious":       "calm",
-		"authoritative": "confident",
-		"dramatic":      "intense",
-		"calm":          "calm",
-		"peaceful":      "peaceful",
-		"excited":       "excited",
-		"happy":         "happy",
-		"sad":           "sad",
-		"angry":         "angry",
-		"scared":        "scared",
-		"confident":     "confident",
-	}
-
-	// Convert to lowercase for matching
-	styleLower := bytes.ToLower([]byte(style))
-
-	// Check for emotion keywords
-	for keyword, emotion := range emotionMap {
-		if bytes.Contains(styleLower, []byte(keyword)) {
-			return emotion
+func (m cartesiaMarkup) text(s string) string {
+	return m.lexicon.apply(s, func(rule PronunciationRule) (string, bool) {
+		if rule.Alias != "" {
+			return rule.Alias, true
 		}
+		return "<<" + strings.Join(splitIPAPhonemes(rule.IPA), "|") + ">>", true
+	})
+}
+
+func (m cartesiaMarkup) pause(d time.Duration) string {
+	return fmt.Sprintf(`<break time="%s"/>`, formatBreakMillis(d))
+}
+
+func (m cartesiaMarkup) emphasis(s string) string { return m.text(s) }
+
+func (m cartesiaMarkup) spell(s string) string { return "<spell>" + s + "</spell>" }
+
+// splitIPAPhonemes splits an IPA transcription into phonemes for Cartesia.
+// Pipe-separated input is taken as already split. Otherwise each symbol is a
+// phoneme, with stress marks kept on the following symbol and length marks,
+// tie bars and diacritics kept on the preceding one.
+func splitIPAPhonemes(ipa string) []string {
+	ipa = strings.ReplaceAll(strings.TrimSpace(ipa), " ", "")
+	if strings.Contains(ipa, "|") {
+		return strings.Split(ipa, "|")
 	}
 
-	// Default to neutral
-	return "neutral"
+	var phonemes []string
+	stress := ""
+	for _, r := range ipa {
+		switch {
+		case r == 'ˈ' || r == 'ˌ':
+			stress += string(r)
+		case (unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Lm, r)) && len(phonemes) > 0 && stress == "":
+			phonemes[len(phonemes)-1] += string(r)
+		default:
+			phonemes = append(phonemes, stress+string(r))
+			stress = ""
+		}
+	}
+	return phonemes
 }
 
-// estimateAudioDuration estimates duration based on text length and speed
+// estimateAudioDuration estimates duration based on text length and speed.
+// Used only as a fallback when the returned audio can't be probed.
 // Average speaking rate is ~140 words per minute at normal speed (narration pace, not conversational)
 func estimateAudioDuration(text string, speed float64) int {
 	words := len(bytes.Fields([]byte(text)))
@@ -256,3 +382,66 @@ func estimateAudioDuration(text string, speed float64) int {
 	minutes := float64(words) / actualWPM
 	return int(minutes * 60 * 1000) // Convert to milliseconds
 }
+
+// cartesiaVoice is one voice from GET /voices.
+type cartesiaVoice struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Language    string `json:"language"`
+	Gender      string `json:"gender"`
+}
+
+// ListVoices returns the voices available to the account (GET /voices).
+// Implements the VoiceLister interface.
+func (s *CartesiaService) ListVoices(ctx context.Context) ([]Voice, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/voices", s.apiURL), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Cartesia-Version", s.apiVersion)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read voices: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &TTSStatusError{Provider: TTSProviderCartesia, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	// Older API versions return a bare array, newer ones a page: {"data": [...]}
+	var list []cartesiaVoice
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(body, &list)
+	} else {
+		var page struct {
+			Data []cartesiaVoice `json:"data"`
+		}
+		err = json.Unmarshal(body, &page)
+		list = page.Data
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse voices: %w", err)
+	}
+
+	voices := make([]Voice, 0, len(list))
+	for _, v := range list {
+		lang := normalizeTTSLanguage(v.Language)
+		voices = append(voices, Voice{
+			ID:          v.ID,
+			Name:        v.Name,
+			Description: v.Description,
+			Language:    lang,
+			Languages:   []string{lang},
+			Gender:      normalizeVoiceGender(v.Gender),
+		})
+	}
+	return voices, nil
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/models"
+)
+
+// ---------------------------------------------------------------------------
+// Covers — the project's thumbnail: a still from the video with its title
+// overlaid in the style of the project's graphics preset, rendered in each
+// platform size. A preset's style_json may set its own "cover" font and
+// colours; the seeded presets have built-in ones, and anything else gets the
+// burned-in caption style (white Noto Sans, black outline).
+// ---------------------------------------------------------------------------
+
+// CoverSize is one platform size a cover is rendered in.
+type CoverSize struct {
+	Name   string // "landscape", "portrait"
+	Width  int
+	Height int
+}
+
+var (
+	CoverLandscape = CoverSize{Name: "landscape", Width: 1280, Height: 720} // YouTube thumbnail
+	CoverPortrait  = CoverSize{Name: "portrait", Width: 1080, Height: 1920} // TikTok, Shorts and Reels cover
+)
+
+// CoverSizes lists the sizes every cover is rendered in.
+var CoverSizes = []CoverSize{CoverLandscape, CoverPortrait}
+
+const coverMaxLines = 4
+
+// CoverStyle is how a cover's title is drawn. Colours are FFmpeg colours
+// ("white", "0xFFE14D").
+type CoverStyle struct {
+	Font         string `json:"font"`
+	TextColor    string `json:"text_color"`
+	OutlineColor string `json:"outline_color"`
+}
+
+// defaultCoverStyle matches the burned-in captions.
+var defaultCoverStyle = CoverStyle{Font: subtitleFontName, TextColor: "white", OutlineColor: "black"}
+
+// presetCoverStyles are the cover styles of the seeded graphics presets, by slug.
+var presetCoverStyles = map[string]CoverStyle{
+	"cinematic_watercolor":  {Font: "Noto Serif", TextColor: "0xFFF4E0", OutlineColor: "0x3B2A1A"},
+	"illustrated_editorial": {Font: "Noto Serif", TextColor: "white", OutlineColor: "0x1A1A1A"},
+	"anime_inspired":        {Font: subtitleFontName, TextColor: "0xFFE14D", OutlineColor: "black"},
+	"cartoon_stylized":      {Font: subtitleFontName, TextColor: "white", OutlineColor: "0x1E3A8A"},
+	"digital_painting":      {Font: "Noto Serif", TextColor: "0xF5E6C8", OutlineColor: "black"},
+	"minimalist_abstract":   {Font: subtitleFontName, TextColor: "white", OutlineColor: "0x222222"},
+	"low_poly_3d":           {Font: subtitleFontName, TextColor: "0x7FE3FF", OutlineColor: "0x10203A"},
+}
+
+// Values from style_json end up in an FFmpeg filter, so only plain names
+// and hex colours are accepted
+var (
+	coverFontPattern  = regexp.MustCompile(`^[A-Za-z0-9 ]{1,64}$`)
+	coverColorPattern = regexp.MustCompile(`^([a-zA-Z]{1,32}|0x[0-9A-Fa-f]{6})$`)
+)
+
+// CoverStyleFor returns the cover style of a graphics preset (nil = none):
+// its style_json "cover" entries over its built-in style, over the caption
+// style. Invalid entries are ignored.
+func CoverStyleFor(preset *models.GraphicsPreset) CoverStyle {
+	style := defaultCoverStyle
+	if preset == nil {
+		return style
+	}
+	if preset.Slug != nil {
+		if builtin, ok := presetCoverStyles[*preset.Slug]; ok {
+			style = builtin
+		}
+	}
+	custom, _ := preset.StyleJSON["cover"].(map[string]interface{})
+	for key, field := range map[string]*string{"font": &style.Font, "text_color": &style.TextColor, "outline_color": &style.OutlineColor} {
+		value, _ := custom[key].(string)
+		pattern := coverColorPattern
+		if key == "font" {
+			pattern = coverFontPattern
+		}
+		if pattern.MatchString(value) {
+			*field = value
+		}
+	}
+	return style
+}
+
+// coverTitleLayout sizes the title for a cover: the font size and the
+// title wrapped to fit 85% of the width, at most coverMaxLines lines.
+func coverTitleLayout(title string, size CoverSize) (int, []string) {
+	short := size.Width
+	if size.Height < short {
+		short = size.Height
+	}
+	fontSize := short / 9
+	// Average glyph width is ~0.55em
+	lineChars := int(float64(size.Width) * 0.85 / (float64(fontSize) * 0.55))
+	lines := WrapText(title, lineChars)
+	if len(lines) > coverMaxLines {
+		lines = lines[:coverMaxLines]
+		lines[coverMaxLines-1] += "…"
+	}
+	return fontSize, lines
+}
+
+// RenderCover renders a JPEG cover of size: imagePath scaled and cropped to
+// fill it (a plain dark background when empty), a shade over the lower part
+// and title on it, drawn in style.
+func (s *FFmpegService) RenderCover(ctx context.Context, imagePath, title string, size CoverSize, style CoverStyle, outputPath string) error {
+	fontSize, lines := coverTitleLayout(title, size)
+
+	// drawtext reads the text from a file, so the title needs no escaping
+	textPath := outputPath + ".txt"
+	if err := os.WriteFile(textPath, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		return fmt.Errorf("failed to write cover title: %w", err)
+	}
+	defer os.Remove(textPath)
+
+	var input []string
+	if imagePath != "" {
+		input = []string{"-i", imagePath}
+	} else {
+		input = []string{"-f", "lavfi", "-i", fmt.Sprintf("color=c=%s:s=%dx%d", textCardBackground, size.Width, size.Height)}
+	}
+
+	// The title sits in the lower part, above the platforms' own overlays
+	vf := fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d,"+
+		"drawbox=x=0:y=ih*0.5:w=iw:h=ih*0.5:color=black@0.45:t=fill,"+
+		"drawtext=font='%s':textfile='%s':fontcolor=%s:fontsize=%d:line_spacing=%d:"+
+		"borderw=%d:bordercolor=%s:x=(w-text_w)/2:y=h*0.8-text_h",
+		size.Width, size.Height, size.Width, size.Height,
+		style.Font, escapeFFmpegFilterPath(textPath), style.TextColor, fontSize, fontSize/4, fontSize/16, style.OutlineColor)
+
+	args := append(input,
+		"-vf", vf,
+		"-frames:v", "1",
+		"-q:v", "2",
+		"-y",
+		outputPath,
+	)
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = joblog.Stderr(ctx, "ffmpeg")
+
+	if err := runFFmpeg(ctx, cmd, "cover"); err != nil {
+		return fmt.Errorf("ffmpeg cover failed: %w", err)
+	}
+
+	return nil
+}
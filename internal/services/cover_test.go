@@ -0,0 +1,52 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobarin/episod/internal/models"
+)
+
+func TestCoverTitleLayout(t *testing.T) {
+	title := "The lake that waited fifteen million years beneath the Antarctic ice, and what scientists found inside it"
+
+	fontSize, lines := coverTitleLayout(title, CoverLandscape)
+	if fontSize != 80 {
+		t.Errorf("landscape font size = %d, want 80", fontSize)
+	}
+	if len(lines) != 4 || !strings.HasSuffix(lines[3], "…") {
+		t.Errorf("landscape lines = %q, want 4 lines ending in an ellipsis", lines)
+	}
+
+	fontSize, lines = coverTitleLayout("Pizza", CoverPortrait)
+	if fontSize != 120 || !reflect.DeepEqual(lines, []string{"Pizza"}) {
+		t.Errorf("portrait layout = %d %q, want 120 [\"Pizza\"]", fontSize, lines)
+	}
+}
+
+func TestCoverStyleFor(t *testing.T) {
+	slug := func(s string) *string { return &s }
+
+	tests := []struct {
+		name   string
+		preset *models.GraphicsPreset
+		want   CoverStyle
+	}{
+		{"no preset", nil, defaultCoverStyle},
+		{"unknown preset", &models.GraphicsPreset{Slug: slug("custom")}, defaultCoverStyle},
+		{"seeded preset", &models.GraphicsPreset{Slug: slug("anime_inspired")}, presetCoverStyles["anime_inspired"]},
+		{
+			name: "style_json overrides",
+			preset: &models.GraphicsPreset{Slug: slug("anime_inspired"), StyleJSON: models.JSONB{
+				"cover": map[string]interface{}{"font": "Noto Serif", "text_color": "0x112233", "outline_color": "red':x=0"},
+			}},
+			want: CoverStyle{Font: "Noto Serif", TextColor: "0x112233", OutlineColor: "black"},
+		},
+	}
+	for _, tt := range tests {
+		if got := CoverStyleFor(tt.preset); got != tt.want {
+			t.Errorf("%s: CoverStyleFor = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
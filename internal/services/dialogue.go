@@ -0,0 +1,75 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/bobarin/episod/internal/models"
+)
+
+// ---------------------------------------------------------------------------
+// Dialogue Narration — multiple voices within a clip
+//
+// When a project maps two or more speaker roles to voices, the planner writes
+// each clip as speaker-tagged lines. Every line is synthesized with its own
+// voice and the segments are joined with short gaps (ConcatenateAudioWithGaps).
+//
+// The clip script is always the lines' text joined in order, so the subtitle
+// tokens produced by script alignment map back onto lines one to one — that is
+// how words get their speaker for per-speaker subtitle colors (TagSpeakers).
+// ---------------------------------------------------------------------------
+
+// NarratorSpeaker is the default speaker role. It always sorts first and its
+// subtitles keep the default color.
+const NarratorSpeaker = "narrator"
+
+// OrderSpeakers returns the speaker roles of a voice map in a stable order:
+// the narrator first (if mapped), then the remaining roles alphabetically.
+func OrderSpeakers(voices map[string]string) []string {
+	speakers := make([]string, 0, len(voices))
+	for role := range voices {
+		if role != NarratorSpeaker {
+			speakers = append(speakers, role)
+		}
+	}
+	sort.Strings(speakers)
+
+	if _, ok := voices[NarratorSpeaker]; ok {
+		speakers = append([]string{NarratorSpeaker}, speakers...)
+	}
+	return speakers
+}
+
+// ScriptFromLines joins dialogue lines into the clip's full script text.
+func ScriptFromLines(lines []models.ScriptLine) string {
+	texts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if t := strings.TrimSpace(line.Text); t != "" {
+			texts = append(texts, t)
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+// TagSpeakers sets the Speaker of each word from the dialogue line it belongs
+// to. words must hold one entry per whitespace-separated token of the lines'
+// plain text (markup stripped, as produced by script alignment); otherwise
+// the words are left untouched and false is returned.
+func TagSpeakers(words []WordTimestamp, lines []models.ScriptLine) bool {
+	total := 0
+	for _, line := range lines {
+		total += len(strings.Fields(PlainScript(line.Text)))
+	}
+	if total == 0 || total != len(words) {
+		return false
+	}
+
+	i := 0
+	for _, line := range lines {
+		for range strings.Fields(PlainScript(line.Text)) {
+			words[i].Speaker = line.Speaker
+			i++
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bobarin/episod/internal/models"
+)
+
+func TestOrderSpeakersPutsNarratorFirst(t *testing.T) {
+	got := OrderSpeakers(map[string]string{"bob": "v2", "narrator": "v0", "alice": "v1"})
+	want := []string{"narrator", "alice", "bob"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("OrderSpeakers = %v, want %v", got, want)
+	}
+}
+
+func TestTagSpeakersAndColoredChunks(t *testing.T) {
+	lines := []models.ScriptLine{
+		{Speaker: "narrator", Text: "She turned around."},
+		{Speaker: "alice", Text: "Who's there?"},
+	}
+	script := ScriptFromLines(lines)
+	if script != "She turned around. Who's there?" {
+		t.Fatalf("unexpected script: %q", script)
+	}
+
+	words := AlignWordsToScript(script, []WordTimestamp{
+		{Word: "she", Start: 0.0, End: 0.2},
+		{Word: "turned", Start: 0.2, End: 0.5},
+		{Word: "around", Start: 0.5, End: 0.9},
+		{Word: "whos", Start: 1.2, End: 1.4},
+		{Word: "there", Start: 1.4, End: 1.7},
+	})
+	if !TagSpeakers(words, lines) {
+		t.Fatalf("TagSpeakers rejected %d aligned words", len(words))
+	}
+	if words[2].Speaker != "narrator" || words[3].Speaker != "alice" {
+		t.Errorf("unexpected speakers: %+v", words)
+	}
+
+	params := SubtitleParamsForResolution(RenderResolution{Width: 1080, Height: 1920})
+	params.Speakers = []string{"narrator", "alice"}
+	ass, err := BuildASSSubtitles(words, 0, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(ass, `{\1c`+speakerTextColors[0]+`}WHO'S`) && !strings.Contains(ass, `{\1c`+speakerTextColors[0]+`\3c`) {
+		t.Errorf("alice's words should use the first speaker color:\n%s", ass)
+	}
+	if strings.Contains(ass, "AROUND. WHO'S") {
+		t.Errorf("a subtitle chunk mixes speakers:\n%s", ass)
+	}
+
+	if TagSpeakers(words[:4], lines) {
+		t.Error("TagSpeakers should reject a word count that doesn't match the lines")
+	}
+}
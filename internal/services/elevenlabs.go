@@ -3,12 +3,17 @@ package services
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/tracing"
 )
 
 // ---------------------------------------------------------------------------
@@ -30,6 +35,22 @@ type ElevenLabsService struct {
 	voiceID  string
 	modelID  string
 	client   *http.Client
+
+	// withTimestamps switches to the /with-timestamps endpoint, which returns
+	// character-level timings alongside the audio (used for subtitle alignment).
+	withTimestamps bool
+
+	// languageVoices maps a language to the default voice used for it when
+	// the request has no voice override (ELEVENLABS_LANGUAGE_VOICES).
+	languageVoices map[string]string
+	// languageCheck applies the voice language policy, caching each voice's
+	// verified languages (GET /v1/voices/{id}).
+	languageCheck voiceLanguageCheck
+
+	// dictionaries caches pronunciation dictionaries created from lexicons,
+	// keyed by Lexicon.Hash, so each distinct lexicon is uploaded once.
+	dictionariesMu sync.Mutex
+	dictionaries   map[string]elevenLabsDictionaryLocator
 }
 
 // Ensure ElevenLabsService implements TTSService at compile time.
@@ -38,10 +59,11 @@ var _ TTSService = (*ElevenLabsService)(nil)
 // NewElevenLabsService creates a new ElevenLabs TTS service with defaults.
 func NewElevenLabsService(apiKey string) *ElevenLabsService {
 	return &ElevenLabsService{
-		apiKey:  apiKey,
-		voiceID: elevenLabsDefaultVoice,
-		modelID: elevenLabsDefaultModel,
-		client:  &http.Client{Timeout: 90 * time.Second},
+		apiKey:        apiKey,
+		voiceID:       elevenLabsDefaultVoice,
+		modelID:       elevenLabsDefaultModel,
+		client:        &http.Client{Timeout: 90 * time.Second, Transport: tracing.Transport(nil)},
+		languageCheck: voiceLanguageCheck{provider: "ElevenLabs"},
 	}
 }
 
@@ -51,11 +73,51 @@ func NewElevenLabsServiceWithVoice(apiKey, voiceID string) *ElevenLabsService {
 		voiceID = elevenLabsDefaultVoice
 	}
 	return &ElevenLabsService{
-		apiKey:  apiKey,
-		voiceID: voiceID,
-		modelID: elevenLabsDefaultModel,
-		client:  &http.Client{Timeout: 90 * time.Second},
+		apiKey:        apiKey,
+		voiceID:       voiceID,
+		modelID:       elevenLabsDefaultModel,
+		client:        &http.Client{Timeout: 90 * time.Second, Transport: tracing.Transport(nil)},
+		languageCheck: voiceLanguageCheck{provider: "ElevenLabs"},
+	}
+}
+
+// WithTimestamps enables the with-timestamps endpoint so GenerateSpeech returns
+// word timings derived from ElevenLabs' character alignment. Subtitles then use
+// these timings instead of a Whisper transcription.
+func (s *ElevenLabsService) WithTimestamps(enabled bool) *ElevenLabsService {
+	s.withTimestamps = enabled
+	return s
+}
+
+// WithModel overrides the TTS model (ELEVENLABS_MODEL_ID). Models with audio
+// tag support (eleven_v3) get the style instruction as a leading tag such as
+// [excited] or [whispers].
+func (s *ElevenLabsService) WithModel(modelID string) *ElevenLabsService {
+	if modelID != "" {
+		s.modelID = modelID
 	}
+	return s
+}
+
+// ModelName returns the TTS model ID. Implements TTSModelNamer.
+func (s *ElevenLabsService) ModelName() string {
+	return s.modelID
+}
+
+// WithLanguageVoices sets per-language default voices, keyed by ISO 639-1 code.
+// They apply when a request has no voice override. eleven_flash_v2_5 is
+// multilingual, so any voice can speak any supported language — this map only
+// picks a voice with a native accent.
+func (s *ElevenLabsService) WithLanguageVoices(voices map[string]string) *ElevenLabsService {
+	s.languageVoices = voices
+	return s
+}
+
+// WithVoiceLanguagePolicy sets how a voice that doesn't speak the requested
+// language is handled (default: VoiceLanguageWarn).
+func (s *ElevenLabsService) WithVoiceLanguagePolicy(policy VoiceLanguagePolicy) *ElevenLabsService {
+	s.languageCheck.policy = policy
+	return s
 }
 
 // ---------------------------------------------------------------------------
@@ -67,6 +129,25 @@ type elevenLabsRequest struct {
 	ModelID       string                `json:"model_id"`
 	VoiceSettings *elevenLabsVoiceSettings `json:"voice_settings,omitempty"`
 	Speed         *float64              `json:"speed,omitempty"`
+	LanguageCode  string                `json:"language_code,omitempty"` // ISO 639-1, enforces the language (models that accept it only)
+
+	PronunciationDictionaries []elevenLabsDictionaryLocator `json:"pronunciation_dictionary_locators,omitempty"`
+}
+
+// elevenLabsDictionaryLocator references a version of a pronunciation dictionary.
+type elevenLabsDictionaryLocator struct {
+	DictionaryID string `json:"pronunciation_dictionary_id"`
+	VersionID    string `json:"version_id"`
+}
+
+// elevenLabsDictionaryRule is one rule of a pronunciation dictionary:
+// type "alias" (respelling) or "phoneme" (IPA).
+type elevenLabsDictionaryRule struct {
+	StringToReplace string `json:"string_to_replace"`
+	Type            string `json:"type"`
+	Alias           string `json:"alias,omitempty"`
+	Phoneme         string `json:"phoneme,omitempty"`
+	Alphabet        string `json:"alphabet,omitempty"`
 }
 
 type elevenLabsVoiceSettings struct {
@@ -76,28 +157,100 @@ type elevenLabsVoiceSettings struct {
 	UseSpeakerBoost bool    `json:"use_speaker_boost,omitempty"`
 }
 
+// elevenLabsTimestampsResponse is the body returned by the with-timestamps endpoint.
+type elevenLabsTimestampsResponse struct {
+	AudioBase64 string               `json:"audio_base64"`
+	Alignment   *elevenLabsAlignment `json:"alignment"`
+}
+
+// elevenLabsAlignment holds per-character timings for the input text.
+type elevenLabsAlignment struct {
+	Characters                 []string  `json:"characters"`
+	CharacterStartTimesSeconds []float64 `json:"character_start_times_seconds"`
+	CharacterEndTimesSeconds   []float64 `json:"character_end_times_seconds"`
+}
+
+// trimPrefix drops the timings of prefix, when the aligned characters
+// start with it.
+func (a *elevenLabsAlignment) trimPrefix(prefix string) {
+	n := 0
+	for _, r := range prefix {
+		if n >= len(a.Characters) || a.Characters[n] != string(r) {
+			return
+		}
+		n++
+	}
+	if n == 0 || len(a.CharacterStartTimesSeconds) < n || len(a.CharacterEndTimesSeconds) < n {
+		return
+	}
+	a.Characters = a.Characters[n:]
+	a.CharacterStartTimesSeconds = a.CharacterStartTimesSeconds[n:]
+	a.CharacterEndTimesSeconds = a.CharacterEndTimesSeconds[n:]
+}
+
 // GenerateSpeech converts text to speech using ElevenLabs.
 // Implements the TTSService interface.
-// voiceID overrides the service-level default when non-empty.
-func (s *ElevenLabsService) GenerateSpeech(ctx context.Context, text, voiceStyle, voiceID string) (*TTSResponse, error) {
-	// Use per-request voice override if provided, otherwise fall back to service default
+// req.VoiceID overrides the service-level default when non-empty.
+// req.Language picks the language's default voice, is checked against the
+// voice's languages and, on models that accept it, is sent as language_code.
+// Script markup is rendered as ElevenLabs break tags and the lexicon is sent as
+// a pronunciation dictionary (see elevenLabsMarkup).
+func (s *ElevenLabsService) GenerateSpeech(ctx context.Context, speech SpeechRequest) (*TTSResponse, error) {
+	lang := normalizeTTSLanguage(speech.Language)
+
+	// Use per-request voice override if provided, otherwise the language's
+	// default voice, otherwise the service default
 	effectiveVoice := s.voiceID
-	if voiceID != "" {
-		effectiveVoice = voiceID
+	if languageVoice, ok := s.languageVoices[lang]; ok {
+		effectiveVoice = languageVoice
+	}
+	if speech.VoiceID != "" {
+		effectiveVoice = speech.VoiceID
+	}
+
+	if err := s.languageCheck.check(ctx, effectiveVoice, lang, s.voiceLanguages); err != nil {
+		return nil, err
+	}
+
+	// Pronunciations go in a native dictionary; if it can't be created, the
+	// aliases are substituted into the text instead
+	var dictionaries []elevenLabsDictionaryLocator
+	audioTags := elevenLabsSupportsAudioTags(s.modelID)
+	markup := elevenLabsMarkup{lexicon: speech.Lexicon, audioTags: audioTags}
+	if speech.Lexicon.Matches(PlainScript(speech.Text)) {
+		locator, err := s.pronunciationDictionary(ctx, speech.Lexicon)
+		if err != nil {
+			joblog.Printf(ctx, "[ElevenLabs] Could not create pronunciation dictionary, substituting aliases inline: %v", err)
+		} else {
+			dictionaries = []elevenLabsDictionaryLocator{locator}
+			markup.lexicon = nil
+		}
+	}
+	text := renderScriptMarkup(speech.Text, markup)
+
+	// Voice settings: service defaults, overridden by the project's base
+	// settings, then adjusted for this clip's style instruction
+	delivery := ParseVoiceStyle(speech.VoiceStyle)
+	delivery.Pace *= speech.paceFactor()
+	tagPrefix := ""
+	if audioTags {
+		if tag := elevenLabsAudioTag(delivery); tag != "" {
+			tagPrefix = tag + " "
+			text = tagPrefix + text
+		}
 	}
+	settings, speed := elevenLabsSettingsFor(speech.Settings, delivery)
 
 	// Build request body
-	speed := 0.85 // Slightly slower for clear narration delivery
 	reqBody := elevenLabsRequest{
-		Text:    text,
-		ModelID: s.modelID,
-		Speed:   &speed,
-		VoiceSettings: &elevenLabsVoiceSettings{
-			Stability:       0.60, // Moderate stability — allows some emotional range
-			SimilarityBoost: 0.80, // High voice consistency
-			Style:           0.35, // Mild style exaggeration for natural delivery
-			UseSpeakerBoost: true,
-		},
+		Text:          text,
+		ModelID:       s.modelID,
+		Speed:         &speed,
+		VoiceSettings: settings,
+		PronunciationDictionaries: dictionaries,
+	}
+	if elevenLabsSupportsLanguageCode(s.modelID) {
+		reqBody.LanguageCode = lang
 	}
 
 	jsonData, err := json.Marshal(reqBody)
@@ -105,9 +258,13 @@ func (s *ElevenLabsService) GenerateSpeech(ctx context.Context, text, voiceStyle
 		return nil, fmt.Errorf("failed to marshal ElevenLabs request: %w", err)
 	}
 
-	// Build URL: POST /v1/text-to-speech/{voice_id}?output_format=mp3_44100_128
+	// Build URL: POST /v1/text-to-speech/{voice_id}[/with-timestamps]?output_format=mp3_44100_128
+	endpoint := effectiveVoice
+	if s.withTimestamps {
+		endpoint += "/with-timestamps"
+	}
 	url := fmt.Sprintf("%s/v1/text-to-speech/%s?output_format=%s",
-		elevenLabsBaseURL, effectiveVoice, elevenLabsOutputFormat)
+		elevenLabsBaseURL, endpoint, elevenLabsOutputFormat)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
 	if err != nil {
@@ -117,8 +274,8 @@ func (s *ElevenLabsService) GenerateSpeech(ctx context.Context, text, voiceStyle
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("xi-api-key", s.apiKey)
 
-	log.Printf("[ElevenLabs] Generating speech (voiceID=%s, model=%s, textLen=%d, speed=%.2f)",
-		effectiveVoice, s.modelID, len(text), speed)
+	joblog.Printf(ctx, "[ElevenLabs] Generating speech (voiceID=%s, model=%s, lang=%s, textLen=%d, speed=%.2f, stability=%.2f, style=%.2f)",
+		effectiveVoice, s.modelID, lang, len(text), speed, settings.Stability, settings.Style)
 
 	resp, err := s.client.Do(req)
 	if err != nil {
@@ -128,27 +285,309 @@ func (s *ElevenLabsService) GenerateSpeech(ctx context.Context, text, voiceStyle
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ElevenLabs returned status %d: %s", resp.StatusCode, string(body))
+		return nil, &TTSStatusError{Provider: TTSProviderElevenLabs, StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
-	// Read audio data — the response body IS the audio file
-	audioData, err := io.ReadAll(resp.Body)
+	// Read audio data — the response body IS the audio file, unless timestamps
+	// were requested, in which case it's JSON with base64 audio + alignment
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read ElevenLabs audio response: %w", err)
 	}
 
+	audioData := body
+	var wordTimestamps []WordTimestamp
+	if s.withTimestamps {
+		var tsResp elevenLabsTimestampsResponse
+		if err := json.Unmarshal(body, &tsResp); err != nil {
+			return nil, fmt.Errorf("failed to parse ElevenLabs timestamps response: %w", err)
+		}
+		audioData, err = base64.StdEncoding.DecodeString(tsResp.AudioBase64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode ElevenLabs audio: %w", err)
+		}
+		if tsResp.Alignment != nil {
+			// The audio tag isn't spoken and must not become a subtitle word
+			tsResp.Alignment.trimPrefix(tagPrefix)
+			wordTimestamps = WordsFromCharacterTimings(
+				tsResp.Alignment.Characters,
+				tsResp.Alignment.CharacterStartTimesSeconds,
+				tsResp.Alignment.CharacterEndTimesSeconds,
+			)
+		}
+	}
+
 	if len(audioData) == 0 {
 		return nil, fmt.Errorf("ElevenLabs returned empty audio")
 	}
 
-	// Estimate duration (ElevenLabs doesn't return duration in the response headers for this endpoint)
-	durationMs := estimateAudioDuration(text, speed)
+	// Measure duration from the MP3 frames (ElevenLabs doesn't return duration for this endpoint)
+	ttsResp := newTTSResponse(ctx, audioData, "mp3", text, speed)
+	ttsResp.WordTimestamps = wordTimestamps
+
+	joblog.Event(ctx, fmt.Sprintf("[ElevenLabs] Speech generated (%d bytes, %dms, %dHz/%dch, %d aligned words)",
+		len(audioData), ttsResp.DurationMs, ttsResp.SampleRate, ttsResp.Channels, len(wordTimestamps)),
+		map[string]interface{}{"provider": TTSProviderElevenLabs, "request_id": resp.Header.Get("request-id")})
+
+	return ttsResp, nil
+}
+
+// pronunciationDictionary returns a dictionary locator for the lexicon,
+// creating the dictionary on first use (POST /v1/pronunciation-dictionaries/add-from-rules).
+// Entries with an alias become alias rules; IPA-only entries become phoneme
+// rules, which ElevenLabs honors on its English models only.
+func (s *ElevenLabsService) pronunciationDictionary(ctx context.Context, lexicon Lexicon) (elevenLabsDictionaryLocator, error) {
+	key := lexicon.Hash()
+	s.dictionariesMu.Lock()
+	locator, ok := s.dictionaries[key]
+	s.dictionariesMu.Unlock()
+	if ok {
+		return locator, nil
+	}
+
+	rules := make([]elevenLabsDictionaryRule, 0, len(lexicon))
+	for _, entry := range lexicon {
+		rule := elevenLabsDictionaryRule{StringToReplace: entry.Term}
+		if entry.Alias != "" {
+			rule.Type = "alias"
+			rule.Alias = entry.Alias
+		} else {
+			rule.Type = "phoneme"
+			rule.Phoneme = entry.IPA
+			rule.Alphabet = "ipa"
+		}
+		rules = append(rules, rule)
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"name":  "episod-" + key[:12],
+		"rules": rules,
+	})
+	if err != nil {
+		return locator, fmt.Errorf("failed to marshal pronunciation dictionary: %w", err)
+	}
 
-	log.Printf("[ElevenLabs] Speech generated (%d bytes, estimated %dms)", len(audioData), durationMs)
+	req, err := http.NewRequestWithContext(ctx, "POST", elevenLabsBaseURL+"/v1/pronunciation-dictionaries/add-from-rules", bytes.NewReader(jsonData))
+	if err != nil {
+		return locator, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("xi-api-key", s.apiKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return locator, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return locator, &TTSStatusError{Provider: TTSProviderElevenLabs, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var created struct {
+		ID        string `json:"id"`
+		VersionID string `json:"version_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return locator, fmt.Errorf("failed to parse pronunciation dictionary: %w", err)
+	}
+	locator = elevenLabsDictionaryLocator{DictionaryID: created.ID, VersionID: created.VersionID}
 
-	return &TTSResponse{
-		AudioData:  audioData,
-		DurationMs: durationMs,
-		Format:     "mp3",
-	}, nil
+	s.dictionariesMu.Lock()
+	if s.dictionaries == nil {
+		s.dictionaries = make(map[string]elevenLabsDictionaryLocator)
+	}
+	s.dictionaries[key] = locator
+	s.dictionariesMu.Unlock()
+
+	joblog.Printf(ctx, "[ElevenLabs] Created pronunciation dictionary %s (%d rules)", created.ID, len(rules))
+	return locator, nil
+}
+
+// elevenLabsSettingsFor computes voice settings and speed for one request.
+// Defaults suit narration (moderate stability, high similarity, mild style,
+// slightly slow); base overrides them; the delivery's intensity trades
+// stability for style and its pace scales the speed.
+func elevenLabsSettingsFor(base *VoiceSettings, delivery VoiceDelivery) (*elevenLabsVoiceSettings, float64) {
+	if base == nil {
+		base = &VoiceSettings{}
+	}
+	settings := &elevenLabsVoiceSettings{
+		Stability:       clampFloat(valueOr(base.Stability, 0.60)-0.2*delivery.Intensity, 0, 1),
+		SimilarityBoost: clampFloat(valueOr(base.SimilarityBoost, 0.80), 0, 1),
+		Style:           clampFloat(valueOr(base.Style, 0.35)+0.25*delivery.Intensity, 0, 1),
+		UseSpeakerBoost: true,
+	}
+	speed := clampFloat(valueOr(base.Speed, 0.85)*delivery.Pace, 0.7, 1.2)
+	return settings, speed
+}
+
+// elevenLabsSupportsAudioTags reports whether a model understands inline
+// audio tags like [excited] (Eleven v3 and later).
+func elevenLabsSupportsAudioTags(modelID string) bool {
+	return strings.HasPrefix(modelID, "eleven_v3")
+}
+
+// elevenLabsSupportsLanguageCode reports whether a model accepts
+// language_code; the others reject requests that set it and detect the
+// language from the text.
+func elevenLabsSupportsLanguageCode(modelID string) bool {
+	return strings.HasPrefix(modelID, "eleven_flash_v2_5") || strings.HasPrefix(modelID, "eleven_turbo_v2_5")
+}
+
+// elevenLabsAudioTag returns the audio tag for a delivery, or "" for a neutral one.
+func elevenLabsAudioTag(delivery VoiceDelivery) string {
+	switch {
+	case delivery.Whisper:
+		return "[whispers]"
+	case delivery.Emotion != "" && delivery.Emotion != "calm" && delivery.Emotion != "enthusiastic":
+		return "[" + delivery.Emotion + "]"
+	}
+	return ""
+}
+
+// elevenLabsMaxBreak is the longest pause ElevenLabs accepts in a break tag.
+const elevenLabsMaxBreak = 3 * time.Second
+
+// elevenLabsMarkup renders script markup for ElevenLabs: pauses become
+// <break time="1.5s" /> tags (capped at 3s) and spelled words are spaced out
+// letter by letter. The models have no emphasis control, so emphasized words
+// are spoken plainly. Audio-tag models (eleven_v3) don't read break tags and
+// get [short pause] / [long pause] instead. lexicon is set only when no
+// pronunciation dictionary could be used, in which case aliases are
+// substituted inline.
+type elevenLabsMarkup struct {
+	lexicon   Lexicon
+	audioTags bool
+}
+
+func (m elevenLabsMarkup) text(s string) string { return m.lexicon.ApplyAliases(s) }
+
+func (m elevenLabsMarkup) pause(d time.Duration) string {
+	if m.audioTags {
+		if d < time.Second {
+			return " [short pause] "
+		}
+		return " [long pause] "
+	}
+	if d > elevenLabsMaxBreak {
+		d = elevenLabsMaxBreak
+	}
+	return fmt.Sprintf(` <break time="%s" /> `, formatBreakSeconds(d))
+}
+
+func (m elevenLabsMarkup) emphasis(s string) string { return m.text(s) }
+
+func (m elevenLabsMarkup) spell(s string) string { return spelledLetters(s) }
+
+// elevenLabsVoice is one entry of GET /v1/voices.
+type elevenLabsVoice struct {
+	VoiceID     string            `json:"voice_id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	PreviewURL  string            `json:"preview_url"`
+	Labels      map[string]string `json:"labels"`
+
+	VerifiedLanguages []struct {
+		Language string `json:"language"`
+		Accent   string `json:"accent"`
+	} `json:"verified_languages"`
+}
+
+// voiceLanguages looks up the languages a voice is verified in
+// (GET /v1/voices/{id}), or its language label; none if unknown.
+func (s *ElevenLabsService) voiceLanguages(ctx context.Context, voiceID string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", elevenLabsBaseURL+"/v1/voices/"+voiceID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("xi-api-key", s.apiKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &TTSStatusError{Provider: TTSProviderElevenLabs, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var voice elevenLabsVoice
+	if err := json.NewDecoder(resp.Body).Decode(&voice); err != nil {
+		return nil, fmt.Errorf("failed to parse voice: %w", err)
+	}
+	return voice.languages(), nil
+}
+
+// languages lists the voice's verified languages, or its language label.
+func (v elevenLabsVoice) languages() []string {
+	var languages []string
+	for _, l := range v.VerifiedLanguages {
+		languages = append(languages, normalizeTTSLanguage(l.Language))
+	}
+	if len(languages) == 0 && v.Labels["language"] != "" {
+		languages = append(languages, normalizeTTSLanguage(v.Labels["language"]))
+	}
+	return languages
+}
+
+// ListVoices returns the account's voices (GET /v1/voices).
+// Implements the VoiceLister interface.
+func (s *ElevenLabsService) ListVoices(ctx context.Context) ([]Voice, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", elevenLabsBaseURL+"/v1/voices", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("xi-api-key", s.apiKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &TTSStatusError{Provider: TTSProviderElevenLabs, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var result struct {
+		Voices []elevenLabsVoice `json:"voices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse voices: %w", err)
+	}
+
+	voices := make([]Voice, 0, len(result.Voices))
+	for _, v := range result.Voices {
+		voice := Voice{
+			ID:          v.VoiceID,
+			Name:        v.Name,
+			Description: v.Description,
+			Gender:      normalizeVoiceGender(v.Labels["gender"]),
+			Accent:      v.Labels["accent"],
+			PreviewURL:  v.PreviewURL,
+		}
+		if voice.Description == "" {
+			voice.Description = v.Labels["description"]
+		}
+		for _, l := range v.VerifiedLanguages {
+			lang := normalizeTTSLanguage(l.Language)
+			if voice.Language == "" {
+				voice.Language = lang
+				if voice.Accent == "" {
+					voice.Accent = l.Accent
+				}
+			}
+			voice.Languages = append(voice.Languages, lang)
+		}
+		if voice.Language == "" {
+			voice.Language = normalizeTTSLanguage(v.Labels["language"])
+		}
+		voices = append(voices, voice)
+	}
+	return voices, nil
 }
@@ -9,6 +9,11 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/metrics"
+	"github.com/bobarin/episod/internal/tracing"
 )
 
 // ---------------------------------------------------------------------------
@@ -122,9 +127,9 @@ func (s *FFmpegService) PrependSilence(ctx context.Context, inputAudioPath, outp
 
 	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = joblog.Stderr(ctx, "ffmpeg")
 
-	if err := cmd.Run(); err != nil {
+	if err := runFFmpeg(ctx, cmd, "prepend_silence"); err != nil {
 		return fmt.Errorf("ffmpeg prepend silence failed: %w", err)
 	}
 
@@ -144,10 +149,10 @@ func (s *FFmpegService) RenderClipWithEffect(ctx context.Context, imagePath, aud
 		// Escape colons and backslashes in the path for FFmpeg filter syntax
 		escapedPath := escapeFFmpegFilterPath(subtitlePath)
 		vf += fmt.Sprintf(",ass='%s'", escapedPath)
-		log.Printf("[FFmpeg] Burning in subtitles from %s", subtitlePath)
+		joblog.Printf(ctx, "[FFmpeg] Burning in subtitles from %s", subtitlePath)
 	}
 
-	log.Printf("[FFmpeg] Rendering with effect=%s, duration=%dms, filter=%s", effect, durationMs, vf)
+	joblog.Printf(ctx, "[FFmpeg] Rendering with effect=%s, duration=%dms, filter=%s", effect, durationMs, vf)
 
 	args := []string{
 		"-i", imagePath,  // Single image input (zoompan handles duration)
@@ -165,15 +170,27 @@ func (s *FFmpegService) RenderClipWithEffect(ctx context.Context, imagePath, aud
 
 	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = joblog.Stderr(ctx, "ffmpeg")
 
-	if err := cmd.Run(); err != nil {
+	if err := runFFmpeg(ctx, cmd, "render_clip_effect"); err != nil {
 		return fmt.Errorf("ffmpeg render clip failed (effect=%s): %w", effect, err)
 	}
 
 	return nil
 }
 
+// runFFmpeg runs an FFmpeg command in a span, recording its duration in the
+// metrics under operation.
+func runFFmpeg(ctx context.Context, cmd *exec.Cmd, operation string) error {
+	_, span := tracing.Start(ctx, "ffmpeg "+operation)
+	start := time.Now()
+	err := cmd.Run()
+	joblog.Flush(cmd.Stderr)
+	metrics.ObserveFFmpeg(operation, time.Since(start), err)
+	tracing.End(span, err)
+	return err
+}
+
 // escapeFFmpegFilterPath escapes special characters in file paths for FFmpeg filter syntax.
 // FFmpeg filter strings treat colons, backslashes, and single quotes specially.
 func escapeFFmpegFilterPath(path string) string {
@@ -312,17 +329,17 @@ func (s *FFmpegService) buildMotionFilter(effect ClipEffect, durationMs int) str
 func (s *FFmpegService) MixBackgroundMusic(ctx context.Context, videoPath, musicPath, outputPath string) error {
 	// Skip if no music path provided
 	if musicPath == "" {
-		log.Printf("[FFmpeg] No background music path provided, skipping")
+		joblog.Printf(ctx, "[FFmpeg] No background music path provided, skipping")
 		return nil
 	}
 
 	// Check if music file exists
 	if _, err := os.Stat(musicPath); os.IsNotExist(err) {
-		log.Printf("[FFmpeg] Background music file not found at %s, skipping", musicPath)
+		joblog.Printf(ctx, "[FFmpeg] Background music file not found at %s, skipping", musicPath)
 		return nil
 	}
 
-	log.Printf("[FFmpeg] Mixing background music from %s", musicPath)
+	joblog.Printf(ctx, "[FFmpeg] Mixing background music from %s", musicPath)
 
 	// Filter complex explanation:
 	// [0:a] = narration audio from the video (keep at full volume)
@@ -348,9 +365,9 @@ func (s *FFmpegService) MixBackgroundMusic(ctx context.Context, videoPath, music
 
 	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = joblog.Stderr(ctx, "ffmpeg")
 
-	if err := cmd.Run(); err != nil {
+	if err := runFFmpeg(ctx, cmd, "mix_music"); err != nil {
 		return fmt.Errorf("ffmpeg mix background music failed: %w", err)
 	}
 
@@ -364,7 +381,7 @@ func (s *FFmpegService) MixBackgroundMusic(ctx context.Context, videoPath, music
 // If the video is longer than the audio, -shortest trims it to match.
 // If subtitlePath is non-empty, TikTok-style ASS subtitles are burned into the video.
 func (s *FFmpegService) RenderClipFromVideo(ctx context.Context, videoPath, audioPath, outputPath string, subtitlePath string) error {
-	log.Printf("[FFmpeg] Combining AI video with narration audio")
+	joblog.Printf(ctx, "[FFmpeg] Combining AI video with narration audio")
 
 	// Build filter_complex: video path + audio loudness normalization
 	// Video: tpad (frame freeze) → scale to target resolution → optional subtitles
@@ -374,7 +391,7 @@ func (s *FFmpegService) RenderClipFromVideo(ctx context.Context, videoPath, audi
 	if subtitlePath != "" {
 		escapedPath := escapeFFmpegFilterPath(subtitlePath)
 		filterExpr += fmt.Sprintf(",ass='%s'", escapedPath)
-		log.Printf("[FFmpeg] Burning in subtitles from %s", subtitlePath)
+		joblog.Printf(ctx, "[FFmpeg] Burning in subtitles from %s", subtitlePath)
 	}
 	filterExpr += "[v];[1:a]loudnorm=I=-16:TP=-1.5:LRA=11[a]"
 
@@ -395,15 +412,46 @@ func (s *FFmpegService) RenderClipFromVideo(ctx context.Context, videoPath, audi
 
 	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = joblog.Stderr(ctx, "ffmpeg")
 
-	if err := cmd.Run(); err != nil {
+	if err := runFFmpeg(ctx, cmd, "render_clip_video"); err != nil {
 		return fmt.Errorf("ffmpeg render clip from video failed: %w", err)
 	}
 
 	return nil
 }
 
+// RenderStillClip renders imagePath as a silent clip of durationMs, encoded
+// like the narrated clips so it can be concatenated with them.
+func (s *FFmpegService) RenderStillClip(ctx context.Context, imagePath string, durationMs int, outputPath string) error {
+	duration := fmt.Sprintf("%.3f", float64(durationMs)/1000)
+	args := []string{
+		"-loop", "1",
+		"-i", imagePath,
+		"-f", "lavfi",
+		"-i", "anullsrc=channel_layout=stereo:sample_rate=44100",
+		"-vf", fmt.Sprintf("scale=%d:%d,fps=%d", s.Resolution.Width, s.Resolution.Height, videoFPS),
+		"-af", "loudnorm=I=-16:TP=-1.5:LRA=11", // Same audio chain as the narrated clips
+		"-c:v", "libx264",
+		"-c:a", "aac",
+		"-b:a", "192k",
+		"-pix_fmt", "yuv420p",
+		"-t", duration,
+		"-y",
+		outputPath,
+	}
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = joblog.Stderr(ctx, "ffmpeg")
+
+	if err := runFFmpeg(ctx, cmd, "render_still_clip"); err != nil {
+		return fmt.Errorf("ffmpeg render still clip failed: %w", err)
+	}
+
+	return nil
+}
+
 // ConcatenateClips combines multiple video clips into one final video
 func (s *FFmpegService) ConcatenateClips(ctx context.Context, clipPaths []string, outputPath string) error {
 	if len(clipPaths) == 0 {
@@ -436,15 +484,60 @@ func (s *FFmpegService) ConcatenateClips(ctx context.Context, clipPaths []string
 
 	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = joblog.Stderr(ctx, "ffmpeg")
 
-	if err := cmd.Run(); err != nil {
+	if err := runFFmpeg(ctx, cmd, "concat_clips"); err != nil {
 		return fmt.Errorf("ffmpeg concatenate failed: %w", err)
 	}
 
 	return nil
 }
 
+// ConcatenateAudioWithGaps joins narration segments (one per dialogue line) into
+// a single MP3, inserting gapMs of silence before every segment after the first.
+// Segments may come from different voices with different sample rates or channel
+// layouts, so each one is resampled to 44.1 kHz stereo before the concat filter.
+func (s *FFmpegService) ConcatenateAudioWithGaps(ctx context.Context, audioPaths []string, outputPath string, gapMs int) error {
+	if len(audioPaths) == 0 {
+		return fmt.Errorf("no audio segments to concatenate")
+	}
+
+	// Filter complex: [i:a] → normalize format → delay by gapMs (except the first) → concat
+	var args []string
+	var filter strings.Builder
+	for i, path := range audioPaths {
+		args = append(args, "-i", path)
+		fmt.Fprintf(&filter, "[%d:a]aformat=sample_rates=44100:channel_layouts=stereo", i)
+		if i > 0 && gapMs > 0 {
+			fmt.Fprintf(&filter, ",adelay=%d|%d", gapMs, gapMs)
+		}
+		fmt.Fprintf(&filter, "[a%d];", i)
+	}
+	for i := range audioPaths {
+		fmt.Fprintf(&filter, "[a%d]", i)
+	}
+	fmt.Fprintf(&filter, "concat=n=%d:v=0:a=1[aout]", len(audioPaths))
+
+	args = append(args,
+		"-filter_complex", filter.String(),
+		"-map", "[aout]",
+		"-c:a", "libmp3lame",
+		"-b:a", "192k",
+		"-y",
+		outputPath,
+	)
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = joblog.Stderr(ctx, "ffmpeg")
+
+	if err := runFFmpeg(ctx, cmd, "concat_audio"); err != nil {
+		return fmt.Errorf("ffmpeg concatenate audio failed: %w", err)
+	}
+
+	return nil
+}
+
 // GetAudioDuration returns the duration of an audio file in milliseconds
 func (s *FFmpegService) GetAudioDuration(ctx context.Context, audioPath string) (int, error) {
 	// Use ffprobe to get duration
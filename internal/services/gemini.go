@@ -8,9 +8,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/metrics"
 	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/tracing"
 )
 
 const geminiModel = "gemini-3-pro-image-preview"
@@ -23,10 +27,15 @@ type GeminiService struct {
 func NewGeminiService(apiKey string) *GeminiService {
 	return &GeminiService{
 		apiKey: apiKey,
-		client: &http.Client{Timeout: 300 * time.Second},
+		client: &http.Client{Timeout: 300 * time.Second, Transport: tracing.Transport(nil)},
 	}
 }
 
+// Model returns the image model name (part of the provider cache key).
+func (s *GeminiService) Model() string {
+	return geminiModel
+}
+
 // Gemini API request/response structures
 type GeminiGenerateContentRequest struct {
 	Contents         []GeminiContent         `json:"contents"`
@@ -59,11 +68,28 @@ type GeminiInlineData struct {
 }
 
 type GeminiGenerateContentResponse struct {
-	Candidates []GeminiCandidate `json:"candidates"`
+	Candidates     []GeminiCandidate     `json:"candidates"`
+	PromptFeedback *GeminiPromptFeedback `json:"promptFeedback,omitempty"` // Set when the prompt itself was blocked
+	ResponseID     string                `json:"responseId,omitempty"`
+}
+
+type GeminiPromptFeedback struct {
+	BlockReason        string               `json:"blockReason,omitempty"` // "SAFETY", "PROHIBITED_CONTENT", ...
+	BlockReasonMessage string               `json:"blockReasonMessage,omitempty"`
+	SafetyRatings      []GeminiSafetyRating `json:"safetyRatings,omitempty"`
 }
 
 type GeminiCandidate struct {
-	Content GeminiResponseContent `json:"content"`
+	Content       GeminiResponseContent `json:"content"`
+	FinishReason  string                `json:"finishReason,omitempty"` // "STOP", "IMAGE_SAFETY", "NO_IMAGE", ...
+	FinishMessage string                `json:"finishMessage,omitempty"`
+	SafetyRatings []GeminiSafetyRating  `json:"safetyRatings,omitempty"`
+}
+
+type GeminiSafetyRating struct {
+	Category    string `json:"category"`    // e.g. "HARM_CATEGORY_DANGEROUS_CONTENT"
+	Probability string `json:"probability"` // "NEGLIGIBLE", "LOW", "MEDIUM", "HIGH"
+	Blocked     bool   `json:"blocked,omitempty"`
 }
 
 type GeminiResponseContent struct {
@@ -75,6 +101,96 @@ type GeminiResponsePart struct {
 	InlineData *GeminiInlineData `json:"inlineData,omitempty"`
 }
 
+// ImageRefusalError is returned when Gemini declines to draw the image: the
+// prompt or the output was blocked, or the model answered in text only.
+// Rewording the prompt can help; retrying it as is won't.
+type ImageRefusalError struct {
+	Reason     string   // Block or finish reason, or "TEXT_ONLY"
+	Message    string   // Gemini's explanation or the model's text, if any
+	Categories []string // Safety categories that were blocked or rated medium/high
+}
+
+func (e *ImageRefusalError) Error() string {
+	msg := "gemini refused the image (" + e.Reason
+	if len(e.Categories) > 0 {
+		msg += ": " + strings.Join(e.Categories, ", ")
+	}
+	msg += ")"
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// refusalFinishReasons are candidate finish reasons meaning the model
+// declined to produce the image (rather than failing).
+var refusalFinishReasons = map[string]bool{
+	"SAFETY":                   true,
+	"RECITATION":               true,
+	"BLOCKLIST":                true,
+	"PROHIBITED_CONTENT":       true,
+	"SPII":                     true,
+	"IMAGE_SAFETY":             true,
+	"IMAGE_PROHIBITED_CONTENT": true,
+	"IMAGE_RECITATION":         true,
+	"IMAGE_OTHER":              true,
+	"NO_IMAGE":                 true,
+}
+
+// imageRefusal returns the refusal in a response without an image, or nil
+// when nothing points to one.
+func imageRefusal(resp *GeminiGenerateContentResponse) *ImageRefusalError {
+	if fb := resp.PromptFeedback; fb != nil && fb.BlockReason != "" {
+		return &ImageRefusalError{Reason: fb.BlockReason, Message: fb.BlockReasonMessage, Categories: flaggedCategories(fb.SafetyRatings)}
+	}
+	if len(resp.Candidates) == 0 {
+		return nil
+	}
+
+	candidate := resp.Candidates[0]
+	var text string
+	for _, part := range candidate.Content.Parts {
+		if part.Text != "" {
+			text = part.Text
+			break
+		}
+	}
+	text = truncateRunes(text, 200)
+
+	if refusalFinishReasons[candidate.FinishReason] {
+		message := candidate.FinishMessage
+		if message == "" {
+			message = text
+		}
+		return &ImageRefusalError{Reason: candidate.FinishReason, Message: message, Categories: flaggedCategories(candidate.SafetyRatings)}
+	}
+	if text != "" {
+		// Finished normally but only explained why it won't draw the scene
+		return &ImageRefusalError{Reason: "TEXT_ONLY", Message: text, Categories: flaggedCategories(candidate.SafetyRatings)}
+	}
+	return nil
+}
+
+// flaggedCategories lists the categories that were blocked or rated medium
+// or high.
+func flaggedCategories(ratings []GeminiSafetyRating) []string {
+	var categories []string
+	for _, r := range ratings {
+		if r.Blocked || r.Probability == "MEDIUM" || r.Probability == "HIGH" {
+			categories = append(categories, r.Category)
+		}
+	}
+	return categories
+}
+
+// truncateRunes shortens s to at most n runes.
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -92,7 +208,9 @@ type ImageGenOptions struct {
 // Gemini uses its own creative interpretation plus the preset's style instructions.
 // Each call is independent — safe for parallel execution across clips.
 // In the future, presets may include their own sample reference image from the database.
-func (s *GeminiService) GenerateImage(ctx context.Context, basePrompt string, preset *models.GraphicsPreset, opts *ImageGenOptions) ([]byte, error) {
+func (s *GeminiService) GenerateImage(ctx context.Context, basePrompt string, preset *models.GraphicsPreset, opts *ImageGenOptions) (_ []byte, err error) {
+	defer metrics.ObserveProviderCall("gemini", "generate_image", time.Now(), &err)
+
 	// Resolve aspect ratio — per-project override or default
 	aspectRatio := "9:16"
 	if opts != nil && opts.AspectRatio != nil && *opts.AspectRatio != "" {
@@ -151,27 +269,30 @@ func (s *GeminiService) doGenerateContent(ctx context.Context, reqBody GeminiGen
 	if err := json.Unmarshal(bodyBytes, &geminiResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	if len(geminiResp.Candidates) == 0 {
-		return nil, fmt.Errorf("no candidates in response")
-	}
-
-	var textParts []string
-	for _, part := range geminiResp.Candidates[0].Content.Parts {
-		if part.InlineData != nil && part.InlineData.Data != "" {
-			imageData, err := base64.StdEncoding.DecodeString(part.InlineData.Data)
-			if err != nil {
-				return nil, fmt.Errorf("failed to decode base64 image: %w", err)
+	joblog.Event(ctx, "[Gemini] Response received", map[string]interface{}{
+		"provider": "gemini", "request_id": geminiResp.ResponseID, "model": geminiModel,
+	})
+
+	if len(geminiResp.Candidates) > 0 {
+		for _, part := range geminiResp.Candidates[0].Content.Parts {
+			if part.InlineData != nil && part.InlineData.Data != "" {
+				imageData, err := base64.StdEncoding.DecodeString(part.InlineData.Data)
+				if err != nil {
+					return nil, fmt.Errorf("failed to decode base64 image: %w", err)
+				}
+				return imageData, nil
 			}
-			return imageData, nil
-		}
-		if part.Text != "" {
-			textParts = append(textParts, part.Text)
 		}
 	}
 
-	if len(textParts) > 0 {
-		return nil, fmt.Errorf("gemini returned text instead of image: %s", textParts[0][:min(200, len(textParts[0]))])
+	if refusal := imageRefusal(&geminiResp); refusal != nil {
+		joblog.Event(ctx, "[Gemini] Image refused", map[string]interface{}{
+			"provider": "gemini", "request_id": geminiResp.ResponseID, "reason": refusal.Reason, "categories": refusal.Categories,
+		})
+		return nil, refusal
+	}
+	if len(geminiResp.Candidates) == 0 {
+		return nil, fmt.Errorf("no candidates in response")
 	}
 	return nil, fmt.Errorf("no image data found in response (got %d parts, none with inlineData)", len(geminiResp.Candidates[0].Content.Parts))
 }
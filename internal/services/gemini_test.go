@@ -0,0 +1,58 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestImageRefusal(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantReason string // "" = no refusal
+		wantCats   int
+	}{
+		{
+			name:       "blocked prompt",
+			body:       `{"promptFeedback":{"blockReason":"PROHIBITED_CONTENT","safetyRatings":[{"category":"HARM_CATEGORY_DANGEROUS_CONTENT","probability":"HIGH"},{"category":"HARM_CATEGORY_HARASSMENT","probability":"NEGLIGIBLE"}]}}`,
+			wantReason: "PROHIBITED_CONTENT",
+			wantCats:   1,
+		},
+		{
+			name:       "image safety finish",
+			body:       `{"candidates":[{"content":{"parts":[]},"finishReason":"IMAGE_SAFETY","safetyRatings":[{"category":"HARM_CATEGORY_SEXUALLY_EXPLICIT","probability":"LOW","blocked":true}]}]}`,
+			wantReason: "IMAGE_SAFETY",
+			wantCats:   1,
+		},
+		{
+			name:       "text only",
+			body:       `{"candidates":[{"content":{"parts":[{"text":"I can't create images of real people."}]},"finishReason":"STOP"}]}`,
+			wantReason: "TEXT_ONLY",
+		},
+		{
+			name: "empty response",
+			body: `{"candidates":[{"content":{"parts":[]},"finishReason":"STOP"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		var resp GeminiGenerateContentResponse
+		if err := json.Unmarshal([]byte(tt.body), &resp); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		refusal := imageRefusal(&resp)
+		if tt.wantReason == "" {
+			if refusal != nil {
+				t.Errorf("%s: unexpected refusal %v", tt.name, refusal)
+			}
+			continue
+		}
+		if refusal == nil {
+			t.Errorf("%s: refusal not detected", tt.name)
+			continue
+		}
+		if refusal.Reason != tt.wantReason || len(refusal.Categories) != tt.wantCats {
+			t.Errorf("%s: got reason %q categories %v, want %q with %d categories", tt.name, refusal.Reason, refusal.Categories, tt.wantReason, tt.wantCats)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ---------------------------------------------------------------------------
+// Script Markup — lightweight, provider-neutral delivery hints in Clip.Script
+//
+//   [pause]          a 500ms pause
+//   [pause 1.5s]     a pause of the given length ("300ms" also works)
+//   *some words*     emphasis
+//   [spell:NASA]     read letter by letter
+//
+// Each provider renders the markup with its own native features (ElevenLabs
+// <break> tags, Cartesia <break> and <spell> tags, ...). Subtitles and script
+// alignment use PlainScript, which drops pauses and keeps the words exactly as
+// written — so "[spell:NASA]" is still captioned "NASA".
+// ---------------------------------------------------------------------------
+
+// defaultMarkupPause is the length of a bare [pause].
+const defaultMarkupPause = 500 * time.Millisecond
+
+// SegmentKind identifies the type of a parsed script segment.
+type SegmentKind int
+
+const (
+	SegmentText     SegmentKind = iota // Plain spoken text
+	SegmentPause                       // Silence of Pause length
+	SegmentEmphasis                    // Text spoken with emphasis
+	SegmentSpell                       // Text read letter by letter
+)
+
+// ScriptSegment is one piece of a parsed script.
+type ScriptSegment struct {
+	Kind  SegmentKind
+	Text  string        // Empty for pauses
+	Pause time.Duration // SegmentPause only
+}
+
+var markupPattern = regexp.MustCompile(`\[pause(?:\s+(\d+(?:\.\d+)?)\s*(ms|s))?\]|\[spell:([^\]]+)\]|\*([^*\n]+)\*`)
+
+// ParseScriptMarkup splits a script into text, pause, emphasis and spell segments.
+// Text without markup comes back as a single SegmentText.
+func ParseScriptMarkup(script string) []ScriptSegment {
+	var segments []ScriptSegment
+	last := 0
+	for _, m := range markupPattern.FindAllStringSubmatchIndex(script, -1) {
+		if m[0] > last {
+			segments = append(segments, ScriptSegment{Kind: SegmentText, Text: script[last:m[0]]})
+		}
+		switch {
+		case m[6] >= 0:
+			segments = append(segments, ScriptSegment{Kind: SegmentSpell, Text: strings.TrimSpace(script[m[6]:m[7]])})
+		case m[8] >= 0:
+			segments = append(segments, ScriptSegment{Kind: SegmentEmphasis, Text: script[m[8]:m[9]]})
+		default:
+			pause := defaultMarkupPause
+			if m[2] >= 0 {
+				value, _ := strconv.ParseFloat(script[m[2]:m[3]], 64)
+				unit := time.Second
+				if script[m[4]:m[5]] == "ms" {
+					unit = time.Millisecond
+				}
+				pause = time.Duration(value * float64(unit))
+			}
+			segments = append(segments, ScriptSegment{Kind: SegmentPause, Pause: pause})
+		}
+		last = m[1]
+	}
+	if last < len(script) {
+		segments = append(segments, ScriptSegment{Kind: SegmentText, Text: script[last:]})
+	}
+	return segments
+}
+
+// PlainScript strips markup from a script, keeping the words as written.
+// Use it for anything shown to viewers (subtitles) or compared against
+// transcriptions (alignment).
+func PlainScript(script string) string {
+	var sb strings.Builder
+	for _, seg := range ParseScriptMarkup(script) {
+		if seg.Kind == SegmentPause {
+			sb.WriteString(" ")
+			continue
+		}
+		sb.WriteString(seg.Text)
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// HasScriptMarkup reports whether a script contains any markup.
+func HasScriptMarkup(script string) bool {
+	return markupPattern.MatchString(script)
+}
+
+// markupRenderer turns parsed segments into a provider's native text format.
+type markupRenderer interface {
+	text(s string) string
+	pause(d time.Duration) string
+	emphasis(s string) string
+	spell(s string) string
+}
+
+// renderScriptMarkup renders a script for a provider. Pronunciation lexicon
+// substitutions (if any) are applied inside text and emphasis segments by
+// the renderer.
+func renderScriptMarkup(script string, r markupRenderer) string {
+	var sb strings.Builder
+	for _, seg := range ParseScriptMarkup(script) {
+		switch seg.Kind {
+		case SegmentPause:
+			sb.WriteString(r.pause(seg.Pause))
+		case SegmentEmphasis:
+			sb.WriteString(r.emphasis(seg.Text))
+		case SegmentSpell:
+			sb.WriteString(r.spell(seg.Text))
+		default:
+			sb.WriteString(r.text(seg.Text))
+		}
+	}
+	return sb.String()
+}
+
+// spelledLetters separates the letters and digits of s with spaces ("NASA" → "N A S A"),
+// the most reliable way to make providers without a spell tag read it letter by letter.
+func spelledLetters(s string) string {
+	var letters []string
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			letters = append(letters, string(r))
+		}
+	}
+	return strings.Join(letters, " ")
+}
+
+// formatBreakSeconds formats a pause for SSML-style break tags ("0.5s").
+func formatBreakSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// formatBreakMillis formats a pause in milliseconds ("500ms").
+func formatBreakMillis(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
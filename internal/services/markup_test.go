@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScriptMarkup(t *testing.T) {
+	segments := ParseScriptMarkup("Then [pause 1.5s] it was *gone*. Ask [spell:NASA].[pause]")
+	want := []ScriptSegment{
+		{Kind: SegmentText, Text: "Then "},
+		{Kind: SegmentPause, Pause: 1500 * time.Millisecond},
+		{Kind: SegmentText, Text: " it was "},
+		{Kind: SegmentEmphasis, Text: "gone"},
+		{Kind: SegmentText, Text: ". Ask "},
+		{Kind: SegmentSpell, Text: "NASA"},
+		{Kind: SegmentText, Text: "."},
+		{Kind: SegmentPause, Pause: defaultMarkupPause},
+	}
+	if len(segments) != len(want) {
+		t.Fatalf("got %d segments, want %d: %+v", len(segments), len(want), segments)
+	}
+	for i := range want {
+		if segments[i] != want[i] {
+			t.Errorf("segment %d: got %+v, want %+v", i, segments[i], want[i])
+		}
+	}
+
+	if got := PlainScript("Then [pause 300ms] it was *gone*. Ask [spell:NASA]."); got != "Then it was gone. Ask NASA." {
+		t.Errorf("PlainScript = %q", got)
+	}
+}
+
+func TestProviderMarkupRendering(t *testing.T) {
+	lexicon := Lexicon{
+		{Term: "Keanu", Alias: "Kee-AH-noo"},
+		{Term: "Nguyen", IPA: "ˈŋwiːən"},
+	}
+	script := "Keanu and Nguyen [pause 5s] met at [spell:NASA]."
+
+	cartesia := renderScriptMarkup(script, cartesiaMarkup{lexicon: lexicon})
+	if want := `Kee-AH-noo and <<ˈŋ|w|iː|ə|n>> <break time="5000ms"/> met at <spell>NASA</spell>.`; cartesia != want {
+		t.Errorf("Cartesia:\n got %q\nwant %q", cartesia, want)
+	}
+
+	elevenLabs := renderScriptMarkup(script, elevenLabsMarkup{lexicon: lexicon})
+	if want := `Kee-AH-noo and Nguyen  <break time="3s" />  met at N A S A.`; elevenLabs != want {
+		t.Errorf("ElevenLabs:\n got %q\nwant %q", elevenLabs, want)
+	}
+}
+
+func TestLexiconMatchesWholeWords(t *testing.T) {
+	lexicon := Lexicon{{Term: "Ana", Alias: "AH-nah"}, {Term: "Ana Luz", Alias: "AH-nah LOOS"}}
+
+	if got := lexicon.ApplyAliases("Ana Luz met Anatoly and Ana."); got != "AH-nah LOOS met Anatoly and AH-nah." {
+		t.Errorf("ApplyAliases = %q", got)
+	}
+	if lexicon.Matches("Banana and ana") {
+		t.Error("terms should only match whole, case-sensitive words")
+	}
+	if lexicon.Hash() != (Lexicon{lexicon[1], lexicon[0]}).Hash() {
+		t.Error("Hash should not depend on rule order")
+	}
+}
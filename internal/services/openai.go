@@ -5,10 +5,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"math"
+	"net/http"
 	"strings"
+	"time"
 
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/metrics"
 	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/tracing"
 	openai "github.com/sashabaranov/go-openai"
 )
 
@@ -17,28 +22,38 @@ type OpenAIService struct {
 }
 
 func NewOpenAIService(apiKey string) *OpenAIService {
+	cfg := openai.DefaultConfig(apiKey)
+	cfg.HTTPClient = &http.Client{Transport: tracing.Transport(nil)}
 	return &OpenAIService{
-		client: openai.NewClient(apiKey),
+		client: openai.NewClientWithConfig(cfg),
 	}
 }
 
 // ClipPlan represents a single clip in the generation plan
 type ClipPlan struct {
-	ClipIndex             int    `json:"clip_index"`
-	Script                string `json:"script"`
-	VoiceStyleInstruction string `json:"voice_style_instruction"`
-	ImagePrompt           string `json:"image_prompt"`
-	VideoPrompt           string `json:"video_prompt"`
-	EstimatedDurationSec  int    `json:"estimated_duration_sec"`
+	ClipIndex             int                 `json:"clip_index"`
+	Script                string              `json:"script"`
+	Lines                 []models.ScriptLine `json:"lines,omitempty"` // Dialogue mode only: speaker-tagged lines
+	VoiceStyleInstruction string              `json:"voice_style_instruction"`
+	ImagePrompt           string              `json:"image_prompt"`
+	VideoPrompt           string              `json:"video_prompt"`
+	EstimatedDurationSec  int                 `json:"estimated_duration_sec"`
+	Citations             []string            `json:"citations,omitempty"` // Research mode: IDs of the snippets backing the script
 }
 
 // VideoPlan represents the complete plan for video generation
 type VideoPlan struct {
-	Clips              []ClipPlan `json:"clips"`
-	TotalEstimatedSec  int        `json:"total_estimated_sec"`
-	NarrativeStructure string     `json:"narrative_structure"`
+	Clips              []ClipPlan        `json:"clips"`
+	HookVariants       []ClipPlan        `json:"hook_variants,omitempty"` // Alternative versions of clip 0
+	Publishing         models.Publishing `json:"publishing,omitempty"`    // Per-platform title, description and hashtags
+	TotalEstimatedSec  int               `json:"total_estimated_sec"`
+	NarrativeStructure string            `json:"narrative_structure"`
+	ValidationWarnings []string          `json:"validation_warnings,omitempty"` // Issues left after repair (set by GeneratePlan)
 }
 
+// hookVariantCount is how many alternative first clips the planner writes.
+const hookVariantCount = 2
+
 // PlanOptions holds per-project customization passed into plan generation.
 // All fields are optional pointers — nil means "use defaults".
 type PlanOptions struct {
@@ -47,105 +62,320 @@ type PlanOptions struct {
 	AspectRatio *string                // "9:16", "16:9", "1:1"
 	CTA         *string                // Call-to-action text for the final clip
 	Language    *string                // ISO 639-1 code ("en", "es", "fr", ...)
+	Speakers    []string               // Dialogue mode: speaker roles with a mapped voice (2+ enables it)
+	Sources     []SourceSnippet        // Research mode: snippets the script must be grounded in
 }
 
+// maxPlanRepairs is how many times GeneratePlan sends a plan that failed
+// validation back to the model for a repair.
+const maxPlanRepairs = 2
+
 // GeneratePlan generates a video plan using OpenAI structured output.
 // opts carries per-project customization; nil fields use global defaults.
-func (s *OpenAIService) GeneratePlan(ctx context.Context, topic string, targetDuration int, seriesGuidance *string, opts *PlanOptions) (*VideoPlan, error) {
+//
+// The plan is validated (ValidatePlan) and, when it has issues, returned to
+// the model in the same conversation with the list of issues to fix, up to
+// maxPlanRepairs times. Issues left on a usable plan are returned in
+// plan.ValidationWarnings; a plan that is still unusable is an error.
+func (s *OpenAIService) GeneratePlan(ctx context.Context, topic string, targetDuration int, seriesGuidance *string, opts *PlanOptions) (_ *VideoPlan, err error) {
+	defer metrics.ObserveProviderCall("openai", "generate_plan", time.Now(), &err)
+
 	// Build system prompt
 	systemPrompt := buildPlanSystemPrompt(targetDuration, seriesGuidance, opts)
 
 	// Build user prompt
 	userPrompt := buildPlanUserPrompt(topic, targetDuration, opts)
 
-	// Call OpenAI with structured output (using JSON mode)
-	resp, err := s.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model: "gpt-5-mini", // gpt-5-mini best for reasoning and cost efficiency
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: systemPrompt,
-			},
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: userPrompt,
-			},
+	messages := []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: systemPrompt,
 		},
-		ResponseFormat: &openai.ChatCompletionResponseFormat{
-			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		{
+			Role:    openai.ChatMessageRoleUser,
+			Content: userPrompt,
 		},
-		Temperature: 1.0,
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("openai request failed: %w", err)
 	}
 
-	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from openai")
-	}
-
-	rawContent := resp.Choices[0].Message.Content
 	const maxLogLen = 2000
-
-	// Parse the JSON response
-	var plan VideoPlan
-	if err := json.Unmarshal([]byte(rawContent), &plan); err != nil {
-		log.Printf("[OpenAI plan] parse failed: %v", err)
+	logRaw := func(rawContent string) {
 		if len(rawContent) > maxLogLen {
-			log.Printf("[OpenAI plan] raw response (truncated): %s...", rawContent[:maxLogLen])
+			joblog.Printf(ctx, "[OpenAI plan] raw response (truncated): %s...", rawContent[:maxLogLen])
 		} else {
-			log.Printf("[OpenAI plan] raw response: %s", rawContent)
+			joblog.Printf(ctx, "[OpenAI plan] raw response: %s", rawContent)
 		}
-		return nil, fmt.Errorf("failed to parse plan: %w", err)
 	}
 
-	// Validate plan
-	if len(plan.Clips) == 0 {
-		log.Printf("[OpenAI plan] plan has no clips (total_estimated_sec=%d, narrative_structure=%q)", plan.TotalEstimatedSec, plan.NarrativeStructure)
-		if len(rawContent) > maxLogLen {
-			log.Printf("[OpenAI plan] raw response (truncated): %s...", rawContent[:maxLogLen])
+	// The usable plan with the fewest issues so far, in case a repair makes
+	// things worse
+	var (
+		best       *VideoPlan
+		bestIssues []PlanIssue
+		bestResp   openai.ChatCompletionResponse
+		lastIssues []PlanIssue
+	)
+
+	for repair := 0; ; repair++ {
+		// Call OpenAI with structured output (using JSON mode)
+		resp, err := s.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Model:    "gpt-5-mini", // gpt-5-mini best for reasoning and cost efficiency
+			Messages: messages,
+			ResponseFormat: &openai.ChatCompletionResponseFormat{
+				Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+			},
+			Temperature: 1.0,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("openai request failed: %w", err)
+		}
+
+		if len(resp.Choices) == 0 {
+			return nil, fmt.Errorf("no response from openai")
+		}
+
+		rawContent := resp.Choices[0].Message.Content
+
+		// Parse the JSON response
+		var plan VideoPlan
+		var issues []PlanIssue
+		if err := json.Unmarshal([]byte(rawContent), &plan); err != nil {
+			joblog.Printf(ctx, "[OpenAI plan] parse failed: %v", err)
+			logRaw(rawContent)
+			issues = []PlanIssue{{Clip: -1, Fatal: true, Message: fmt.Sprintf("the response is not valid JSON: %v", err)}}
 		} else {
-			log.Printf("[OpenAI plan] raw response: %s", rawContent)
+			// Dialogue mode: keep speaker tags within the known roles and derive the
+			// clip script from its lines, so subtitles match exactly what is voiced
+			if opts != nil && len(opts.Speakers) >= 2 {
+				for i := range plan.Clips {
+					normalizeClipLines(ctx, &plan.Clips[i], opts.Speakers)
+				}
+				for i := range plan.HookVariants {
+					normalizeClipLines(ctx, &plan.HookVariants[i], opts.Speakers)
+				}
+			}
+			normalizePlan(&plan)
+			issues = ValidatePlan(&plan, targetDuration, opts)
 		}
-		planJSON, _ := json.MarshalIndent(plan, "", "  ")
-		log.Printf("[OpenAI plan] parsed plan: %s", string(planJSON))
-		return nil, fmt.Errorf("plan has no clips")
-	}
 
-	// Validate all required fields on each clip
-	for i, clip := range plan.Clips {
-		var missing []string
-		if clip.Script == "" {
-			missing = append(missing, "script")
+		lastIssues = issues
+		if !HasFatalPlanIssue(issues) && (best == nil || len(issues) < len(bestIssues)) {
+			best, bestIssues, bestResp = &plan, issues, resp
 		}
-		if clip.VoiceStyleInstruction == "" {
-			missing = append(missing, "voice_style_instruction")
+
+		if len(issues) == 0 || repair >= maxPlanRepairs {
+			break
 		}
-		if clip.ImagePrompt == "" {
-			missing = append(missing, "image_prompt")
+
+		if HasFatalPlanIssue(issues) && len(plan.Clips) > 0 {
+			logRaw(rawContent)
 		}
-		if clip.VideoPrompt == "" {
-			missing = append(missing, "video_prompt")
+
+		descriptions := make([]string, len(issues))
+		for i, issue := range issues {
+			descriptions[i] = issue.String()
 		}
-		if clip.EstimatedDurationSec == 0 {
-			missing = append(missing, "estimated_duration_sec")
+		joblog.Event(ctx, fmt.Sprintf("[OpenAI plan] %d validation issues, requesting a repair (%d/%d)", len(issues), repair+1, maxPlanRepairs),
+			map[string]interface{}{"provider": "openai", "response_id": resp.ID, "issues": descriptions})
+
+		messages = append(messages,
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: rawContent},
+			openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: buildPlanRepairPrompt(issues)},
+		)
+	}
+
+	if best == nil {
+		for _, issue := range lastIssues {
+			if issue.Fatal {
+				return nil, fmt.Errorf("invalid plan after %d repairs: %s", maxPlanRepairs, issue)
+			}
 		}
-		if len(missing) > 0 {
-			log.Printf("[OpenAI plan] clip %d missing required fields: %v", i, missing)
-			if len(rawContent) > maxLogLen {
-				log.Printf("[OpenAI plan] raw response (truncated): %s...", rawContent[:maxLogLen])
-			} else {
-				log.Printf("[OpenAI plan] raw response: %s", rawContent)
+		return nil, fmt.Errorf("invalid plan after %d repairs", maxPlanRepairs)
+	}
+
+	for _, issue := range bestIssues {
+		best.ValidationWarnings = append(best.ValidationWarnings, issue.String())
+	}
+
+	// Incomplete hook variants can't be rendered; the warnings name them
+	variants := best.HookVariants[:0]
+	for _, variant := range best.HookVariants {
+		if len(missingClipFields(variant)) == 0 {
+			variants = append(variants, variant)
+		}
+	}
+	best.HookVariants = variants
+
+	joblog.Event(ctx, fmt.Sprintf("[OpenAI plan] plan generated: %d clips, %d hook variants, total_estimated_sec=%d, narrative=%q, warnings=%d",
+		len(best.Clips), len(best.HookVariants), best.TotalEstimatedSec, best.NarrativeStructure, len(best.ValidationWarnings)),
+		map[string]interface{}{"provider": "openai", "request_id": bestResp.Header().Get("x-request-id"), "response_id": bestResp.ID, "warnings": best.ValidationWarnings})
+
+	return best, nil
+}
+
+// normalizePlan fixes what doesn't need the model: clip indexes follow the
+// clip order, total_estimated_sec is the sum of the clip durations, hook
+// variants are first clips and hashtags start with "#".
+func normalizePlan(plan *VideoPlan) {
+	total := 0
+	for i := range plan.Clips {
+		plan.Clips[i].ClipIndex = i
+		total += plan.Clips[i].EstimatedDurationSec
+	}
+	plan.TotalEstimatedSec = total
+
+	for i := range plan.HookVariants {
+		plan.HookVariants[i].ClipIndex = 0
+	}
+
+	for platform, meta := range plan.Publishing {
+		hashtags := meta.Hashtags[:0]
+		for _, tag := range meta.Hashtags {
+			tag = strings.TrimLeft(strings.TrimSpace(tag), "#")
+			if tag == "" {
+				continue
 			}
-			return nil, fmt.Errorf("clip %d missing required fields: %v", i, missing)
+			hashtags = append(hashtags, "#"+strings.ReplaceAll(tag, " ", ""))
+		}
+		meta.Hashtags = hashtags
+		meta.Title = strings.TrimSpace(meta.Title)
+		meta.Description = strings.TrimSpace(meta.Description)
+		plan.Publishing[platform] = meta
+	}
+}
+
+// buildPlanRepairPrompt asks the model to fix the listed issues in the plan
+// it just returned, leaving the rest of it alone.
+func buildPlanRepairPrompt(issues []PlanIssue) string {
+	var sb strings.Builder
+	sb.WriteString("Your plan has these problems:\n")
+	for _, issue := range issues {
+		sb.WriteString("- ")
+		sb.WriteString(issue.String())
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\nFix them and return the complete corrected plan as JSON in the same schema. " +
+		"Change only what the problems require: keep the story, the other clips' scripts and the prompts as they are. " +
+		"If you add or remove clips, renumber clip_index from 0.")
+	return sb.String()
+}
+
+// normalizeClipLines drops empty dialogue lines, maps unknown speaker roles to
+// the first known role (the narrator), and rebuilds clip.Script from the lines.
+func normalizeClipLines(ctx context.Context, clip *ClipPlan, speakers []string) {
+	if len(clip.Lines) == 0 {
+		return
+	}
+
+	known := make(map[string]bool, len(speakers))
+	for _, sp := range speakers {
+		known[sp] = true
+	}
+
+	lines := clip.Lines[:0]
+	for _, line := range clip.Lines {
+		line.Text = strings.TrimSpace(line.Text)
+		if line.Text == "" {
+			continue
 		}
+		if !known[line.Speaker] {
+			joblog.Warnf(ctx, "[OpenAI plan] clip %d: unknown speaker %q, using %q", clip.ClipIndex, line.Speaker, speakers[0])
+			line.Speaker = speakers[0]
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		clip.Lines = nil
+		return
 	}
+	clip.Lines = lines
+	clip.Script = ScriptFromLines(lines)
+}
 
-	log.Printf("[OpenAI plan] plan generated: %d clips, total_estimated_sec=%d, narrative=%q",
-		len(plan.Clips), plan.TotalEstimatedSec, plan.NarrativeStructure)
+// RewriteImagePrompt rewrites an image prompt the image model rejected
+// (reason is its error) into one likely to be accepted: same scene, subject
+// and composition, without the content that may have triggered the refusal.
+func (s *OpenAIService) RewriteImagePrompt(ctx context.Context, prompt, reason string) (_ string, err error) {
+	defer metrics.ObserveProviderCall("openai", "rewrite_image_prompt", time.Now(), &err)
 
-	return &plan, nil
+	resp, err := s.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: "gpt-5-mini",
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role: openai.ChatMessageRoleSystem,
+				Content: "You rewrite image generation prompts that an image model refused or failed on. " +
+					"Keep the scene's intent, subject, composition and style, but remove or soften anything " +
+					"that may trip a safety filter: graphic violence, gore, nudity, real people's names, " +
+					"brands, weapons in use, minors in danger. Reply with the rewritten prompt only.",
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: fmt.Sprintf("Prompt:\n%s\n\nImage model error:\n%s", prompt, reason),
+			},
+		},
+		Temperature: 1.0,
+	})
+	if err != nil {
+		return "", fmt.Errorf("openai request failed: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from openai")
+	}
+
+	rewritten := strings.TrimSpace(resp.Choices[0].Message.Content)
+	if rewritten == "" {
+		return "", fmt.Errorf("openai returned an empty prompt")
+	}
+	return rewritten, nil
+}
+
+// FitClipScript rewrites a clip's narration to be spoken in about
+// targetSec seconds instead of currentSec (its measured length), keeping its
+// meaning, tone, language and place in the story. Markup is kept where it
+// still fits.
+func (s *OpenAIService) FitClipScript(ctx context.Context, script string, currentSec, targetSec float64, language string) (_ string, err error) {
+	defer metrics.ObserveProviderCall("openai", "fit_clip_script", time.Now(), &err)
+
+	if language == "" {
+		language = "en"
+	}
+	action := "Shorten"
+	if targetSec > currentSec {
+		action = "Lengthen"
+	}
+	words := len(strings.Fields(PlainScript(script)))
+	targetWords := int(math.Round(float64(words) * targetSec / currentSec))
+
+	resp, err := s.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: "gpt-5-mini",
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role: openai.ChatMessageRoleSystem,
+				Content: "You edit voiceover narration for short-form videos to fit a time slot. " +
+					"Keep the meaning, tone, language and facts, and keep how it opens and closes: " +
+					"it connects to the clips before and after. Keep delivery markup such as [pause], *emphasis* " +
+					"and [spell:X] only where it still fits. Reply with the narration only.",
+			},
+			{
+				Role: openai.ChatMessageRoleUser,
+				Content: fmt.Sprintf("%s this narration (language: %s) from about %.0f to about %.0f seconds when read aloud, "+
+					"which is about %d words instead of %d:\n\n%s", action, language, currentSec, targetSec, targetWords, words, script),
+			},
+		},
+		Temperature: 1.0,
+	})
+	if err != nil {
+		return "", fmt.Errorf("openai request failed: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from openai")
+	}
+
+	fitted := strings.TrimSpace(resp.Choices[0].Message.Content)
+	if fitted == "" {
+		return "", fmt.Errorf("openai returned an empty script")
+	}
+	return fitted, nil
 }
 
 // ---------------------------------------------------------------------------
@@ -155,15 +385,18 @@ func (s *OpenAIService) GeneratePlan(ctx context.Context, topic string, targetDu
 // WordTimestamp represents a single word with its precise timing from Whisper.
 // Used to generate TikTok-style word-by-word highlighted subtitles.
 type WordTimestamp struct {
-	Word  string  `json:"word"`
-	Start float64 `json:"start"` // seconds
-	End   float64 `json:"end"`   // seconds
+	Word    string  `json:"word"`
+	Start   float64 `json:"start"`             // seconds
+	End     float64 `json:"end"`               // seconds
+	Speaker string  `json:"speaker,omitempty"` // Dialogue speaker role (empty = single narrator)
 }
 
 // TranscribeAudio sends audio to OpenAI Whisper and returns word-level timestamps.
 // The audio bytes should be the raw TTS output (before any silence prepend).
 // The caller is responsible for adding any time offset (e.g., for prepended silence).
-func (s *OpenAIService) TranscribeAudio(ctx context.Context, audioData []byte, language string) ([]WordTimestamp, error) {
+func (s *OpenAIService) TranscribeAudio(ctx context.Context, audioData []byte, language string) (_ []WordTimestamp, err error) {
+	defer metrics.ObserveProviderCall("openai", "transcribe", time.Now(), &err)
+
 	if language == "" {
 		language = "en"
 	}
@@ -195,8 +428,9 @@ func (s *OpenAIService) TranscribeAudio(ctx context.Context, audioData []byte, l
 		}
 	}
 
-	log.Printf("[Whisper] Transcribed %d words (duration: %.1fs, text: %q)",
-		len(words), resp.Duration, truncateString(resp.Text, 80))
+	joblog.Event(ctx, fmt.Sprintf("[Whisper] Transcribed %d words (duration: %.1fs, text: %q)",
+		len(words), resp.Duration, truncateString(resp.Text, 80)),
+		map[string]interface{}{"provider": "openai", "request_id": resp.Header().Get("x-request-id")})
 
 	return words, nil
 }
@@ -322,6 +556,12 @@ Spoken delivery:
 - Each script should feel like one thought or moment, not a wall of text. Aim for 2-3 sentences per clip.
 - Read each script aloud in your head. If it sounds rushed, shorten it. If it sounds like a Wikipedia article, rewrite it.
 
+Delivery markup (optional — use sparingly, at most once or twice per clip):
+- [pause] or [pause 1s]: a deliberate beat of silence before a reveal. Never longer than 2s.
+- *word*: emphasis on a key word or short phrase.
+- [spell:FBI]: an acronym that must be read letter by letter.
+Markup is never shown on screen; subtitles display the plain words. Use no other brackets or asterisks in scripts.
+
 IMAGE PROMPTS - CRITICAL:
 Every image_prompt MUST be a complete, detailed scene description rendered in the "%s" visual style. Include ALL of the following:
 
@@ -372,6 +612,41 @@ If ANY field is empty or zero, the plan is INVALID and will be rejected.
 
 Structure your response as JSON matching the required schema.`, visualStyle, aspectRatio, aspectRatio, targetDuration)
 
+	if opts != nil && len(opts.Speakers) >= 2 {
+		basePrompt += fmt.Sprintf(`
+
+DIALOGUE MODE - MULTIPLE VOICES:
+This story is performed by several voices, each with its own voice actor: %s.
+"%s" is the narrator; the other roles are characters who speak in their own words.
+For every clip, add a "lines" array with the spoken lines in order. Each line is an object with:
+- speaker: One of the roles above, exactly as written. NEVER invent other roles.
+- text: What that speaker says. Keep each line short — one or two sentences.
+- voice_style_instruction: Optional delivery note for this line only (English).
+The clip's script field must be all of its lines' text joined in order with spaces.
+A clip may be narrator-only (a single narrator line). Keep the total spoken length per clip within the same 8-10 second budget — several voices do not mean more words.`,
+			strings.Join(opts.Speakers, ", "), opts.Speakers[0])
+	}
+
+	basePrompt += fmt.Sprintf(`
+
+HOOK VARIANTS:
+Also write %d alternative versions of clip 0 in a top-level "hook_variants" array, so the opening can be A/B tested.
+Each variant is a complete clip object with the same fields as clip 0 (script, voice_style_instruction, image_prompt, video_prompt, estimated_duration_sec%s).
+Each variant must open the same story with a genuinely different hook — a question, a shocking fact, a bold claim, a mystery — and lead naturally into clip 1.
+Keep each variant's duration close to clip 0's.
+
+PUBLISHING METADATA:
+Add a top-level "publishing" object with one entry for each of %s. Each entry has:
+- title: The post title, written for that platform (YouTube Shorts: searchable, under 100 characters; TikTok and Instagram Reels: a short caption-style title).
+- description: The post description for that platform, 1-3 sentences.
+- hashtags: 3-8 relevant hashtags for that platform, each starting with "#".
+Write them in the same language as the script.`,
+		hookVariantCount, dialogueLinesField(opts), strings.Join(models.PublishingPlatforms, ", "))
+
+	if opts != nil && len(opts.Sources) > 0 {
+		basePrompt += "\n\n" + buildResearchPrompt(opts.Sources)
+	}
+
 	if seriesGuidance != nil && *seriesGuidance != "" {
 		basePrompt += fmt.Sprintf("\n\nSeries Guidance:\n%s", *seriesGuidance)
 	}
@@ -379,6 +654,31 @@ Structure your response as JSON matching the required schema.`, visualStyle, asp
 	return basePrompt
 }
 
+// buildResearchPrompt lists the research snippets and asks for a script
+// grounded in them, with each clip citing the snippets it draws on.
+func buildResearchPrompt(snippets []SourceSnippet) string {
+	var sb strings.Builder
+	sb.WriteString(`RESEARCH MODE - GROUNDED IN SOURCES:
+The script must be grounded in the source snippets below. Every factual claim (names, dates, numbers, events, causes) must be supported by at least one snippet.
+Do NOT state facts that the snippets don't support — leave them out, or clearly frame them as open questions. The story, hook and CTA may still be your own words.
+For every clip (and hook variant), add a "citations" array with the IDs of the snippets backing its claims, e.g. ["S1.2", "S3.1"]. Use only the IDs listed below; a clip without factual claims has an empty array.
+
+SOURCE SNIPPETS:`)
+	for _, snippet := range snippets {
+		fmt.Fprintf(&sb, "\n[%s] (%s) %s", snippet.ID, snippet.Title, snippet.Text)
+	}
+	return sb.String()
+}
+
+// dialogueLinesField names the lines field among a clip's fields in
+// dialogue mode.
+func dialogueLinesField(opts *PlanOptions) string {
+	if opts != nil && len(opts.Speakers) >= 2 {
+		return ", lines"
+	}
+	return ""
+}
+
 // buildPlanUserPrompt constructs the user-facing prompt with customization context.
 func buildPlanUserPrompt(topic string, targetDuration int, opts *PlanOptions) string {
 	prompt := fmt.Sprintf("Generate a compelling short-form video plan for the topic: \"%s\"\n\nTarget duration: %d seconds", topic, targetDuration)
@@ -398,6 +698,12 @@ func buildPlanUserPrompt(topic string, targetDuration int, opts *PlanOptions) st
 		if opts.CTA != nil && *opts.CTA != "" {
 			extras = append(extras, fmt.Sprintf("End with CTA: \"%s\"", *opts.CTA))
 		}
+		if len(opts.Speakers) >= 2 {
+			extras = append(extras, fmt.Sprintf("Dialogue between: %s", strings.Join(opts.Speakers, ", ")))
+		}
+		if len(opts.Sources) > 0 {
+			extras = append(extras, fmt.Sprintf("Research mode: ground the script in the %d source snippets and cite them", len(opts.Sources)))
+		}
 		if len(extras) > 0 {
 			prompt += "\n\nCustomization:\n- " + strings.Join(extras, "\n- ")
 		}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/bobarin/episod/internal/models"
+)
+
+// ---------------------------------------------------------------------------
+// Plan Validation — checks a VideoPlan against what the pipeline needs and
+// what the project asked for:
+//
+//   - every clip has a script, voice instruction, image and video prompt
+//     and a duration (fatal: the clip can't be produced without them)
+//   - the clip count fits the target duration at 8-12 seconds per clip
+//   - the clip durations add up to roughly the target duration
+//   - each script can be spoken in its clip's duration at narration pace
+//   - the last clip carries the project's call-to-action
+//   - in research mode, clips cite only known source snippets, and at least
+//     one clip cites a source
+//   - the hook variants are complete and every publishing platform has a
+//     title, description and hashtags (not fatal: the video doesn't need them)
+//
+// GeneratePlan sends the issues back to the model for a repair; whatever is
+// left on an otherwise usable plan becomes the project's plan warnings.
+// ---------------------------------------------------------------------------
+
+const (
+	minClipDurationSec = 8  // Shortest clip the planner is asked for
+	maxClipDurationSec = 12 // Length of a generated AI video clip
+
+	// planDurationTolerance is how far the clip durations may add up from
+	// the target duration, as a fraction of it.
+	planDurationTolerance = 0.2
+
+	// narrationWordsPerSec is the narration pace scripts are measured at
+	// (~140 words per minute, as in estimateAudioDuration).
+	narrationWordsPerSec = 140.0 / 60.0
+
+	// scriptDurationTolerance is how far a script's spoken length may be
+	// from its clip's estimated_duration_sec, as a fraction of it.
+	scriptDurationTolerance = 0.35
+
+	// ctaMatchRatio is the share of the CTA's words the last script must
+	// contain — the CTA is woven into the narration, not pasted verbatim.
+	ctaMatchRatio = 0.75
+)
+
+// unspacedLanguages don't separate words with spaces, so scripts in them
+// can't be measured in words.
+var unspacedLanguages = map[string]bool{"zh": true, "ja": true, "th": true, "lo": true, "km": true, "my": true}
+
+// PlanIssue is one problem found in a plan.
+type PlanIssue struct {
+	Clip    int    // Clip position, or -1 for the plan as a whole
+	Fatal   bool   // The plan can't be produced as is
+	Message string // Written for the model that repairs the plan
+}
+
+func (i PlanIssue) String() string {
+	if i.Clip < 0 {
+		return i.Message
+	}
+	return fmt.Sprintf("clip %d: %s", i.Clip, i.Message)
+}
+
+// HasFatalPlanIssue reports whether any of the issues makes the plan unusable.
+func HasFatalPlanIssue(issues []PlanIssue) bool {
+	for _, issue := range issues {
+		if issue.Fatal {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidatePlan checks a plan for a video of targetDuration seconds. opts
+// supplies the CTA and language; nil skips the CTA check.
+func ValidatePlan(plan *VideoPlan, targetDuration int, opts *PlanOptions) []PlanIssue {
+	if len(plan.Clips) == 0 {
+		return []PlanIssue{{Clip: -1, Fatal: true, Message: "the plan has no clips"}}
+	}
+
+	var issues []PlanIssue
+
+	// Clip count: the target split into 8-12 second clips
+	if targetDuration > 0 {
+		minClips := int(math.Ceil(float64(targetDuration) / maxClipDurationSec))
+		maxClips := int(math.Ceil(float64(targetDuration) / minClipDurationSec))
+		if minClips < 1 {
+			minClips = 1
+		}
+		if n := len(plan.Clips); n < minClips || n > maxClips {
+			issues = append(issues, PlanIssue{Clip: -1, Message: fmt.Sprintf(
+				"the plan has %d clips; a %d-second video needs %d to %d clips of %d-%d seconds",
+				n, targetDuration, minClips, maxClips, minClipDurationSec, maxClipDurationSec)})
+		}
+	}
+
+	language := ""
+	if opts != nil && opts.Language != nil {
+		language = strings.ToLower(*opts.Language)
+	}
+
+	total := 0
+	for i, clip := range plan.Clips {
+		if missing := missingClipFields(clip); len(missing) > 0 {
+			issues = append(issues, PlanIssue{Clip: i, Fatal: true, Message: fmt.Sprintf(
+				"missing required fields: %s", strings.Join(missing, ", "))})
+			continue
+		}
+
+		total += clip.EstimatedDurationSec
+		if clip.EstimatedDurationSec > maxClipDurationSec {
+			issues = append(issues, PlanIssue{Clip: i, Message: fmt.Sprintf(
+				"estimated_duration_sec is %d, longer than the %d-second video clip",
+				clip.EstimatedDurationSec, maxClipDurationSec)})
+		}
+
+		// Script length against the clip's duration at narration pace
+		if unspacedLanguages[language] {
+			continue
+		}
+		spoken := EstimateSpeechSeconds(clip.Script)
+		estimated := float64(clip.EstimatedDurationSec)
+		words := len(strings.Fields(PlainScript(clip.Script)))
+		switch {
+		case spoken > estimated*(1+scriptDurationTolerance):
+			issues = append(issues, PlanIssue{Clip: i, Message: fmt.Sprintf(
+				"the script has %d words (~%.0f seconds spoken) but estimated_duration_sec is %d; shorten it to about %d words",
+				words, spoken, clip.EstimatedDurationSec, wordsForSeconds(estimated))})
+		case spoken < estimated*(1-scriptDurationTolerance):
+			issues = append(issues, PlanIssue{Clip: i, Message: fmt.Sprintf(
+				"the script has %d words (~%.0f seconds spoken) but estimated_duration_sec is %d; lengthen it to about %d words",
+				words, spoken, clip.EstimatedDurationSec, wordsForSeconds(estimated))})
+		}
+	}
+
+	// Duration sum against the target
+	if targetDuration > 0 && !HasFatalPlanIssue(issues) {
+		if diff := math.Abs(float64(total - targetDuration)); diff > float64(targetDuration)*planDurationTolerance {
+			issues = append(issues, PlanIssue{Clip: -1, Message: fmt.Sprintf(
+				"the clip durations add up to %d seconds but the target is %d seconds", total, targetDuration)})
+		}
+	}
+
+	// CTA in the last clip
+	if opts != nil && opts.CTA != nil && strings.TrimSpace(*opts.CTA) != "" {
+		last := len(plan.Clips) - 1
+		if !containsCTA(plan.Clips[last].Script, *opts.CTA) {
+			issues = append(issues, PlanIssue{Clip: last, Message: fmt.Sprintf(
+				"the final clip's script must end with the call-to-action %q", *opts.CTA)})
+		}
+	}
+
+	// Citations in research mode
+	if opts != nil && len(opts.Sources) > 0 {
+		issues = append(issues, validateCitations(plan, opts.Sources)...)
+	}
+
+	// Hook variants and publishing metadata
+	if n := len(plan.HookVariants); n < hookVariantCount {
+		issues = append(issues, PlanIssue{Clip: -1, Message: fmt.Sprintf(
+			"the plan has %d hook variants; hook_variants must hold %d alternative versions of clip 0", n, hookVariantCount)})
+	}
+	for i, variant := range plan.HookVariants {
+		if missing := missingClipFields(variant); len(missing) > 0 {
+			issues = append(issues, PlanIssue{Clip: -1, Message: fmt.Sprintf(
+				"hook variant %d: missing required fields: %s", i, strings.Join(missing, ", "))})
+		}
+	}
+	for _, platform := range models.PublishingPlatforms {
+		meta := plan.Publishing[platform]
+		if meta.Title == "" || meta.Description == "" || len(meta.Hashtags) == 0 {
+			issues = append(issues, PlanIssue{Clip: -1, Message: fmt.Sprintf(
+				"publishing.%s needs a title, a description and hashtags", platform)})
+		}
+	}
+
+	return issues
+}
+
+// validateCitations checks the clips' citations against the research
+// snippets.
+func validateCitations(plan *VideoPlan, snippets []SourceSnippet) []PlanIssue {
+	known := make(map[string]bool, len(snippets))
+	for _, snippet := range snippets {
+		known[snippet.ID] = true
+	}
+
+	var issues []PlanIssue
+	citing := 0
+	for i, clip := range plan.Clips {
+		var unknown []string
+		for _, id := range clip.Citations {
+			if !known[strings.TrimSpace(id)] {
+				unknown = append(unknown, id)
+			}
+		}
+		if len(unknown) > 0 {
+			issues = append(issues, PlanIssue{Clip: i, Message: fmt.Sprintf(
+				"citations lists unknown snippet IDs %s; use only the IDs under SOURCE SNIPPETS", strings.Join(unknown, ", "))})
+		}
+		if len(clip.Citations) > len(unknown) {
+			citing++
+		}
+	}
+	if citing == 0 {
+		issues = append(issues, PlanIssue{Clip: -1, Message: "no clip cites a source snippet; " +
+			"ground the claims in the snippets and list their IDs in each clip's citations"})
+	}
+	return issues
+}
+
+// missingClipFields lists the required fields a clip leaves empty.
+func missingClipFields(clip ClipPlan) []string {
+	var missing []string
+	if strings.TrimSpace(clip.Script) == "" {
+		missing = append(missing, "script")
+	}
+	if strings.TrimSpace(clip.VoiceStyleInstruction) == "" {
+		missing = append(missing, "voice_style_instruction")
+	}
+	if strings.TrimSpace(clip.ImagePrompt) == "" {
+		missing = append(missing, "image_prompt")
+	}
+	if strings.TrimSpace(clip.VideoPrompt) == "" {
+		missing = append(missing, "video_prompt")
+	}
+	if clip.EstimatedDurationSec <= 0 {
+		missing = append(missing, "estimated_duration_sec")
+	}
+	return missing
+}
+
+// EstimateSpeechSeconds estimates how long a script takes to narrate:
+// its words at narration pace plus its [pause] markup.
+func EstimateSpeechSeconds(script string) float64 {
+	var words int
+	var pauses float64
+	for _, seg := range ParseScriptMarkup(script) {
+		if seg.Kind == SegmentPause {
+			pauses += seg.Pause.Seconds()
+			continue
+		}
+		words += len(strings.Fields(seg.Text))
+	}
+	return float64(words)/narrationWordsPerSec + pauses
+}
+
+// wordsForSeconds is the number of words narrated in seconds.
+func wordsForSeconds(seconds float64) int {
+	return int(math.Round(seconds * narrationWordsPerSec))
+}
+
+// containsCTA reports whether script contains most of the CTA's words,
+// ignoring case and punctuation.
+func containsCTA(script, cta string) bool {
+	ctaWords := normalizedWords(cta)
+	if len(ctaWords) == 0 {
+		return true
+	}
+
+	inScript := make(map[string]bool)
+	for _, word := range normalizedWords(PlainScript(script)) {
+		inScript[word] = true
+	}
+	found := 0
+	for _, word := range ctaWords {
+		if inScript[word] {
+			found++
+		}
+	}
+	return float64(found) >= float64(len(ctaWords))*ctaMatchRatio
+}
+
+// normalizedWords lowercases text and splits it into words, dropping
+// punctuation.
+func normalizedWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bobarin/episod/internal/models"
+)
+
+// validClip returns a complete clip whose default 20-word script fits 10 seconds.
+func validClip(script string) ClipPlan {
+	if script == "" {
+		script = "Somewhere beneath the ice, a lake has waited in the dark for fifteen million years. Nobody knew it was there."
+	}
+	return ClipPlan{
+		Script:                script,
+		VoiceStyleInstruction: "slow, hushed",
+		ImagePrompt:           "a frozen lake under a night sky",
+		VideoPrompt:           "the camera drifts over the ice",
+		EstimatedDurationSec:  10,
+	}
+}
+
+// validPublishing returns complete metadata for every platform.
+func validPublishing() models.Publishing {
+	publishing := make(models.Publishing)
+	for _, platform := range models.PublishingPlatforms {
+		publishing[platform] = models.PublishingMetadata{
+			Title:       "The lake under the ice",
+			Description: "Fifteen million years in the dark.",
+			Hashtags:    []string{"#antarctica", "#science"},
+		}
+	}
+	return publishing
+}
+
+func TestValidatePlan(t *testing.T) {
+	cta := "Follow for more stories"
+	opts := &PlanOptions{CTA: &cta}
+	withCTA := validClip("And there's so much more beneath the surface. Follow for more stories like this one, every single week.")
+
+	tests := []struct {
+		name   string
+		clips  []ClipPlan
+		target int
+		opts   *PlanOptions
+		want   []string // Substrings of the expected issues, in order
+		fatal  bool
+	}{
+		{
+			name:   "valid plan",
+			clips:  []ClipPlan{validClip(""), validClip(""), withCTA},
+			target: 30,
+			opts:   opts,
+		},
+		{
+			name:   "no clips",
+			target: 30,
+			want:   []string{"no clips"},
+			fatal:  true,
+		},
+		{
+			name:   "missing fields",
+			clips:  []ClipPlan{validClip(""), {Script: "Hello there.", EstimatedDurationSec: 10}, validClip("")},
+			target: 30,
+			want:   []string{"clip 1: missing required fields: voice_style_instruction, image_prompt, video_prompt"},
+			fatal:  true,
+		},
+		{
+			name:   "too few clips for the target",
+			clips:  []ClipPlan{validClip(""), validClip("")},
+			target: 60,
+			want:   []string{"the plan has 2 clips; a 60-second video needs 5 to 8 clips", "add up to 20 seconds but the target is 60"},
+		},
+		{
+			name:   "script too long for its duration",
+			clips:  []ClipPlan{validClip(strings.Repeat("word ", 40)), validClip(""), validClip("")},
+			target: 30,
+			want:   []string{"clip 0: the script has 40 words (~17 seconds spoken) but estimated_duration_sec is 10; shorten it to about 23 words"},
+		},
+		{
+			name:   "script too short for its duration",
+			clips:  []ClipPlan{validClip(""), validClip("Then silence."), validClip("")},
+			target: 30,
+			want:   []string{"clip 1: the script has 2 words"},
+		},
+		{
+			name:   "pauses count as spoken time",
+			clips:  []ClipPlan{validClip(""), validClip("Then... [pause 2s] silence. [pause 2s] Nothing at all, for a long, long while. [pause 1s]"), validClip("")},
+			target: 30,
+		},
+		{
+			name:   "missing CTA",
+			clips:  []ClipPlan{validClip(""), validClip(""), validClip("")},
+			target: 30,
+			opts:   opts,
+			want:   []string{`clip 2: the final clip's script must end with the call-to-action "Follow for more stories"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := &VideoPlan{Clips: tt.clips, HookVariants: []ClipPlan{validClip(""), validClip("")}, Publishing: validPublishing()}
+			issues := ValidatePlan(plan, tt.target, tt.opts)
+			if len(issues) != len(tt.want) {
+				t.Fatalf("got %d issues %v, want %d", len(issues), issues, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(issues[i].String(), want) {
+					t.Errorf("issue %d = %q, want it to contain %q", i, issues[i], want)
+				}
+			}
+			if got := HasFatalPlanIssue(issues); got != tt.fatal {
+				t.Errorf("HasFatalPlanIssue = %v, want %v", got, tt.fatal)
+			}
+		})
+	}
+}
+
+func TestValidatePlanHookVariantsAndPublishing(t *testing.T) {
+	clips := []ClipPlan{validClip(""), validClip(""), validClip("")}
+	publishing := validPublishing()
+	delete(publishing, models.PlatformYouTubeShorts)
+
+	issues := ValidatePlan(&VideoPlan{
+		Clips:        clips,
+		HookVariants: []ClipPlan{{Script: "What if the ice is hiding something?", EstimatedDurationSec: 10}},
+		Publishing:   publishing,
+	}, 30, nil)
+
+	want := []string{
+		"the plan has 1 hook variants; hook_variants must hold 2 alternative versions of clip 0",
+		"hook variant 0: missing required fields: voice_style_instruction, image_prompt, video_prompt",
+		"publishing.youtube_shorts needs a title, a description and hashtags",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("got %d issues %v, want %d", len(issues), issues, len(want))
+	}
+	for i := range want {
+		if issues[i].String() != want[i] {
+			t.Errorf("issue %d = %q, want %q", i, issues[i], want[i])
+		}
+	}
+	if HasFatalPlanIssue(issues) {
+		t.Error("hook variant and publishing issues must not be fatal")
+	}
+}
+
+func TestValidatePlanCitations(t *testing.T) {
+	opts := &PlanOptions{Sources: []SourceSnippet{{ID: "S1.1", Source: 1, Text: "Lake Vostok is buried under the ice."}}}
+	plan := func(citations ...[]string) *VideoPlan {
+		plan := &VideoPlan{HookVariants: []ClipPlan{validClip(""), validClip("")}, Publishing: validPublishing()}
+		for _, ids := range citations {
+			clip := validClip("")
+			clip.Citations = ids
+			plan.Clips = append(plan.Clips, clip)
+		}
+		return plan
+	}
+
+	if issues := ValidatePlan(plan([]string{"S1.1"}, nil, nil), 30, opts); len(issues) != 0 {
+		t.Errorf("got issues %v for a cited plan", issues)
+	}
+
+	issues := ValidatePlan(plan([]string{"S1.1", "S4.2"}, nil, nil), 30, opts)
+	if len(issues) != 1 || !strings.Contains(issues[0].String(), "clip 0: citations lists unknown snippet IDs S4.2") {
+		t.Errorf("got issues %v, want the unknown ID reported", issues)
+	}
+
+	issues = ValidatePlan(plan(nil, nil, nil), 30, opts)
+	if len(issues) != 1 || !strings.Contains(issues[0].String(), "no clip cites a source snippet") {
+		t.Errorf("got issues %v, want the uncited plan reported", issues)
+	}
+	if HasFatalPlanIssue(issues) {
+		t.Error("citation issues must not be fatal")
+	}
+}
+
+func TestNormalizePlanHashtags(t *testing.T) {
+	plan := &VideoPlan{Publishing: models.Publishing{
+		models.PlatformTikTok: {Title: " Title ", Hashtags: []string{"science", "#deep sea", " ", "##ice"}},
+	}}
+	normalizePlan(plan)
+
+	got := plan.Publishing[models.PlatformTikTok]
+	if want := []string{"#science", "#deepsea", "#ice"}; strings.Join(got.Hashtags, " ") != strings.Join(want, " ") {
+		t.Errorf("hashtags = %v, want %v", got.Hashtags, want)
+	}
+	if got.Title != "Title" {
+		t.Errorf("title = %q, want %q", got.Title, "Title")
+	}
+}
+
+func TestContainsCTA(t *testing.T) {
+	tests := []struct {
+		script string
+		want   bool
+	}{
+		{"That's the story. Follow for more stories!", true},
+		{"That's the story — *follow* for more of these stories.", true},
+		{"That's the story. See you next time.", false},
+	}
+
+	for _, tt := range tests {
+		if got := containsCTA(tt.script, "Follow for more stories."); got != tt.want {
+			t.Errorf("containsCTA(%q) = %v, want %v", tt.script, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ---------------------------------------------------------------------------
+// Pronunciation Lexicon — how TTS should say names, brands and jargon
+//
+// Entries come from the pronunciations table (global, per-user and
+// per-series). Each maps a term, matched case-sensitively as a whole word, to
+// a phonetic respelling (alias, "KEE-ah-no") and/or an IPA transcription.
+// Providers apply the lexicon to the text they synthesize only; the script
+// and subtitles keep the original spelling.
+// ---------------------------------------------------------------------------
+
+// PronunciationRule tells TTS how to say one term. At least one of Alias and IPA is set.
+type PronunciationRule struct {
+	Term  string
+	Alias string // Phonetic respelling, e.g. "Kee-AH-no"
+	IPA   string // IPA transcription, e.g. "kiˈɑːnoʊ"
+}
+
+// Lexicon is a set of pronunciation rules. A nil Lexicon is valid and empty.
+type Lexicon []PronunciationRule
+
+// Matches reports whether any rule's term occurs in text.
+func (l Lexicon) Matches(text string) bool {
+	found := false
+	l.apply(text, func(PronunciationRule) (string, bool) {
+		found = true
+		return "", false
+	})
+	return found
+}
+
+// ApplyAliases replaces every term that has an alias with its respelling.
+// Terms with only an IPA transcription are left untouched.
+func (l Lexicon) ApplyAliases(text string) string {
+	return l.apply(text, func(rule PronunciationRule) (string, bool) {
+		return rule.Alias, rule.Alias != ""
+	})
+}
+
+// Hash returns a stable content hash of the lexicon, used to cache
+// provider-side dictionaries built from it.
+func (l Lexicon) Hash() string {
+	rules := append(Lexicon(nil), l...)
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Term < rules[j].Term })
+
+	h := sha256.New()
+	for _, r := range rules {
+		h.Write([]byte(r.Term + "\x00" + r.Alias + "\x00" + r.IPA + "\x00"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// apply scans text once and replaces whole-word term matches with whatever
+// replace returns (if ok). Longer terms win over shorter ones that share a
+// prefix ("New York City" before "New York").
+func (l Lexicon) apply(text string, replace func(PronunciationRule) (string, bool)) string {
+	if len(l) == 0 {
+		return text
+	}
+	rules := append(Lexicon(nil), l...)
+	sort.SliceStable(rules, func(i, j int) bool { return len(rules[i].Term) > len(rules[j].Term) })
+
+	var sb strings.Builder
+	prevWord := false
+	for i := 0; i < len(text); {
+		if !prevWord {
+			if rule, ok := matchRuleAt(rules, text, i); ok {
+				if repl, ok := replace(rule); ok {
+					sb.WriteString(repl)
+					i += len(rule.Term)
+					prevWord = true
+					continue
+				}
+			}
+		}
+		r, size := utf8.DecodeRuneInString(text[i:])
+		sb.WriteString(text[i : i+size])
+		prevWord = isWordRune(r)
+		i += size
+	}
+	return sb.String()
+}
+
+// matchRuleAt returns the first rule whose term starts at text[i:] and ends on a word boundary.
+func matchRuleAt(rules Lexicon, text string, i int) (PronunciationRule, bool) {
+	for _, rule := range rules {
+		if rule.Term == "" || !strings.HasPrefix(text[i:], rule.Term) {
+			continue
+		}
+		end := i + len(rule.Term)
+		if end < len(text) {
+			if next, _ := utf8.DecodeRuneInString(text[end:]); isWordRune(next) {
+				continue
+			}
+		}
+		return rule, true
+	}
+	return PronunciationRule{}, false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,464 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/tracing"
+	"golang.org/x/net/html"
+)
+
+// ---------------------------------------------------------------------------
+// Research mode — grounds a plan in user-supplied sources:
+//
+//   - each source is a URL, fetched by a SourceFetcher (HTTP in production,
+//     a local file store in tests and offline runs), or text supplied with
+//     the project
+//   - the documents are split into numbered snippets ("S2.4" = source 2,
+//     snippet 4); when they exceed the prompt budget, the snippets sharing
+//     the most words with the topic are kept
+//   - the planner writes from the snippets and lists the IDs backing each
+//     clip, which become the clip's citations
+// ---------------------------------------------------------------------------
+
+const (
+	snippetMinChars    = 200   // Shorter paragraphs are joined with the next
+	snippetMaxChars    = 600   // Longer paragraphs are split at sentences
+	researchMaxChars   = 12000 // Snippet text shown to the planner
+	sourceMaxBytes     = 2 << 20
+	sourceFetchTimeout = 30 * time.Second
+	sourceMaxRedirects = 5
+)
+
+// SourceDocument is a fetched source as plain text.
+type SourceDocument struct {
+	Title string
+	URL   string
+	Text  string // Paragraphs separated by blank lines
+}
+
+// SourceFetcher fetches a research source by URL.
+type SourceFetcher interface {
+	Fetch(ctx context.Context, rawURL string) (*SourceDocument, error)
+}
+
+// HTTPSourceFetcher fetches HTML and plain-text sources over HTTP. Source
+// URLs come from users, so it only connects to public addresses: the check
+// runs on every connection, after DNS resolution, which covers redirects and
+// hostnames resolving to internal addresses.
+type HTTPSourceFetcher struct {
+	client *http.Client
+}
+
+func NewHTTPSourceFetcher() *HTTPSourceFetcher {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: dialPublicOnly}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // A proxy would be the address checked instead of the source's
+	transport.DialContext = dialer.DialContext
+
+	return &HTTPSourceFetcher{
+		client: &http.Client{
+			Transport: tracing.Transport(transport),
+			Timeout:   sourceFetchTimeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= sourceMaxRedirects {
+					return fmt.Errorf("stopped after %d redirects", sourceMaxRedirects)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// dialPublicOnly refuses connections to non-public addresses.
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("unexpected source address %q: %w", address, err)
+	}
+	if !IsPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("source address %s is not public", addrPort.Addr())
+	}
+	return nil
+}
+
+// Address ranges not covered by the netip predicates
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // "This network"
+	netip.MustParsePrefix("100.64.0.0/10"), // Carrier-grade NAT
+}
+
+// IsPublicAddr reports whether addr is a public unicast address: not
+// loopback, private (RFC 1918, fc00::/7), link-local (which includes the
+// 169.254.169.254 cloud metadata service), unspecified, multicast or
+// carrier-grade NAT.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsUnspecified() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *HTTPSourceFetcher) Fetch(ctx context.Context, rawURL string) (*SourceDocument, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source URL: %w", err)
+	}
+	req.Header.Set("Accept", "text/html, text/plain")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("source request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("source returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, sourceMaxBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source: %w", err)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return parseSourceDocument(rawURL, mediaType, body)
+}
+
+// FileSourceFetcher serves sources from a local directory: a URL maps to
+// Root/<host>/<path>, with an optional .html or .txt extension. Files outside
+// Root (through ".." or symlinks) are never read.
+type FileSourceFetcher struct {
+	Root string
+}
+
+func (f *FileSourceFetcher) Fetch(ctx context.Context, rawURL string) (*SourceDocument, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source URL: %w", err)
+	}
+	base := filepath.Join(u.Host, filepath.FromSlash(strings.TrimSuffix(u.Path, "/")))
+	if !filepath.IsLocal(base) {
+		return nil, fmt.Errorf("source %s is outside %s", rawURL, f.Root)
+	}
+
+	for _, ext := range []string{"", ".html", ".txt"} {
+		body, err := readFileInRoot(f.Root, base+ext)
+		if err != nil {
+			continue
+		}
+		mediaType := "text/plain"
+		if strings.HasSuffix(base+ext, ".html") {
+			mediaType = "text/html"
+		}
+		return parseSourceDocument(rawURL, mediaType, body)
+	}
+	return nil, fmt.Errorf("source %s not found under %s", rawURL, f.Root)
+}
+
+// readFileInRoot reads the file at the local path name under root.
+func readFileInRoot(root, name string) ([]byte, error) {
+	file, err := os.OpenInRoot(root, name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(io.LimitReader(file, sourceMaxBytes))
+}
+
+// parseSourceDocument extracts the text (and, for HTML, the title) of a
+// fetched source.
+func parseSourceDocument(rawURL, mediaType string, body []byte) (*SourceDocument, error) {
+	doc := &SourceDocument{URL: rawURL}
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		doc.Title, doc.Text = htmlText(string(body))
+	case "text/plain", "text/markdown":
+		doc.Text = string(body)
+	default:
+		return nil, fmt.Errorf("unsupported source content type %q", mediaType)
+	}
+	if strings.TrimSpace(doc.Text) == "" {
+		return nil, fmt.Errorf("source has no text")
+	}
+	return doc, nil
+}
+
+// htmlBlockTags end a paragraph of extracted text.
+var htmlBlockTags = map[string]bool{
+	"p": true, "div": true, "li": true, "br": true, "tr": true, "section": true, "article": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "blockquote": true, "pre": true,
+}
+
+// htmlSkipTags hold no readable text.
+var htmlSkipTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "nav": true, "header": true, "footer": true,
+	"aside": true, "form": true, "svg": true, "template": true,
+}
+
+// htmlText returns an HTML page's title and its readable text, one
+// paragraph per block element.
+func htmlText(page string) (string, string) {
+	var (
+		title string
+		sb    strings.Builder
+		skip  int
+		tag   string
+	)
+	z := html.NewTokenizer(strings.NewReader(page))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return strings.TrimSpace(title), sb.String()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := z.TagName()
+			tag = string(name)
+			if htmlSkipTags[tag] && tt == html.StartTagToken {
+				skip++
+			}
+			if htmlBlockTags[tag] {
+				sb.WriteString("\n\n")
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if htmlSkipTags[string(name)] && skip > 0 {
+				skip--
+			}
+			if htmlBlockTags[string(name)] {
+				sb.WriteString("\n\n")
+			}
+			tag = ""
+		case html.TextToken:
+			text := string(z.Text())
+			switch {
+			case tag == "title":
+				title += text
+			case skip == 0:
+				sb.WriteString(text)
+			}
+		}
+	}
+}
+
+// SourceSnippet is a passage of a source the planner can cite.
+type SourceSnippet struct {
+	ID     string // "S{source}.{n}"
+	Source int    // 1-based position in the project's sources
+	Title  string
+	URL    string
+	Text   string
+}
+
+// Research fetches the project's sources and splits them into the snippets
+// shown to the planner. Sources that can't be fetched are logged and left
+// out; it fails only when none can be used.
+func Research(ctx context.Context, fetcher SourceFetcher, topic string, sources []models.ResearchSource) ([]SourceSnippet, error) {
+	docs := make([]*SourceDocument, len(sources))
+	var errs []string
+	for i, source := range sources {
+		doc := &SourceDocument{URL: source.URL, Text: source.Text}
+		if source.Text == "" {
+			fetched, err := fetcher.Fetch(ctx, source.URL)
+			if err != nil {
+				joblog.Warnf(ctx, "[Research] source %d (%s) unusable: %v", i+1, source.URL, err)
+				errs = append(errs, fmt.Sprintf("source %d: %v", i+1, err))
+				continue
+			}
+			doc = fetched
+		}
+		if source.Title != "" {
+			doc.Title = source.Title
+		}
+		if doc.Title == "" {
+			doc.Title = sourceTitle(doc.URL, i+1)
+		}
+		docs[i] = doc
+	}
+
+	snippets := BuildSnippets(topic, docs, researchMaxChars)
+	if len(snippets) == 0 {
+		return nil, fmt.Errorf("no usable research sources: %s", strings.Join(errs, "; "))
+	}
+	joblog.Printf(ctx, "[Research] %d snippets from %d of %d sources", len(snippets), len(sources)-len(errs), len(sources))
+	return snippets, nil
+}
+
+// sourceTitle names an untitled source after its host.
+func sourceTitle(rawURL string, n int) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return strings.TrimPrefix(u.Host, "www.")
+	}
+	return fmt.Sprintf("Source %d", n)
+}
+
+// BuildSnippets splits docs (nil entries are skipped, numbering is kept)
+// into snippets. When their text exceeds maxChars, the snippets sharing the
+// most words with the topic are kept, in document order.
+func BuildSnippets(topic string, docs []*SourceDocument, maxChars int) []SourceSnippet {
+	var snippets []SourceSnippet
+	total := 0
+	for i, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		for n, text := range splitPassages(doc.Text) {
+			snippets = append(snippets, SourceSnippet{
+				ID:     fmt.Sprintf("S%d.%d", i+1, n+1),
+				Source: i + 1,
+				Title:  doc.Title,
+				URL:    doc.URL,
+				Text:   text,
+			})
+			total += len(text)
+		}
+	}
+	if total <= maxChars {
+		return snippets
+	}
+
+	topicWords := make(map[string]bool)
+	for _, word := range normalizedWords(topic) {
+		if len(word) > 3 {
+			topicWords[word] = true
+		}
+	}
+	score := func(s SourceSnippet) int {
+		seen := make(map[string]bool)
+		for _, word := range normalizedWords(s.Text) {
+			if topicWords[word] {
+				seen[word] = true
+			}
+		}
+		return len(seen)
+	}
+
+	order := make([]int, len(snippets))
+	scores := make([]int, len(snippets))
+	for i := range snippets {
+		order[i] = i
+		scores[i] = score(snippets[i])
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	keep := make([]bool, len(snippets))
+	used := 0
+	for _, i := range order {
+		if used+len(snippets[i].Text) > maxChars {
+			continue
+		}
+		keep[i] = true
+		used += len(snippets[i].Text)
+	}
+
+	kept := snippets[:0]
+	for i, snippet := range snippets {
+		if keep[i] {
+			kept = append(kept, snippet)
+		}
+	}
+	return kept
+}
+
+var (
+	paragraphBreak = regexp.MustCompile(`\n\s*\n`)
+	sentenceEnd    = regexp.MustCompile(`[.!?]["')\]]?\s+`)
+)
+
+// splitPassages splits text into passages of about snippetMinChars to
+// snippetMaxChars: paragraphs, short ones joined and long ones split at
+// sentence ends.
+func splitPassages(text string) []string {
+	var passages []string
+	var current string
+	flush := func() {
+		if current != "" {
+			passages = append(passages, current)
+			current = ""
+		}
+	}
+	add := func(part string) {
+		if current != "" && len(current)+1+len(part) > snippetMaxChars {
+			flush()
+		}
+		if current != "" {
+			current += " "
+		}
+		current += part
+		if len(current) >= snippetMinChars {
+			flush()
+		}
+	}
+
+	for _, paragraph := range paragraphBreak.Split(text, -1) {
+		paragraph = strings.Join(strings.Fields(paragraph), " ")
+		if paragraph == "" {
+			continue
+		}
+		if len(paragraph) <= snippetMaxChars {
+			add(paragraph)
+			continue
+		}
+		start := 0
+		for _, loc := range sentenceEnd.FindAllStringIndex(paragraph, -1) {
+			if loc[1]-start > snippetMaxChars/2 {
+				add(strings.TrimSpace(paragraph[start:loc[1]]))
+				start = loc[1]
+			}
+		}
+		if rest := strings.TrimSpace(paragraph[start:]); rest != "" {
+			add(rest)
+		}
+	}
+	flush()
+	return passages
+}
+
+// CitationsFor resolves the snippet IDs a clip cites into citations,
+// dropping unknown and repeated IDs.
+func CitationsFor(ids []string, snippets []SourceSnippet) models.Citations {
+	byID := make(map[string]SourceSnippet, len(snippets))
+	for _, snippet := range snippets {
+		byID[snippet.ID] = snippet
+	}
+
+	var citations models.Citations
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		snippet, ok := byID[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		citations = append(citations, models.Citation{
+			Source:  snippet.Source,
+			Title:   snippet.Title,
+			URL:     snippet.URL,
+			Snippet: snippet.Text,
+		})
+	}
+	return citations
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bobarin/episod/internal/models"
+)
+
+const lakePage = `<html><head><title>Lake Vostok</title><script>var tracking = 1;</script></head>
+<body><nav>Home | About</nav>
+<h1>Lake Vostok</h1>
+<p>Lake Vostok is the largest of Antarctica's subglacial lakes, buried under nearly four kilometres of ice.</p>
+<p>Scientists believe the lake has been sealed off from the atmosphere for about fifteen million years, and drilling reached its surface in 2012.</p>
+<footer>Copyright</footer></body></html>`
+
+// writeSource stores a source file under root for FileSourceFetcher.
+func writeSource(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileSourceFetcher(t *testing.T) {
+	root := t.TempDir()
+	writeSource(t, root, "example.com/lakes/vostok.html", lakePage)
+	writeSource(t, root, "example.org/notes.txt", "Plain notes.")
+	fetcher := &FileSourceFetcher{Root: root}
+
+	doc, err := fetcher.Fetch(context.Background(), "https://example.com/lakes/vostok")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if doc.Title != "Lake Vostok" {
+		t.Errorf("title = %q, want %q", doc.Title, "Lake Vostok")
+	}
+	for _, unwanted := range []string{"tracking", "Home | About", "Copyright"} {
+		if strings.Contains(doc.Text, unwanted) {
+			t.Errorf("text contains %q: %q", unwanted, doc.Text)
+		}
+	}
+	if !strings.Contains(doc.Text, "fifteen million years") {
+		t.Errorf("text is missing the article body: %q", doc.Text)
+	}
+
+	if doc, err := fetcher.Fetch(context.Background(), "https://example.org/notes"); err != nil || doc.Text != "Plain notes." {
+		t.Errorf("Fetch(notes) = %+v, %v", doc, err)
+	}
+	if _, err := fetcher.Fetch(context.Background(), "https://example.com/missing"); err == nil {
+		t.Error("Fetch of a missing source succeeded")
+	}
+}
+
+func TestFileSourceFetcherStaysInRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "sources")
+	writeSource(t, dir, "secret.txt", "Outside the source directory.")
+	writeSource(t, root, "example.com/page.txt", "Inside.")
+	if err := os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(root, "example.com", "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	fetcher := &FileSourceFetcher{Root: root}
+
+	for _, rawURL := range []string{
+		"http://example.com/../../secret",
+		"http://example.com/%2e%2e/%2e%2e/secret",
+		"http://../secret",
+		"http://example.com/link",
+	} {
+		if doc, err := fetcher.Fetch(context.Background(), rawURL); err == nil {
+			t.Errorf("Fetch(%s) read %q outside the root", rawURL, doc.Text)
+		}
+	}
+	if _, err := fetcher.Fetch(context.Background(), "http://example.com/page"); err != nil {
+		t.Errorf("Fetch inside the root: %v", err)
+	}
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.215.14":          true,
+		"2606:4700:4700::1111":   true,
+		"127.0.0.1":              false,
+		"::1":                    false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"fd00::1":                false,
+		"169.254.169.254":        false,
+		"fe80::1":                false,
+		"0.0.0.0":                false,
+		"::":                     false,
+		"224.0.0.1":              false,
+		"100.64.0.1":             false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+	} {
+		if got := IsPublicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("IsPublicAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestHTTPSourceFetcherRefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPSourceFetcher()
+	for _, rawURL := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+		if _, err := fetcher.Fetch(context.Background(), rawURL); err == nil || !strings.Contains(err.Error(), "is not public") {
+			t.Errorf("Fetch(%s) error = %v, want a refused address", rawURL, err)
+		}
+	}
+}
+
+func TestResearch(t *testing.T) {
+	root := t.TempDir()
+	writeSource(t, root, "example.com/lakes/vostok.html", lakePage)
+
+	snippets, err := Research(context.Background(), &FileSourceFetcher{Root: root}, "Lake Vostok", []models.ResearchSource{
+		{URL: "https://example.com/lakes/vostok"},
+		{URL: "https://example.com/missing"},
+		{Title: "Field notes", Text: "The ice above the lake is about 3,700 metres thick."},
+	})
+	if err != nil {
+		t.Fatalf("Research: %v", err)
+	}
+
+	var ids, titles []string
+	for _, snippet := range snippets {
+		ids = append(ids, snippet.ID)
+		titles = append(titles, snippet.Title)
+	}
+	// Both paragraphs of the page are joined into one snippet; the missing
+	// source keeps its number
+	if got, want := strings.Join(ids, " "), "S1.1 S3.1"; got != want {
+		t.Errorf("snippet IDs = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(titles, ", "), "Lake Vostok, Field notes"; got != want {
+		t.Errorf("snippet titles = %q, want %q", got, want)
+	}
+
+	if _, err := Research(context.Background(), &FileSourceFetcher{Root: root}, "Lake Vostok", []models.ResearchSource{
+		{URL: "https://example.com/missing"},
+	}); err == nil {
+		t.Error("Research without usable sources succeeded")
+	}
+}
+
+func TestSplitPassages(t *testing.T) {
+	long := strings.Repeat("This sentence is exactly fifty characters long ok. ", 20)
+	passages := splitPassages("Short one.\n\nShort two.\n\n" + long)
+
+	if passages[0] != "Short one. Short two. "+strings.TrimSpace(passages[0][len("Short one. Short two. "):]) {
+		t.Errorf("short paragraphs not joined: %q", passages[0])
+	}
+	for i, passage := range passages {
+		if len(passage) > snippetMaxChars {
+			t.Errorf("passage %d has %d chars, more than %d", i, len(passage), snippetMaxChars)
+		}
+	}
+	if got := strings.Join(strings.Fields(strings.Join(passages, " ")), " "); got != strings.Join(strings.Fields("Short one. Short two. "+long), " ") {
+		t.Error("passages lost text")
+	}
+}
+
+func TestBuildSnippetsBudget(t *testing.T) {
+	filler := strings.Repeat("Unrelated words about the weather in spring. ", 5)
+	relevant := strings.Repeat("Lake Vostok lies beneath the Antarctic ice sheet. ", 5)
+	docs := []*SourceDocument{
+		{Title: "A", Text: filler},
+		{Title: "B", Text: relevant},
+	}
+
+	snippets := BuildSnippets("Lake Vostok", docs, len(relevant))
+	if len(snippets) != 1 || snippets[0].ID != "S2.1" {
+		t.Errorf("got %+v, want only the snippet about the topic", snippets)
+	}
+	if all := BuildSnippets("Lake Vostok", docs, 10000); len(all) != 2 {
+		t.Errorf("got %d snippets within budget, want 2", len(all))
+	}
+}
+
+func TestCitationsFor(t *testing.T) {
+	snippets := []SourceSnippet{
+		{ID: "S1.1", Source: 1, Title: "Lake Vostok", URL: "https://example.com/vostok", Text: "First."},
+		{ID: "S2.3", Source: 2, Title: "Field notes", Text: "Second."},
+	}
+
+	citations := CitationsFor([]string{"S2.3", "S9.9", " S1.1", "S2.3"}, snippets)
+	if len(citations) != 2 {
+		t.Fatalf("got %d citations %+v, want 2", len(citations), citations)
+	}
+	if citations[0].Source != 2 || citations[0].Snippet != "Second." || citations[1].URL != "https://example.com/vostok" {
+		t.Errorf("citations = %+v", citations)
+	}
+	if CitationsFor(nil, snippets) != nil {
+		t.Error("no IDs should give no citations")
+	}
+}
@@ -18,6 +18,8 @@ import (
 //   - Dark outline on all words for readability on any background
 //   - Active word: thick purple border creating a "pill highlight" effect
 //   - Smooth transitions: each chunk appears/disappears as a group
+//   - Dialogue clips: each speaker's words get their own text color
+//     (narrator stays white), and chunks never mix speakers
 // ---------------------------------------------------------------------------
 
 const (
@@ -37,6 +39,15 @@ const (
 	assColorSemiBlack = "&H80000000" // 50% transparent black (for shadow)
 )
 
+// speakerTextColors are the text colors for non-narrator dialogue speakers,
+// assigned in SubtitleParams.Speakers order (BGR, like the colors above).
+var speakerTextColors = []string{
+	"&H0000D7FF", // #FFD700 gold
+	"&H00FFE600", // #00E6FF cyan
+	"&H009F7FFF", // #FF7F9F coral
+	"&H0000FC7C", // #7CFC00 lime
+}
+
 // SubtitleParams holds resolution-aware subtitle styling parameters.
 type SubtitleParams struct {
 	PlayResX         int
@@ -45,6 +56,26 @@ type SubtitleParams struct {
 	OutlineNormal    int
 	OutlineHighlight int
 	MarginV          int
+	Speakers         []string // Dialogue speaker roles, narrator first (see OrderSpeakers)
+}
+
+// speakerColor returns the ASS text color for a speaker, or "" for the
+// default white (the narrator, single-voice clips, and unknown roles).
+func (p SubtitleParams) speakerColor(speaker string) string {
+	if speaker == "" || speaker == NarratorSpeaker {
+		return ""
+	}
+	idx := 0
+	for _, s := range p.Speakers {
+		if s == NarratorSpeaker {
+			continue
+		}
+		if s == speaker {
+			return speakerTextColors[idx%len(speakerTextColors)]
+		}
+		idx++
+	}
+	return ""
 }
 
 // SubtitleParamsForResolution returns font sizes and margins scaled to the render resolution.
@@ -74,8 +105,25 @@ func SubtitleParamsForResolution(res RenderResolution) SubtitleParams {
 // The generated subtitles show words in chunks of ~4, with the active word
 // highlighted in purple. All text is bold, uppercase, centered at the bottom.
 func GenerateASSSubtitles(words []WordTimestamp, outputPath string, silenceOffsetSec float64, params SubtitleParams) error {
+	content, err := BuildASSSubtitles(words, silenceOffsetSec, params)
+	if err != nil {
+		return err
+	}
+
+	// Write to file
+	if err := os.WriteFile(outputPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write ASS subtitle file: %w", err)
+	}
+
+	return nil
+}
+
+// BuildASSSubtitles renders the TikTok-style ASS document for the given words
+// and returns it as a string. GenerateASSSubtitles writes the same content to disk;
+// the project-level sidecar export uploads it directly to storage.
+func BuildASSSubtitles(words []WordTimestamp, silenceOffsetSec float64, params SubtitleParams) (string, error) {
 	if len(words) == 0 {
-		return fmt.Errorf("no words to generate subtitles from")
+		return "", fmt.Errorf("no words to generate subtitles from")
 	}
 
 	// Group words into display chunks
@@ -131,7 +179,7 @@ func GenerateASSSubtitles(words []WordTimestamp, outputPath string, silenceOffse
 			}
 
 			// Build the display text with the active word highlighted
-			displayText := buildHighlightedChunkText(chunk, wordIdx, params.OutlineHighlight)
+			displayText := buildHighlightedChunkText(chunk, wordIdx, params.OutlineHighlight, params.speakerColor(chunk[0].Speaker))
 
 			// Write the dialogue line
 			sb.WriteString(fmt.Sprintf(
@@ -143,21 +191,21 @@ func GenerateASSSubtitles(words []WordTimestamp, outputPath string, silenceOffse
 		}
 	}
 
-	// Write to file
-	if err := os.WriteFile(outputPath, []byte(sb.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write ASS subtitle file: %w", err)
-	}
-
-	return nil
+	return sb.String(), nil
 }
 
 // chunkWords groups words into display chunks of the specified size.
-// It also breaks at sentence boundaries (., !, ?) to keep chunks natural.
+// It also breaks at sentence boundaries (., !, ?) to keep chunks natural,
+// and whenever the speaker changes so each chunk has a single color.
 func chunkWords(words []WordTimestamp, chunkSize int) [][]WordTimestamp {
 	var chunks [][]WordTimestamp
 	var current []WordTimestamp
 
 	for _, word := range words {
+		if len(current) > 0 && current[0].Speaker != word.Speaker {
+			chunks = append(chunks, current)
+			current = nil
+		}
 		current = append(current, word)
 
 		// Break chunk if we've reached the target size
@@ -179,9 +227,16 @@ func chunkWords(words []WordTimestamp, chunkSize int) [][]WordTimestamp {
 
 // buildHighlightedChunkText builds the ASS-formatted text for a chunk where
 // the word at activeIdx is highlighted with a purple pill background.
+// textColor overrides the text color of every word (dialogue speakers);
+// empty keeps the default white.
 //
 // Output example: "THE {\3c&H9932CC&\bord8}HISTORY{\r} OF COFFEE"
-func buildHighlightedChunkText(chunk []WordTimestamp, activeIdx int, outlineHighlight int) string {
+func buildHighlightedChunkText(chunk []WordTimestamp, activeIdx int, outlineHighlight int, textColor string) string {
+	colorTag := ""
+	if textColor != "" {
+		colorTag = "\\1c" + textColor
+	}
+
 	var parts []string
 
 	for i, word := range chunk {
@@ -195,9 +250,12 @@ func buildHighlightedChunkText(chunk []WordTimestamp, activeIdx int, outlineHigh
 			// \3c sets outline color, \bord sets outline thickness
 			// \r resets back to the default style after this word
 			parts = append(parts, fmt.Sprintf(
-				"{\\3c%s\\bord%d}%s{\\r}",
-				assColorPurple, outlineHighlight, cleanWord,
+				"{%s\\3c%s\\bord%d}%s{\\r}",
+				colorTag, assColorPurple, outlineHighlight, cleanWord,
 			))
+		} else if colorTag != "" {
+			// Dialogue word: speaker color, reset after so the next tag starts clean
+			parts = append(parts, fmt.Sprintf("{%s}%s{\\r}", colorTag, cleanWord))
 		} else {
 			// Normal word: just the text (default style applies: white + black outline)
 			parts = append(parts, cleanWord)
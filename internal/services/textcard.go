@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/bobarin/episod/internal/joblog"
+)
+
+// ---------------------------------------------------------------------------
+// Text cards — a styled still of the clip's script, the last-resort visual
+// for a clip whose image could not be generated, and the sources card that
+// ends research-mode videos
+// ---------------------------------------------------------------------------
+
+// Text card style: light text on a dark vignetted background
+const (
+	textCardBackground = "0x14141f"
+	textCardFont       = "Noto Sans" // Installed with the subtitle fonts (see Dockerfile)
+	textCardMaxLines   = 10
+)
+
+// RenderTextCard renders text centered on a dark background as a PNG at the
+// render resolution.
+func (s *FFmpegService) RenderTextCard(ctx context.Context, text, outputPath string) error {
+	fontSize := s.Resolution.Width / 16
+	// Average glyph width is ~0.55em; keep a 10% margin on both sides
+	lineChars := int(float64(s.Resolution.Width) * 0.8 / (float64(fontSize) * 0.55))
+	lines := WrapText(text, lineChars)
+	if len(lines) > textCardMaxLines {
+		lines = lines[:textCardMaxLines]
+		lines[textCardMaxLines-1] += "…"
+	}
+	return s.renderCard(ctx, lines, fontSize, outputPath)
+}
+
+// Sources card: up to sourcesCardMaxLines lines, smaller than the text card
+const sourcesCardMaxLines = 16
+
+// RenderSourcesCard renders a "Sources" card listing sources (one entry
+// each) as a PNG at the render resolution.
+func (s *FFmpegService) RenderSourcesCard(ctx context.Context, sources []string, outputPath string) error {
+	fontSize := s.Resolution.Width / 24
+	lineChars := int(float64(s.Resolution.Width) * 0.8 / (float64(fontSize) * 0.55))
+	lines := []string{"Sources", ""}
+	for _, source := range sources {
+		lines = append(lines, WrapText(source, lineChars)...)
+	}
+	if len(lines) > sourcesCardMaxLines {
+		lines = lines[:sourcesCardMaxLines]
+		lines[sourcesCardMaxLines-1] += "…"
+	}
+	return s.renderCard(ctx, lines, fontSize, outputPath)
+}
+
+// renderCard renders lines centered on the text card background.
+func (s *FFmpegService) renderCard(ctx context.Context, lines []string, fontSize int, outputPath string) error {
+	// drawtext reads the text from a file, so the script needs no escaping
+	textPath := outputPath + ".txt"
+	if err := os.WriteFile(textPath, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		return fmt.Errorf("failed to write text card text: %w", err)
+	}
+	defer os.Remove(textPath)
+
+	vf := fmt.Sprintf("vignette=PI/4,drawtext=font='%s':textfile='%s':fontcolor=white:fontsize=%d:line_spacing=%d:"+
+		"x=(w-text_w)/2:y=(h-text_h)/2:shadowcolor=black@0.6:shadowx=3:shadowy=3",
+		textCardFont, escapeFFmpegFilterPath(textPath), fontSize, fontSize/3)
+
+	args := []string{
+		"-f", "lavfi",
+		"-i", fmt.Sprintf("color=c=%s:s=%dx%d", textCardBackground, s.Resolution.Width, s.Resolution.Height),
+		"-vf", vf,
+		"-frames:v", "1",
+		"-y",
+		outputPath,
+	}
+
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = joblog.Stderr(ctx, "ffmpeg")
+
+	if err := runFFmpeg(ctx, cmd, "text_card"); err != nil {
+		return fmt.Errorf("ffmpeg text card failed: %w", err)
+	}
+
+	return nil
+}
+
+// WrapText breaks text into lines of at most width characters, at word
+// boundaries. Words longer than width get a line of their own.
+func WrapText(text string, width int) []string {
+	var lines []string
+	var line strings.Builder
+	for _, word := range strings.Fields(text) {
+		if line.Len() > 0 && len([]rune(line.String()))+1+len([]rune(word)) > width {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteByte(' ')
+		}
+		line.WriteString(word)
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
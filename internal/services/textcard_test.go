@@ -0,0 +1,25 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"The quick brown fox jumps over the lazy dog", 15, []string{"The quick brown", "fox jumps over", "the lazy dog"}},
+		{"  spaced \n out  ", 20, []string{"spaced out"}},
+		{"a supercalifragilistic word", 10, []string{"a", "supercalifragilistic", "word"}},
+		{"", 10, nil},
+	}
+
+	for _, tt := range tests {
+		if got := WrapText(tt.text, tt.width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WrapText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,16 @@
 package services
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bobarin/episod/internal/joblog"
+)
 
 // ---------------------------------------------------------------------------
 // TTSService — common interface for text-to-speech providers
@@ -11,15 +21,249 @@ import "context"
 // TTSResponse is the common response type from any TTS provider.
 type TTSResponse struct {
 	AudioData  []byte
-	DurationMs int
+	DurationMs int    // Measured from the audio bytes (estimated only if probing fails)
 	Format     string // "mp3", "wav", etc.
+	SampleRate int    // Hz, 0 if unknown
+	Channels   int    // 1 = mono, 2 = stereo, 0 if unknown
+
+	// DurationEstimated is true when DurationMs fell back to the word-count
+	// estimate because the audio could not be probed.
+	DurationEstimated bool
+
+	// WordTimestamps holds provider-supplied word timings for the synthesized text,
+	// when the provider returns them (e.g. ElevenLabs with-timestamps).
+	// nil means the caller must transcribe the audio to get subtitle timings.
+	WordTimestamps []WordTimestamp
+
+	// Fallback is true when a fallback provider of a ChainTTSService served
+	// the request, in its own default voice.
+	Fallback bool
+}
+
+// SpeechRequest describes one synthesis call.
+type SpeechRequest struct {
+	// Text to speak. May contain script markup ([pause], *emphasis*, [spell:X]),
+	// which each provider renders with its native features.
+	Text string
+	// VoiceStyle is a human-readable description of the desired delivery style
+	// (e.g., "slow, mysterious, and low-pitched"). The provider may or may not use it.
+	VoiceStyle string
+	// VoiceID is an optional per-request voice override. Empty uses the service default
+	// (or the provider's default voice for the language, if one is configured).
+	VoiceID string
+	// Language is the ISO 639-1 code of the text ("es", "fr", ...). Empty means English.
+	Language string
+	// Lexicon holds pronunciation rules for names and jargon in Text.
+	Lexicon Lexicon
+	// Settings are the project's base voice settings; nil uses provider defaults.
+	// VoiceStyle adjusts them per request.
+	Settings *VoiceSettings
+	// Pace scales the speaking speed on top of Settings and VoiceStyle, e.g.
+	// 1.05 to narrate 5% faster when fitting a video to its target length.
+	// 0 means unchanged.
+	Pace float64
+}
+
+// paceFactor returns the request's Pace, or 1 when unset.
+func (r SpeechRequest) paceFactor() float64 {
+	if r.Pace <= 0 {
+		return 1
+	}
+	return r.Pace
 }
 
 // TTSService is the interface that any TTS provider must implement.
 type TTSService interface {
-	// GenerateSpeech converts text to audio using the provider's default settings.
-	// voiceStyle is a human-readable description of the desired delivery style
-	// (e.g., "slow, mysterious, and low-pitched"). The provider may or may not use it.
-	// voiceID is an optional per-request voice override. Pass "" to use the service default.
-	GenerateSpeech(ctx context.Context, text, voiceStyle, voiceID string) (*TTSResponse, error)
+	// GenerateSpeech converts req.Text to audio using the provider's default settings.
+	GenerateSpeech(ctx context.Context, req SpeechRequest) (*TTSResponse, error)
+}
+
+// TTSModelNamer is implemented by TTS services that can name the model(s)
+// they synthesize with, so cached audio is not reused across model changes.
+type TTSModelNamer interface {
+	ModelName() string
+}
+
+// TTSModelName returns the model name of a TTS service, or "" if it can't tell.
+func TTSModelName(tts TTSService) string {
+	if namer, ok := tts.(TTSModelNamer); ok {
+		return namer.ModelName()
+	}
+	return ""
+}
+
+// ErrTTSInvalidRequest marks provider errors caused by the request itself
+// (unsupported language, voice/language mismatch). Retrying the same request,
+// or counting it against the provider's health, is pointless.
+var ErrTTSInvalidRequest = errors.New("invalid TTS request")
+
+// ErrTTSLanguageMismatch is the ErrTTSInvalidRequest of a provider that
+// doesn't speak the request's language, or whose voice doesn't. Another
+// provider may, so an unpinned chain moves on to it.
+var ErrTTSLanguageMismatch = fmt.Errorf("%w: language mismatch", ErrTTSInvalidRequest)
+
+// normalizeTTSLanguage lowercases a language tag and keeps the primary subtag
+// ("pt-BR" → "pt"). Empty means English.
+func normalizeTTSLanguage(language string) string {
+	lang := strings.ToLower(strings.TrimSpace(language))
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	if lang == "" {
+		return "en"
+	}
+	return lang
+}
+
+// ParseLanguageVoices parses a per-language default voice map from config,
+// e.g. "es=abc123,fr=def456". Malformed entries are skipped.
+func ParseLanguageVoices(s string) map[string]string {
+	voices := make(map[string]string)
+	for _, entry := range strings.Split(s, ",") {
+		lang, voiceID, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || strings.TrimSpace(lang) == "" || strings.TrimSpace(voiceID) == "" {
+			continue
+		}
+		voices[normalizeTTSLanguage(lang)] = strings.TrimSpace(voiceID)
+	}
+	return voices
+}
+
+// VoiceLanguagePolicy decides what happens when the chosen voice doesn't
+// support the requested language.
+type VoiceLanguagePolicy string
+
+const (
+	// VoiceLanguageWarn logs the mismatch and synthesizes anyway (default).
+	VoiceLanguageWarn VoiceLanguagePolicy = "warn"
+	// VoiceLanguageReject fails the request with ErrTTSInvalidRequest.
+	VoiceLanguageReject VoiceLanguagePolicy = "reject"
+)
+
+// ParseVoiceLanguagePolicy converts a config string into a VoiceLanguagePolicy.
+// Unknown values fall back to VoiceLanguageWarn.
+func ParseVoiceLanguagePolicy(s string) VoiceLanguagePolicy {
+	if VoiceLanguagePolicy(strings.ToLower(strings.TrimSpace(s))) == VoiceLanguageReject {
+		return VoiceLanguageReject
+	}
+	return VoiceLanguageWarn
+}
+
+// voiceLookupRetry is how long a failed voice language lookup is cached
+// before the voice is looked up again.
+const voiceLookupRetry = 10 * time.Minute
+
+// voiceLanguageCheck applies a VoiceLanguagePolicy for a provider, caching
+// the languages each voice speaks. Failed lookups are cached too (for
+// voiceLookupRetry), so an unreachable voice endpoint costs one request per
+// voice rather than one per clip.
+type voiceLanguageCheck struct {
+	provider string // Provider name in log and error messages
+	policy   VoiceLanguagePolicy
+
+	mu     sync.Mutex
+	voices map[string]voiceLanguages
+}
+
+// voiceLanguages is a cached lookup: the languages a voice speaks (empty
+// when unknown), or when the lookup failed.
+type voiceLanguages struct {
+	languages []string
+	failedAt  time.Time
+}
+
+// check compares the languages of voiceID, fetched with lookup on first use,
+// with language. A mismatch is logged, or rejected with ErrTTSInvalidRequest
+// under VoiceLanguageReject. If the voice can't be looked up, synthesis
+// proceeds.
+func (c *voiceLanguageCheck) check(ctx context.Context, voiceID, language string, lookup func(context.Context, string) ([]string, error)) error {
+	c.mu.Lock()
+	cached, ok := c.voices[voiceID]
+	c.mu.Unlock()
+
+	if !ok || (!cached.failedAt.IsZero() && time.Since(cached.failedAt) > voiceLookupRetry) {
+		languages, err := lookup(ctx, voiceID)
+		if err != nil {
+			joblog.Printf(ctx, "[%s] Could not look up language of voice %s: %v", c.provider, voiceID, err)
+			if ctx.Err() != nil {
+				return nil
+			}
+			cached = voiceLanguages{failedAt: time.Now()}
+		} else {
+			cached = voiceLanguages{languages: languages}
+		}
+		c.mu.Lock()
+		if c.voices == nil {
+			c.voices = make(map[string]voiceLanguages)
+		}
+		c.voices[voiceID] = cached
+		c.mu.Unlock()
+	}
+
+	if len(cached.languages) == 0 {
+		return nil // Unknown — no check possible
+	}
+	for _, lang := range cached.languages {
+		if lang == language {
+			return nil
+		}
+	}
+
+	spoken := strings.Join(cached.languages, ", ")
+	if c.policy == VoiceLanguageReject {
+		return fmt.Errorf("%w: %s voice %s speaks %q, not %q", ErrTTSLanguageMismatch, c.provider, voiceID, spoken, language)
+	}
+	joblog.Warnf(ctx, "[%s] voice %s speaks %q but the text is %q — expect an accent", c.provider, voiceID, spoken, language)
+	return nil
+}
+
+// TTS provider names, used in config (TTS_PROVIDERS) and per-project selection.
+const (
+	TTSProviderElevenLabs = "elevenlabs"
+	TTSProviderCartesia   = "cartesia"
+)
+
+// IsKnownTTSProvider reports whether name is a supported TTS provider.
+func IsKnownTTSProvider(name string) bool {
+	return name == TTSProviderElevenLabs || name == TTSProviderCartesia
+}
+
+// TTSStatusError is returned when a TTS provider answers with a non-200 status.
+type TTSStatusError struct {
+	Provider   string
+	StatusCode int
+	Body       string
+}
+
+func (e *TTSStatusError) Error() string {
+	return fmt.Sprintf("%s returned status %d: %s", e.Provider, e.StatusCode, e.Body)
+}
+
+// Transient reports whether the failure is worth retrying on another provider:
+// rate limiting (429) or a server-side error (5xx).
+func (e *TTSStatusError) Transient() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// newTTSResponse builds a TTSResponse with duration and stream info measured
+// from the audio bytes. If the audio can't be probed, it falls back to the
+// word-count estimate and flags the duration as estimated.
+func newTTSResponse(ctx context.Context, audioData []byte, format, text string, speed float64) *TTSResponse {
+	resp := &TTSResponse{
+		AudioData: audioData,
+		Format:    format,
+	}
+
+	info, err := ProbeAudio(audioData, format)
+	if err != nil {
+		joblog.Warnf(ctx, "[TTS] Could not measure audio duration, estimating from text: %v", err)
+		resp.DurationMs = estimateAudioDuration(text, speed)
+		resp.DurationEstimated = true
+		return resp
+	}
+
+	resp.DurationMs = info.DurationMs
+	resp.SampleRate = info.SampleRate
+	resp.Channels = info.Channels
+	return resp
 }
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/metrics"
+)
+
+// ---------------------------------------------------------------------------
+// TTS Fallback Chain — several providers behind one TTSService
+//
+// ChainTTSService tries providers in the configured order (TTS_PROVIDERS).
+// Transient failures (429, 5xx, network errors) move on to the next provider,
+// and so does a provider or voice that doesn't speak the request's language
+// (ErrTTSLanguageMismatch, not held against the provider's breaker); any other
+// error (bad voice ID, invalid request) is returned immediately, since another
+// provider would not fix it.
+//
+// Each provider has a circuit breaker: after breakerThreshold consecutive
+// transient failures it is skipped for breakerCooldown, then a single trial
+// request decides whether it closes again.
+//
+// Voice IDs are provider-specific. An unpinned request sends voiceID only to
+// the first provider in the chain; fallbacks use their own default voice.
+// Projects that pin a provider (WithProvider) never fall back, so their voice
+// IDs only ever reach the provider they belong to. Dialogue clips map each
+// speaker to a voice of the first provider and use only that one (Primary):
+// falling back would voice every speaker with the same default voice.
+// ---------------------------------------------------------------------------
+
+// NamedTTSService pairs a provider name with its TTSService.
+type NamedTTSService struct {
+	Name    string
+	Service TTSService
+}
+
+// TTSProviderSelector is implemented by TTS services that front several
+// providers and can be narrowed to one of them.
+type TTSProviderSelector interface {
+	// WithProvider returns a TTSService that only uses the named provider.
+	WithProvider(name string) (TTSService, error)
+}
+
+// ChainTTSService implements TTSService over an ordered list of providers.
+type ChainTTSService struct {
+	providers []*chainProvider
+}
+
+// Ensure ChainTTSService implements TTSService and TTSProviderSelector at compile time.
+var (
+	_ TTSService          = (*ChainTTSService)(nil)
+	_ TTSProviderSelector = (*ChainTTSService)(nil)
+)
+
+// NewChainTTSService creates a fallback chain. threshold is the number of
+// consecutive transient failures that opens a provider's breaker; cooldown is
+// how long it stays open.
+func NewChainTTSService(providers []NamedTTSService, threshold int, cooldown time.Duration) *ChainTTSService {
+	if threshold < 1 {
+		threshold = 1
+	}
+	chain := &ChainTTSService{}
+	for _, p := range providers {
+		chain.providers = append(chain.providers, &chainProvider{
+			NamedTTSService: p,
+			breaker:         &circuitBreaker{threshold: threshold, cooldown: cooldown},
+		})
+	}
+	return chain
+}
+
+// Providers returns the provider names in fallback order.
+func (c *ChainTTSService) Providers() []string {
+	names := make([]string, len(c.providers))
+	for i, p := range c.providers {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// GenerateSpeech tries each provider in order until one succeeds.
+func (c *ChainTTSService) GenerateSpeech(ctx context.Context, req SpeechRequest) (*TTSResponse, error) {
+	var lastErr error
+	for i, p := range c.providers {
+		if !p.breaker.allow() {
+			joblog.Printf(ctx, "[TTS] %s circuit open, skipping", p.Name)
+			continue
+		}
+
+		// The voice ID belongs to the primary provider — fallbacks use their default voice
+		providerReq := req
+		if i > 0 {
+			providerReq.VoiceID = ""
+		}
+
+		resp, err := p.generate(ctx, providerReq)
+		if err == nil {
+			resp.Fallback = i > 0
+			return resp, nil
+		}
+		lastErr = err
+		if errors.Is(err, ErrTTSLanguageMismatch) && ctx.Err() == nil {
+			joblog.Printf(ctx, "[TTS] %s can't voice this request (%v), trying next provider", p.Name, err)
+			continue
+		}
+		if !isTransientTTSError(ctx, err) {
+			return nil, err
+		}
+
+		joblog.Warnf(ctx, "[TTS] %s failed (%v), trying next provider", p.Name, err)
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("no TTS provider available (all circuits open)")
+	}
+	return nil, fmt.Errorf("all TTS providers failed: %w", lastErr)
+}
+
+// ModelName lists each provider's model in fallback order. Implements TTSModelNamer.
+func (c *ChainTTSService) ModelName() string {
+	names := make([]string, len(c.providers))
+	for i, p := range c.providers {
+		names[i] = p.ModelName()
+	}
+	return strings.Join(names, ",")
+}
+
+// Primary returns a TTSService bound to the first provider of the chain, as
+// WithProvider would.
+func (c *ChainTTSService) Primary() TTSService {
+	if len(c.providers) == 0 {
+		return c
+	}
+	return c.providers[0]
+}
+
+// WithProvider returns a TTSService bound to one provider of the chain.
+// It shares that provider's circuit breaker but never falls back.
+func (c *ChainTTSService) WithProvider(name string) (TTSService, error) {
+	for _, p := range c.providers {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("TTS provider %q is not configured (available: %v)", name, c.Providers())
+}
+
+// chainProvider is one provider in the chain with its circuit breaker.
+// It implements TTSService so WithProvider can hand it out directly.
+type chainProvider struct {
+	NamedTTSService
+	breaker *circuitBreaker
+}
+
+func (p *chainProvider) GenerateSpeech(ctx context.Context, req SpeechRequest) (*TTSResponse, error) {
+	if !p.breaker.allow() {
+		return nil, fmt.Errorf("TTS provider %s is temporarily unavailable (circuit open)", p.Name)
+	}
+	return p.generate(ctx, req)
+}
+
+// ModelName returns "provider:model". Implements TTSModelNamer.
+func (p *chainProvider) ModelName() string {
+	return p.Name + ":" + TTSModelName(p.Service)
+}
+
+// generate calls the provider and records the outcome on its breaker and in
+// the provider metrics.
+func (p *chainProvider) generate(ctx context.Context, req SpeechRequest) (*TTSResponse, error) {
+	start := time.Now()
+	resp, err := p.Service.GenerateSpeech(ctx, req)
+	metrics.ObserveProviderCall(p.Name, "speech", start, &err)
+
+	var statusErr *TTSStatusError
+	switch {
+	case err == nil:
+		p.breaker.success()
+	case ctx.Err() != nil:
+		// Cut short by the caller (shutdown, timeout): no verdict on the provider
+		p.breaker.release()
+	case isTransientTTSError(ctx, err):
+		if p.breaker.failure() {
+			joblog.Printf(ctx, "[TTS] %s circuit opened after %d consecutive failures", p.Name, p.breaker.threshold)
+		}
+	case errors.As(err, &statusErr):
+		// The provider answered; a request-specific error says nothing against its health
+		p.breaker.success()
+	default:
+		// Rejected before reaching the provider, or an answer that couldn't
+		// be read: no verdict on the provider's health
+		p.breaker.release()
+	}
+	return resp, err
+}
+
+// isTransientTTSError reports whether a provider error is worth a fallback:
+// 429/5xx responses and network errors. Cancellation of the caller's own
+// context, ErrTTSInvalidRequest and failures to decode a response (a bug
+// another provider wouldn't hide) are not transient.
+func isTransientTTSError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrTTSInvalidRequest) {
+		return false
+	}
+	var statusErr *TTSStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Transient()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// circuitBreaker counts consecutive failures and opens for cooldown once
+// threshold is reached. After the cooldown one trial request is let through
+// (half-open); its outcome closes the breaker or re-opens it.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool // a half-open trial request is in flight
+}
+
+// allow reports whether a request may be sent.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if time.Now().Before(b.openUntil) || b.trial {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// success closes the breaker.
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.trial = false
+}
+
+// release ends a half-open trial without a verdict, leaving the failure count
+// as it was so the next request becomes the trial.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// failure records a transient failure. Returns true if this opened the breaker.
+func (b *circuitBreaker) failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+		return b.failures == b.threshold
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeTTS returns err (if set) and records the voice IDs it was called with.
+type fakeTTS struct {
+	err    error
+	calls  int
+	voices []string
+}
+
+func (f *fakeTTS) GenerateSpeech(ctx context.Context, req SpeechRequest) (*TTSResponse, error) {
+	f.calls++
+	f.voices = append(f.voices, req.VoiceID)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &TTSResponse{AudioData: []byte("audio"), Format: "mp3"}, nil
+}
+
+func TestChainTTSFallsBackOnTransientErrors(t *testing.T) {
+	primary := &fakeTTS{err: &TTSStatusError{Provider: TTSProviderElevenLabs, StatusCode: 429}}
+	backup := &fakeTTS{}
+	chain := NewChainTTSService([]NamedTTSService{
+		{Name: TTSProviderElevenLabs, Service: primary},
+		{Name: TTSProviderCartesia, Service: backup},
+	}, 2, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		resp, err := chain.GenerateSpeech(context.Background(), SpeechRequest{Text: "hi", VoiceID: "el-voice", Language: "en"})
+		if err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+		if !resp.Fallback {
+			t.Errorf("call %d: response not marked as served by a fallback", i)
+		}
+	}
+
+	if primary.calls != 2 {
+		t.Errorf("primary should be skipped once its breaker opens, got %d calls", primary.calls)
+	}
+	if backup.calls != 3 {
+		t.Errorf("backup should serve every call, got %d", backup.calls)
+	}
+	for _, v := range backup.voices {
+		if v != "" {
+			t.Errorf("fallback provider received the primary's voice ID %q", v)
+		}
+	}
+}
+
+func TestChainTTSReturnsRequestErrors(t *testing.T) {
+	primary := &fakeTTS{err: &TTSStatusError{Provider: TTSProviderElevenLabs, StatusCode: 400}}
+	backup := &fakeTTS{}
+	chain := NewChainTTSService([]NamedTTSService{
+		{Name: TTSProviderElevenLabs, Service: primary},
+		{Name: TTSProviderCartesia, Service: backup},
+	}, 2, time.Minute)
+
+	if _, err := chain.GenerateSpeech(context.Background(), SpeechRequest{Text: "hi", VoiceID: "bad-voice", Language: "en"}); err == nil {
+		t.Fatal("expected the 400 to be returned")
+	}
+	if backup.calls != 0 {
+		t.Error("a 400 should not fall through to the next provider")
+	}
+
+	pinned, err := chain.WithProvider(TTSProviderCartesia)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pinned.GenerateSpeech(context.Background(), SpeechRequest{Text: "hi", VoiceID: "ca-voice", Language: "en"}); err != nil || backup.voices[0] != "ca-voice" {
+		t.Errorf("pinned provider should get the voice ID, err=%v voices=%v", err, backup.voices)
+	}
+	if _, err := chain.WithProvider("unknown"); err == nil {
+		t.Error("expected an error for an unconfigured provider")
+	}
+}
+
+func TestChainTTSSkipsProvidersWithoutTheLanguage(t *testing.T) {
+	primary := &fakeTTS{err: fmt.Errorf("%w: Cartesia does not support language %q", ErrTTSLanguageMismatch, "sw")}
+	backup := &fakeTTS{}
+	chain := NewChainTTSService([]NamedTTSService{
+		{Name: TTSProviderCartesia, Service: primary},
+		{Name: TTSProviderElevenLabs, Service: backup},
+	}, 1, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if _, err := chain.GenerateSpeech(context.Background(), SpeechRequest{Text: "habari", Language: "sw"}); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if primary.calls != 2 || backup.calls != 2 {
+		t.Errorf("got %d primary and %d backup calls, want 2 each (breaker untouched)", primary.calls, backup.calls)
+	}
+
+	pinned, _ := chain.WithProvider(TTSProviderCartesia)
+	if _, err := pinned.GenerateSpeech(context.Background(), SpeechRequest{Text: "habari", Language: "sw"}); !errors.Is(err, ErrTTSInvalidRequest) {
+		t.Errorf("pinned provider error = %v, want an invalid request", err)
+	}
+}
+
+func TestChainTTSPrimaryNeverFallsBack(t *testing.T) {
+	primary := &fakeTTS{err: &TTSStatusError{Provider: TTSProviderElevenLabs, StatusCode: 503}}
+	backup := &fakeTTS{}
+	chain := NewChainTTSService([]NamedTTSService{
+		{Name: TTSProviderElevenLabs, Service: primary},
+		{Name: TTSProviderCartesia, Service: backup},
+	}, 2, time.Minute)
+
+	if _, err := chain.Primary().GenerateSpeech(context.Background(), SpeechRequest{Text: "hi", VoiceID: "el-voice"}); err == nil {
+		t.Fatal("expected the primary's failure to be returned")
+	}
+	if backup.calls != 0 {
+		t.Error("the primary alone should not fall back")
+	}
+}
+
+func TestChainTTSCancelledTrialKeepsBreakerOpen(t *testing.T) {
+	primary := &fakeTTS{err: &TTSStatusError{Provider: TTSProviderElevenLabs, StatusCode: 503}}
+	chain := NewChainTTSService([]NamedTTSService{{Name: TTSProviderElevenLabs, Service: primary}}, 1, 0)
+	pinned, _ := chain.WithProvider(TTSProviderElevenLabs)
+
+	// Opens the breaker; with no cooldown the next request is the half-open trial
+	pinned.GenerateSpeech(context.Background(), SpeechRequest{Text: "hi"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	primary.err = context.Canceled
+	if _, err := pinned.GenerateSpeech(ctx, SpeechRequest{Text: "hi"}); err == nil {
+		t.Fatal("expected the cancelled trial to fail")
+	}
+
+	breaker := chain.providers[0].breaker
+	if breaker.failures != 1 || breaker.trial {
+		t.Errorf("a cancelled trial must leave the breaker open, got failures=%d trial=%v", breaker.failures, breaker.trial)
+	}
+	if !breaker.allow() {
+		t.Error("the next request should be let through as the trial")
+	}
+}
+
+func TestIsTransientTTSError(t *testing.T) {
+	_, decodeErr := base64.StdEncoding.DecodeString("not base64!")
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"rate limited", context.Background(), &TTSStatusError{StatusCode: 429}, true},
+		{"server error", context.Background(), &TTSStatusError{StatusCode: 502}, true},
+		{"bad request", context.Background(), &TTSStatusError{StatusCode: 400}, false},
+		{"network error", context.Background(), fmt.Errorf("request failed: %w", &net.OpError{Op: "dial", Err: fmt.Errorf("connection refused")}), true},
+		{"cut-off body", context.Background(), fmt.Errorf("failed to read audio: %w", io.ErrUnexpectedEOF), true},
+		{"undecodable audio", context.Background(), fmt.Errorf("failed to decode audio: %w", decodeErr), false},
+		{"invalid request", context.Background(), fmt.Errorf("%w: no text", ErrTTSInvalidRequest), false},
+		{"cancelled", cancelled, &net.OpError{Op: "dial", Err: context.Canceled}, false},
+	}
+	for _, tt := range tests {
+		if got := isTransientTTSError(tt.ctx, tt.err); got != tt.want {
+			t.Errorf("%s: isTransientTTSError = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
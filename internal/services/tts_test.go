@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseLanguageVoices(t *testing.T) {
+	voices := ParseLanguageVoices(" es=voiceA, pt-BR=voiceB,broken,fr= ")
+	if len(voices) != 2 || voices["es"] != "voiceA" || voices["pt"] != "voiceB" {
+		t.Errorf("unexpected voice map: %v", voices)
+	}
+}
+
+func TestCartesiaLanguageSelection(t *testing.T) {
+	if m := cartesiaModelForLanguage(""); m != cartesiaEnglishModel {
+		t.Errorf("empty language should use %s, got %s", cartesiaEnglishModel, m)
+	}
+	if m := cartesiaModelForLanguage("es"); m != cartesiaMultilingualModel {
+		t.Errorf("Spanish should use %s, got %s", cartesiaMultilingualModel, m)
+	}
+
+	svc := NewCartesiaService("key", "http://127.0.0.1:0")
+	_, err := svc.GenerateSpeech(context.Background(), SpeechRequest{Text: "Hallo", Language: "xx"})
+	if !errors.Is(err, ErrTTSInvalidRequest) {
+		t.Errorf("unsupported language should be an invalid request, got %v", err)
+	}
+}
+
+func TestVoiceLanguageCheck(t *testing.T) {
+	lookups := 0
+	languages := map[string][]string{"multi": {"en", "es"}, "french": {"fr"}}
+	lookup := func(ctx context.Context, voiceID string) ([]string, error) {
+		lookups++
+		if voiceID == "missing" {
+			return nil, &TTSStatusError{Provider: TTSProviderElevenLabs, StatusCode: 404}
+		}
+		return languages[voiceID], nil
+	}
+
+	check := &voiceLanguageCheck{provider: "ElevenLabs", policy: VoiceLanguageReject}
+	ctx := context.Background()
+	if err := check.check(ctx, "multi", "es", lookup); err != nil {
+		t.Errorf("a voice verified in the language should pass, got %v", err)
+	}
+	if err := check.check(ctx, "french", "es", lookup); !errors.Is(err, ErrTTSInvalidRequest) {
+		t.Errorf("a mismatch should be rejected, got %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := check.check(ctx, "missing", "es", lookup); err != nil {
+			t.Errorf("a failed lookup should not block synthesis, got %v", err)
+		}
+	}
+	check.check(ctx, "multi", "en", lookup)
+	if lookups != 3 {
+		t.Errorf("each voice should be looked up once, failures included; got %d lookups", lookups)
+	}
+
+	check.policy = VoiceLanguageWarn
+	if err := check.check(ctx, "french", "es", lookup); err != nil {
+		t.Errorf("the warn policy should not fail the request, got %v", err)
+	}
+}
+
+func TestElevenLabsLanguageCode(t *testing.T) {
+	for model, want := range map[string]bool{
+		"eleven_flash_v2_5":      true,
+		"eleven_turbo_v2_5":      true,
+		"eleven_multilingual_v2": false,
+		"eleven_v3":              false,
+	} {
+		if got := elevenLabsSupportsLanguageCode(model); got != want {
+			t.Errorf("elevenLabsSupportsLanguageCode(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
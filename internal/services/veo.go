@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"net/http"
 	"strings"
 	"time"
 
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/metrics"
+	"github.com/bobarin/episod/internal/tracing"
+
 	"google.golang.org/genai"
 )
 
@@ -80,10 +84,13 @@ No generated audio or dialogue. Silent video only.`, rawPrompt)
 //   - imageMimeType: MIME type of the image (e.g., "image/png")
 //
 // Returns the raw video bytes (MP4) or an error.
-func (s *VeoService) GenerateVideo(ctx context.Context, prompt string, imageData []byte, imageMimeType string) ([]byte, error) {
+func (s *VeoService) GenerateVideo(ctx context.Context, prompt string, imageData []byte, imageMimeType string) (_ []byte, err error) {
+	defer metrics.ObserveProviderCall("veo", "generate_video", time.Now(), &err)
+
 	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  s.apiKey,
-		Backend: genai.BackendGeminiAPI,
+		APIKey:     s.apiKey,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: &http.Client{Transport: tracing.Transport(nil)},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create genai client: %w", err)
@@ -106,7 +113,7 @@ func (s *VeoService) GenerateVideo(ctx context.Context, prompt string, imageData
 		NumberOfVideos:   1,
 	}
 
-	log.Printf("[Veo] Starting video generation (model=%s, promptLen=%d, enhancedLen=%d, imageSize=%d bytes)", s.model, len(prompt), len(enhancedPrompt), len(imageData))
+	joblog.Printf(ctx, "[Veo] Starting video generation (model=%s, promptLen=%d, enhancedLen=%d, imageSize=%d bytes)", s.model, len(prompt), len(enhancedPrompt), len(imageData))
 
 	// Start the async video generation operation with the enhanced prompt
 	operation, err := client.Models.GenerateVideos(ctx, s.model, enhancedPrompt, firstFrame, config)
@@ -114,7 +121,7 @@ func (s *VeoService) GenerateVideo(ctx context.Context, prompt string, imageData
 		return nil, fmt.Errorf("failed to start video generation: %w", err)
 	}
 
-	log.Printf("[Veo] Operation started: %s", operation.Name)
+	joblog.Printf(ctx, "[Veo] Operation started: %s", operation.Name)
 
 	// Poll until done, cancelled, or timed out
 	deadline := time.Now().Add(veoMaxPollDuration)
@@ -136,7 +143,7 @@ func (s *VeoService) GenerateVideo(ctx context.Context, prompt string, imageData
 			return nil, fmt.Errorf("failed to poll operation (attempt %d): %w", pollCount, err)
 		}
 
-		log.Printf("[Veo] Poll %d: done=%v", pollCount, operation.Done)
+		joblog.Printf(ctx, "[Veo] Poll %d: done=%v", pollCount, operation.Done)
 	}
 
 	// Check for operation-level errors (e.g. invalid request, quota exceeded)
@@ -150,7 +157,7 @@ func (s *VeoService) GenerateVideo(ctx context.Context, prompt string, imageData
 		// Log any metadata that might contain clues
 		if operation.Metadata != nil {
 			metaJSON, _ := json.Marshal(operation.Metadata)
-			log.Printf("[Veo] Operation metadata: %s", string(metaJSON))
+			joblog.Printf(ctx, "[Veo] Operation metadata: %s", string(metaJSON))
 		}
 		return nil, fmt.Errorf("no response in completed operation after %d polls (operation: %s)", pollCount, operation.Name)
 	}
@@ -176,7 +183,7 @@ func (s *VeoService) GenerateVideo(ctx context.Context, prompt string, imageData
 		return nil, fmt.Errorf("generated video object is nil")
 	}
 
-	log.Printf("[Veo] Video ready, downloading...")
+	joblog.Printf(ctx, "[Veo] Video ready, downloading...")
 
 	// Download the generated video
 	downloadURI := genai.NewDownloadURIFromVideo(video.Video)
@@ -189,7 +196,7 @@ func (s *VeoService) GenerateVideo(ctx context.Context, prompt string, imageData
 		return nil, fmt.Errorf("downloaded video is empty (0 bytes)")
 	}
 
-	log.Printf("[Veo] Video generated successfully (%d bytes, %d polls)", len(videoBytes), pollCount)
+	joblog.Printf(ctx, "[Veo] Video generated successfully (%d bytes, %d polls)", len(videoBytes), pollCount)
 
 	return videoBytes, nil
 }
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"strings"
+)
+
+// ---------------------------------------------------------------------------
+// Voice Style — turns the planner's voice_style_instruction into settings
+//
+// The planner describes delivery in plain English ("slow, mysterious, and
+// low-pitched"). ParseVoiceStyle reads it into a provider-neutral
+// VoiceDelivery (emotion, pace, intensity); each provider maps that onto its
+// own controls on top of its defaults and the project's base VoiceSettings:
+//
+//   ElevenLabs — stability / style / speed, plus an audio tag ([excited],
+//                [whispers], ...) on models that support them (eleven_v3)
+//   Cartesia   — generation_config emotion and speed
+// ---------------------------------------------------------------------------
+
+// VoiceSettings are per-project base voice settings, read from the series'
+// default_voice_profile. nil fields keep the provider's default.
+type VoiceSettings struct {
+	Stability       *float64 // ElevenLabs, 0–1: lower is more expressive
+	SimilarityBoost *float64 // ElevenLabs, 0–1: adherence to the original voice
+	Style           *float64 // ElevenLabs, 0–1: style exaggeration
+	Speed           *float64 // Both providers: 1.0 is the voice's natural pace
+	Volume          *float64 // Cartesia, 0.5–2.0
+}
+
+// VoiceSettingsFromProfile reads base settings from a series' default_voice_profile,
+// e.g. {"stability": 0.45, "speed": 0.9}. Non-numeric values are ignored.
+// Returns nil when the profile sets none of them.
+func VoiceSettingsFromProfile(profile map[string]interface{}) *VoiceSettings {
+	settings := &VoiceSettings{
+		Stability:       profileFloat(profile, "stability"),
+		SimilarityBoost: profileFloat(profile, "similarity_boost"),
+		Style:           profileFloat(profile, "style"),
+		Speed:           profileFloat(profile, "speed"),
+		Volume:          profileFloat(profile, "volume"),
+	}
+	if *settings == (VoiceSettings{}) {
+		return nil
+	}
+	return settings
+}
+
+// profileFloat returns profile[key] if it is a number.
+func profileFloat(profile map[string]interface{}, key string) *float64 {
+	v, ok := profile[key].(float64)
+	if !ok {
+		return nil
+	}
+	return &v
+}
+
+// valueOr returns *v, or def when v is nil.
+func valueOr(v *float64, def float64) float64 {
+	if v == nil {
+		return def
+	}
+	return *v
+}
+
+// VoiceDelivery is what a style instruction asks for, in provider-neutral terms.
+type VoiceDelivery struct {
+	Emotion   string  // Dominant emotion ("excited", "calm", "mysterious", ...), "" if none
+	Pace      float64 // Speed multiplier: <1 slower, >1 faster
+	Intensity float64 // -1 (restrained) … +1 (dramatic)
+	Whisper   bool    // Hushed delivery
+}
+
+// styleEmotions maps instruction keywords to emotions, checked in order so
+// the first (most specific) match wins.
+var styleEmotions = []struct{ keyword, emotion string }{
+	{"whisper", "mysterious"},
+	{"mysterious", "mysterious"},
+	{"eerie", "mysterious"},
+	{"excited", "excited"},
+	{"energetic", "excited"},
+	{"enthusiastic", "enthusiastic"},
+	{"engaging", "enthusiastic"},
+	{"dramatic", "intense"},
+	{"intense", "intense"},
+	{"urgent", "intense"},
+	{"angry", "angry"},
+	{"sad", "sad"},
+	{"somber", "sad"},
+	{"melancholic", "sad"},
+	{"scared", "scared"},
+	{"fearful", "scared"},
+	{"happy", "happy"},
+	{"cheerful", "happy"},
+	{"playful", "happy"},
+	{"confident", "confident"},
+	{"authoritative", "confident"},
+	{"peaceful", "peaceful"},
+	{"calm", "calm"},
+	{"soothing", "calm"},
+	{"serious", "calm"},
+}
+
+// stylePace adjusts speed from pacing words. Earlier (more specific) entries win.
+var stylePace = []struct {
+	keyword string
+	factor  float64
+}{
+	{"very slow", 0.85},
+	{"slow", 0.92},
+	{"deliberate", 0.95},
+	{"measured", 0.95},
+	{"unhurried", 0.95},
+	{"very fast", 1.15},
+	{"fast", 1.08},
+	{"quick", 1.08},
+	{"brisk", 1.06},
+	{"rapid", 1.1},
+	{"urgent", 1.06},
+}
+
+var (
+	intenseStyleWords    = []string{"dramatic", "intense", "passionate", "excited", "energetic", "emotional", "urgent", "angry", "powerful"}
+	restrainedStyleWords = []string{"calm", "soothing", "gentle", "flat", "neutral", "restrained", "measured", "understated", "soft"}
+)
+
+// ParseVoiceStyle reads a voice_style_instruction into a VoiceDelivery.
+// Unrecognized instructions yield a neutral delivery (Pace 1, Intensity 0).
+func ParseVoiceStyle(instruction string) VoiceDelivery {
+	style := strings.ToLower(instruction)
+	delivery := VoiceDelivery{Pace: 1}
+
+	for _, e := range styleEmotions {
+		if strings.Contains(style, e.keyword) {
+			delivery.Emotion = e.emotion
+			break
+		}
+	}
+	for _, p := range stylePace {
+		if strings.Contains(style, p.keyword) {
+			delivery.Pace = p.factor
+			break
+		}
+	}
+	for _, w := range intenseStyleWords {
+		if strings.Contains(style, w) {
+			delivery.Intensity += 0.5
+		}
+	}
+	for _, w := range restrainedStyleWords {
+		if strings.Contains(style, w) {
+			delivery.Intensity -= 0.5
+		}
+	}
+	delivery.Intensity = clampFloat(delivery.Intensity, -1, 1)
+	delivery.Whisper = strings.Contains(style, "whisper") || strings.Contains(style, "hushed")
+
+	return delivery
+}
+
+// clampFloat limits v to [lo, hi].
+func clampFloat(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,38 @@
+package services
+
+import "testing"
+
+func TestParseVoiceStyle(t *testing.T) {
+	d := ParseVoiceStyle("Slow, dramatic and intense")
+	if d.Emotion != "intense" || d.Pace != 0.92 || d.Intensity != 1 {
+		t.Errorf("unexpected delivery: %+v", d)
+	}
+
+	d = ParseVoiceStyle("calm, soothing whisper")
+	if d.Emotion != "mysterious" || !d.Whisper || d.Intensity != -1 {
+		t.Errorf("unexpected delivery: %+v", d)
+	}
+
+	if d := ParseVoiceStyle("something unusual"); d != (VoiceDelivery{Pace: 1}) {
+		t.Errorf("unrecognized style should be neutral, got %+v", d)
+	}
+}
+
+func TestElevenLabsSettingsFromStyle(t *testing.T) {
+	// Neutral style keeps the narration defaults
+	settings, speed := elevenLabsSettingsFor(nil, VoiceDelivery{Pace: 1})
+	if settings.Stability != 0.60 || settings.Style != 0.35 || speed != 0.85 {
+		t.Errorf("unexpected defaults: %+v speed=%.2f", settings, speed)
+	}
+
+	// Series base settings apply first, then the style adjusts them
+	base := VoiceSettingsFromProfile(map[string]interface{}{"stability": 0.5, "speed": 1.0, "speakers": map[string]interface{}{}})
+	settings, speed = elevenLabsSettingsFor(base, ParseVoiceStyle("very fast and dramatic"))
+	if settings.Stability != 0.4 || settings.Style != 0.475 || speed != 1.15 {
+		t.Errorf("unexpected settings: %+v speed=%.2f", settings, speed)
+	}
+
+	if VoiceSettingsFromProfile(map[string]interface{}{"speed": "fast"}) != nil {
+		t.Error("non-numeric profile values should be ignored")
+	}
+}
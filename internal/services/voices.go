@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bobarin/episod/internal/joblog"
+)
+
+// ---------------------------------------------------------------------------
+// Voice Catalogue — the configured providers' voices, for a voice picker
+//
+// Providers that can list their voices implement VoiceLister. The chain
+// aggregates them; VoiceCatalog caches the result (provider voice lists
+// rarely change and are slow to fetch) and synthesizes short previews.
+// ---------------------------------------------------------------------------
+
+// Voice describes one provider voice.
+type Voice struct {
+	ID          string   `json:"id"`       // Provider voice ID — pass as voice_id with tts_provider
+	Provider    string   `json:"provider"` // "elevenlabs" or "cartesia"
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Language    string   `json:"language,omitempty"`  // Primary language (ISO 639-1)
+	Languages   []string `json:"languages,omitempty"` // All languages the voice is verified for
+	Gender      string   `json:"gender,omitempty"`    // "male", "female" or "neutral"
+	Accent      string   `json:"accent,omitempty"`    // e.g. "american", "british"
+	PreviewURL  string   `json:"preview_url,omitempty"`
+}
+
+// VoiceLister is implemented by TTS services that can list their voices.
+type VoiceLister interface {
+	ListVoices(ctx context.Context) ([]Voice, error)
+}
+
+// Ensure the providers and the chain implement VoiceLister at compile time.
+var (
+	_ VoiceLister = (*ChainTTSService)(nil)
+	_ VoiceLister = (*ElevenLabsService)(nil)
+	_ VoiceLister = (*CartesiaService)(nil)
+)
+
+// ListVoices aggregates the voices of every provider in the chain that can
+// list them. A failing provider is logged and skipped; an error is returned
+// only if no provider could be listed.
+func (c *ChainTTSService) ListVoices(ctx context.Context) ([]Voice, error) {
+	var (
+		voices  []Voice
+		lastErr error
+		listed  bool
+	)
+	for _, p := range c.providers {
+		lister, ok := p.Service.(VoiceLister)
+		if !ok {
+			continue
+		}
+		providerVoices, err := lister.ListVoices(ctx)
+		if err != nil {
+			joblog.Printf(ctx, "[Voices] Could not list %s voices: %v", p.Name, err)
+			lastErr = err
+			continue
+		}
+		for _, v := range providerVoices {
+			v.Provider = p.Name
+			voices = append(voices, v)
+		}
+		listed = true
+	}
+	if !listed && lastErr != nil {
+		return nil, fmt.Errorf("failed to list voices: %w", lastErr)
+	}
+	return voices, nil
+}
+
+// normalizeVoiceGender maps provider gender labels onto male/female/neutral.
+func normalizeVoiceGender(gender string) string {
+	switch strings.ToLower(strings.TrimSpace(gender)) {
+	case "":
+		return ""
+	case "male", "masculine", "man":
+		return "male"
+	case "female", "feminine", "woman":
+		return "female"
+	default:
+		return "neutral"
+	}
+}
+
+// voicePreviewTexts are the default preview sentences per language.
+var voicePreviewTexts = map[string]string{
+	"en": "Hi there! This is how I sound telling your story. Ready when you are.",
+	"es": "¡Hola! Así sueno contando tu historia. Cuando quieras, empezamos.",
+	"fr": "Bonjour ! Voici ma voix pour raconter votre histoire. On commence quand vous voulez.",
+	"de": "Hallo! So klinge ich, wenn ich deine Geschichte erzähle. Los geht's, wann du willst.",
+	"it": "Ciao! Ecco come suono mentre racconto la tua storia. Iniziamo quando vuoi.",
+	"pt": "Olá! É assim que eu soo contando a sua história. Vamos começar quando quiser.",
+}
+
+// MaxVoicePreviewChars bounds custom preview text, keeping previews short and cheap.
+const MaxVoicePreviewChars = 300
+
+// VoiceCatalog caches the aggregated voice list and generates voice previews.
+type VoiceCatalog struct {
+	tts *ChainTTSService
+	ttl time.Duration
+
+	mu        sync.Mutex
+	voices    []Voice
+	fetchedAt time.Time
+	previews  map[string]string // preview key → stored preview URL
+}
+
+// NewVoiceCatalog creates a catalogue over the chain's providers. Voice lists
+// are refetched after ttl.
+func NewVoiceCatalog(tts *ChainTTSService, ttl time.Duration) *VoiceCatalog {
+	return &VoiceCatalog{
+		tts:      tts,
+		ttl:      ttl,
+		previews: make(map[string]string),
+	}
+}
+
+// Voices returns all voices, from cache when fresh.
+func (c *VoiceCatalog) Voices(ctx context.Context) ([]Voice, error) {
+	c.mu.Lock()
+	if c.voices != nil && time.Since(c.fetchedAt) < c.ttl {
+		voices := c.voices
+		c.mu.Unlock()
+		return voices, nil
+	}
+	c.mu.Unlock()
+
+	voices, err := c.tts.ListVoices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if voices == nil {
+		voices = []Voice{}
+	}
+
+	c.mu.Lock()
+	c.voices = voices
+	c.fetchedAt = time.Now()
+	c.mu.Unlock()
+
+	joblog.Printf(ctx, "[Voices] Catalogue refreshed (%d voices)", len(voices))
+	return voices, nil
+}
+
+// FindVoice looks up a voice by ID, optionally restricted to one provider.
+func (c *VoiceCatalog) FindVoice(ctx context.Context, provider, id string) (*Voice, error) {
+	voices, err := c.Voices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range voices {
+		if v.ID == id && (provider == "" || v.Provider == provider) {
+			return &v, nil
+		}
+	}
+	return nil, fmt.Errorf("voice %s not found", id)
+}
+
+// Preview synthesizes a short sample of a voice and stores it with store,
+// which returns the sample's URL. text defaults to a sentence in language
+// (or the voice's own language). Previews are generated once per voice,
+// language and text; later calls return the stored URL.
+func (c *VoiceCatalog) Preview(ctx context.Context, voice Voice, text, language string, store func(path string, audio []byte) (string, error)) (string, error) {
+	if language == "" {
+		language = voice.Language
+	}
+	lang := normalizeTTSLanguage(language)
+	if text == "" {
+		text = voicePreviewTexts[lang]
+		if text == "" {
+			text = voicePreviewTexts["en"]
+		}
+	}
+	if len(text) > MaxVoicePreviewChars {
+		return "", fmt.Errorf("preview text is longer than %d characters", MaxVoicePreviewChars)
+	}
+
+	sum := sha256.Sum256([]byte(voice.Provider + "\x00" + voice.ID + "\x00" + lang + "\x00" + text))
+	key := hex.EncodeToString(sum[:])[:16]
+
+	c.mu.Lock()
+	url, ok := c.previews[key]
+	c.mu.Unlock()
+	if ok {
+		return url, nil
+	}
+
+	tts, err := c.tts.WithProvider(voice.Provider)
+	if err != nil {
+		return "", err
+	}
+	resp, err := tts.GenerateSpeech(ctx, SpeechRequest{
+		Text:     text,
+		VoiceID:  voice.ID,
+		Language: lang,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to synthesize preview: %w", err)
+	}
+
+	url, err = store(fmt.Sprintf("voices/previews/%s/%s_%s.%s", voice.Provider, voice.ID, key, resp.Format), resp.AudioData)
+	if err != nil {
+		return "", fmt.Errorf("failed to store preview: %w", err)
+	}
+
+	c.mu.Lock()
+	c.previews[key] = url
+	c.mu.Unlock()
+
+	joblog.Printf(ctx, "[Voices] Preview generated for %s voice %s (%s, %dms)", voice.Provider, voice.ID, lang, resp.DurationMs)
+	return url, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeVoiceTTS is a fakeTTS that can also list voices.
+type fakeVoiceTTS struct {
+	fakeTTS
+	list    []Voice
+	listErr error
+	lists   int
+}
+
+func (f *fakeVoiceTTS) ListVoices(ctx context.Context) ([]Voice, error) {
+	f.lists++
+	return f.list, f.listErr
+}
+
+func TestVoiceCatalogAggregatesAndCaches(t *testing.T) {
+	eleven := &fakeVoiceTTS{list: []Voice{{ID: "v1", Name: "Adam"}}}
+	cartesia := &fakeVoiceTTS{listErr: errors.New("down")}
+	chain := NewChainTTSService([]NamedTTSService{
+		{Name: TTSProviderElevenLabs, Service: eleven},
+		{Name: TTSProviderCartesia, Service: cartesia},
+	}, 3, time.Minute)
+	catalog := NewVoiceCatalog(chain, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		voices, err := catalog.Voices(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(voices) != 1 || voices[0].Provider != TTSProviderElevenLabs {
+			t.Fatalf("a failing provider should be skipped, got %+v", voices)
+		}
+	}
+	if eleven.lists != 1 {
+		t.Errorf("voice list should be cached, listed %d times", eleven.lists)
+	}
+
+	voice, err := catalog.FindVoice(context.Background(), "", "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := 0
+	store := func(path string, audio []byte) (string, error) {
+		stored++
+		return "https://cdn/" + path, nil
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := catalog.Preview(context.Background(), *voice, "", "es", store); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stored != 1 || eleven.calls != 1 || eleven.voices[0] != "v1" {
+		t.Errorf("preview should be synthesized once with the voice, stored=%d calls=%d voices=%v", stored, eleven.calls, eleven.voices)
+	}
+}
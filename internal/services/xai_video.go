@@ -6,11 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"time"
 
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/metrics"
 	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/tracing"
 )
 
 // ---------------------------------------------------------------------------
@@ -47,11 +49,17 @@ func NewXAIVideoService(apiKey string) *XAIVideoService {
 	return &XAIVideoService{
 		apiKey: apiKey,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second, // Timeout for individual HTTP calls, not the full poll cycle
+			Timeout:   30 * time.Second, // Timeout for individual HTTP calls, not the full poll cycle
+			Transport: tracing.Transport(nil),
 		},
 	}
 }
 
+// Model returns the video model name (part of the provider cache key).
+func (s *XAIVideoService) Model() string {
+	return xaiVideoModel
+}
+
 // ---------------------------------------------------------------------------
 // Request / Response types
 // ---------------------------------------------------------------------------
@@ -140,7 +148,9 @@ Generate natural, cinematic movement that brings the scene to life. Silent video
 //   - opts: per-project overrides for visual style and aspect ratio (nil = use defaults)
 //
 // Returns the raw video bytes (MP4) or an error.
-func (s *XAIVideoService) GenerateVideo(ctx context.Context, prompt string, imageURL string, durationSec int, opts *VideoGenOptions) ([]byte, error) {
+func (s *XAIVideoService) GenerateVideo(ctx context.Context, prompt string, imageURL string, durationSec int, opts *VideoGenOptions) (_ []byte, err error) {
+	defer metrics.ObserveProviderCall("xai", "generate_video", time.Now(), &err)
+
 	enhancedPrompt := buildXAIVideoPrompt(prompt, opts)
 
 	// Clamp duration to xAI's allowed range
@@ -173,7 +183,7 @@ func (s *XAIVideoService) GenerateVideo(ctx context.Context, prompt string, imag
 		reqBody.Image = &xaiImageInput{URL: imageURL}
 	}
 
-	log.Printf("[xAI Video] Starting video generation (promptLen=%d, enhancedLen=%d, hasImage=%v, duration=%ds, aspect=%s)",
+	joblog.Printf(ctx, "[xAI Video] Starting video generation (promptLen=%d, enhancedLen=%d, hasImage=%v, duration=%ds, aspect=%s)",
 		len(prompt), len(enhancedPrompt), imageURL != "", durationSec, aspectRatio)
 
 	requestID, err := s.submitGeneration(ctx, reqBody)
@@ -181,7 +191,7 @@ func (s *XAIVideoService) GenerateVideo(ctx context.Context, prompt string, imag
 		return nil, fmt.Errorf("failed to submit video generation: %w", err)
 	}
 
-	log.Printf("[xAI Video] Generation submitted, request_id=%s", requestID)
+	joblog.Event(ctx, "[xAI Video] Generation submitted", map[string]interface{}{"provider": "xai", "request_id": requestID})
 
 	// Step 2: Poll for completion
 	result, err := s.pollForResult(ctx, requestID)
@@ -189,7 +199,7 @@ func (s *XAIVideoService) GenerateVideo(ctx context.Context, prompt string, imag
 		return nil, err
 	}
 
-	log.Printf("[xAI Video] Video ready (duration=%ds), downloading from URL...", result.Video.Duration)
+	joblog.Printf(ctx, "[xAI Video] Video ready (duration=%ds), downloading from URL...", result.Video.Duration)
 
 	// Step 3: Download the video from the returned URL
 	videoBytes, err := s.downloadVideo(ctx, result.Video.URL)
@@ -201,7 +211,7 @@ func (s *XAIVideoService) GenerateVideo(ctx context.Context, prompt string, imag
 		return nil, fmt.Errorf("downloaded video is empty (0 bytes)")
 	}
 
-	log.Printf("[xAI Video] Video downloaded successfully (%d bytes)", len(videoBytes))
+	joblog.Printf(ctx, "[xAI Video] Video downloaded successfully (%d bytes)", len(videoBytes))
 	return videoBytes, nil
 }
 
@@ -265,7 +275,7 @@ func (s *XAIVideoService) pollForResult(ctx context.Context, requestID string) (
 
 	// Wait before the first poll — xAI video generation typically takes 30-40s,
 	// so the first 15s are guaranteed to be "pending".
-	log.Printf("[xAI Video] Waiting %v before first poll (videos typically take 30-40s)...", xaiInitialDelay)
+	joblog.Printf(ctx, "[xAI Video] Waiting %v before first poll (videos typically take 30-40s)...", xaiInitialDelay)
 	select {
 	case <-ctx.Done():
 		return nil, fmt.Errorf("video generation cancelled during initial wait: %w", ctx.Err())
@@ -287,11 +297,11 @@ func (s *XAIVideoService) pollForResult(ctx context.Context, requestID string) (
 		// Detection: when xAI completes, it returns a "video" object with no "status" field.
 		// When pending, it returns {"status":"pending"} with no "video" object.
 		if result.Video != nil && result.Video.URL != "" {
-			log.Printf("[xAI Video] Poll %d: completed (video url present, duration=%ds)", pollCount, result.Video.Duration)
+			joblog.Printf(ctx, "[xAI Video] Poll %d: completed (video url present, duration=%ds)", pollCount, result.Video.Duration)
 			return result, nil
 		}
 
-		log.Printf("[xAI Video] Poll %d: status=%s (next poll in %v)", pollCount, result.Status, currentInterval)
+		joblog.Printf(ctx, "[xAI Video] Poll %d: status=%s (next poll in %v)", pollCount, result.Status, currentInterval)
 
 		switch result.Status {
 		case "failed":
@@ -357,7 +367,7 @@ func (s *XAIVideoService) getVideoResult(ctx context.Context, requestID string)
 // downloadVideo fetches the video bytes from the given URL.
 func (s *XAIVideoService) downloadVideo(ctx context.Context, videoURL string) ([]byte, error) {
 	// Use a longer timeout for video download (videos can be large)
-	downloadClient := &http.Client{Timeout: 120 * time.Second}
+	downloadClient := &http.Client{Timeout: 120 * time.Second, Transport: tracing.Transport(nil)}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", videoURL, nil)
 	if err != nil {
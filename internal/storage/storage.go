@@ -15,6 +15,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bobarin/episod/internal/tracing"
+
 	"github.com/google/uuid"
 )
 
@@ -45,11 +47,11 @@ func New(url, serviceKey, bucket string) *Storage {
 		Bucket:     bucket,
 		client: &http.Client{
 			Timeout: uploadTimeout,
-			Transport: &http.Transport{
+			Transport: tracing.Transport(&http.Transport{
 				MaxIdleConns:        100,
 				MaxIdleConnsPerHost: 20,
 				IdleConnTimeout:     90 * time.Second,
-			},
+			}),
 		},
 	}
 }
@@ -263,8 +265,10 @@ func (s *Storage) GetSignedURL(ctx context.Context, path string, expiresIn int)
 //	      plan.json
 //	      clip_0_image.png
 //	      clip_0_audio.mp3
+//	      clip_0_words.json
 //	      clip_{uuid}.mp4
 //	      final.mp4
+//	      final.srt / final.vtt / final.ass
 func (s *Storage) GenerateStoragePath(projectID uuid.UUID, filename string) string {
 	return filepath.Join("projects", projectID.String(), filename)
 }
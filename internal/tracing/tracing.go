@@ -0,0 +1,165 @@
+// Package tracing sets up OpenTelemetry tracing and provides the helpers the
+// API, queue, worker and services use to create spans.
+//
+// Tracing is a no-op unless an OTLP endpoint is configured: spans are then
+// exported over OTLP/HTTP (e.g. to a local collector or Jaeger), giving one
+// waterfall per project — API request → enqueue → generate_plan → each
+// process_clip with its visual/audio pipelines, provider HTTP calls and
+// FFmpeg runs → render_final. Trace context crosses the Redis queue inside
+// queue.Job.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/bobarin/episod"
+
+// Config holds tracing settings from env config.
+type Config struct {
+	// OTLPEndpoint is the OTLP/HTTP endpoint URL, e.g. http://localhost:4318.
+	// Empty = tracing disabled (no-op).
+	OTLPEndpoint string
+
+	// ServiceName identifies this process in traces.
+	ServiceName string
+
+	// SampleRatio is the fraction of new traces recorded (0–1]. Traces
+	// continued from a sampled parent are always recorded.
+	SampleRatio float64
+}
+
+// Setup installs the global tracer provider and W3C trace-context
+// propagator. The returned shutdown flushes pending spans; call it on exit.
+// With no endpoint configured, tracing stays a no-op and shutdown does nothing.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	if cfg.OTLPEndpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	log.Printf("Tracing enabled (OTLP endpoint: %s, service: %s, sample ratio: %.2f)", cfg.OTLPEndpoint, cfg.ServiceName, ratio)
+	return provider.Shutdown, nil
+}
+
+// Start starts a span as a child of the span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartKind starts a span of the given kind (server, client, producer, consumer).
+func StartKind(ctx context.Context, name string, kind trace.SpanKind, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attrs...))
+}
+
+// End records err (if any) on span and ends it. Designed for defer with a
+// named error result:
+//
+//	ctx, span := tracing.Start(ctx, "stage")
+//	defer func() { tracing.End(span, err) }()
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject returns ctx's trace context as a string map, for carrying it
+// through the queue. Returns nil when ctx has no span.
+func Inject(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// Extract returns ctx continuing the trace context in carrier (from Inject).
+func Extract(ctx context.Context, carrier map[string]string) context.Context {
+	if len(carrier) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(carrier))
+}
+
+// ExtractHTTP returns ctx continuing the trace context of an incoming request.
+func ExtractHTTP(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// Transport wraps an HTTP transport (nil = http.DefaultTransport) so every
+// outgoing request gets a client span and carries the trace context.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := StartKind(req.Context(), "HTTP "+req.Method+" "+req.URL.Host, trace.SpanKindClient,
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.ServerAddress(req.URL.Host),
+		semconv.URLPath(req.URL.Path),
+	)
+	defer span.End()
+
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,247 @@
+package worker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/services"
+	"github.com/google/uuid"
+)
+
+// ---------------------------------------------------------------------------
+// Provider output cache
+//
+// Gemini images, TTS audio and xAI videos are keyed by a hash of everything
+// that determines the output (provider, model, prompt, voice, settings, ...).
+// A cached output is stored once more as an immutable, content-addressed
+// object (cache/<sha256>.<ext>): the clip's own files are overwritten when
+// the clip is retried or re-narrated, so the cache can't point at them. On a
+// hit the clip gets a new asset record pointing at the cached object —
+// nothing is generated or uploaded again. Projects with bypass_cache always
+// call the providers but still refresh the cache.
+// ---------------------------------------------------------------------------
+
+// Cache kinds — the provider column and the key prefix.
+const (
+	cacheKindImage = "gemini"
+	cacheKindAudio = "tts"
+	cacheKindVideo = "xai"
+)
+
+// providerCacheKey hashes a provider call's inputs into a cache key. Map keys
+// are sorted by encoding/json, so equal inputs always give the same key.
+// Returns "" (caching disabled for the call) if the inputs can't be encoded.
+func providerCacheKey(ctx context.Context, kind string, inputs ...interface{}) string {
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		joblog.Warnf(ctx, "[Cache] Could not hash %s inputs, skipping cache: %v", kind, err)
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return kind + ":" + hex.EncodeToString(sum[:])
+}
+
+// cachedOutput is a cache hit: the cached asset, its bytes and metadata.
+type cachedOutput struct {
+	Asset    *models.Asset
+	Data     []byte
+	Metadata models.JSONB
+}
+
+// cacheLookup returns the cached output for key, or nil on a miss, when the
+// cache is disabled, or when the project bypasses it. Lookup failures are
+// logged and treated as misses — the caller just calls the provider.
+func (w *Worker) cacheLookup(ctx context.Context, project *models.Project, key string) *cachedOutput {
+	if !w.cacheEnabled || key == "" || project.BypassCache {
+		return nil
+	}
+
+	entry, err := w.db.UseProviderCacheEntry(ctx, key)
+	if err != nil {
+		joblog.Warnf(ctx, "[Cache] lookup failed for %s: %v", key, err)
+		return nil
+	}
+	if entry == nil {
+		return nil
+	}
+
+	asset, err := w.db.GetAsset(ctx, entry.AssetID)
+	if err == nil {
+		var data []byte
+		if data, err = w.storage.Download(ctx, asset.StoragePath); err == nil {
+			joblog.Printf(ctx, "[Cache] Hit %s (asset %s, %d hits)", key, asset.ID, entry.HitCount)
+			return &cachedOutput{Asset: asset, Data: data, Metadata: entry.Metadata}
+		}
+	}
+
+	// The cached object is gone — forget the entry so it gets regenerated
+	joblog.Warnf(ctx, "[Cache] cached asset for %s unavailable, regenerating: %v", key, err)
+	if delErr := w.db.DeleteProviderCacheEntry(ctx, key); delErr != nil {
+		joblog.Warnf(ctx, "[Cache] %v", delErr)
+	}
+	return nil
+}
+
+// cacheStore records data, the contents of asset, as the output for key and
+// evicts expired and surplus entries. The entry points at a content-addressed
+// copy of asset rather than asset itself. Non-critical: failures are only
+// logged.
+func (w *Worker) cacheStore(ctx context.Context, key, kind string, asset *models.Asset, data []byte, metadata models.JSONB) {
+	if !w.cacheEnabled || key == "" {
+		return
+	}
+
+	cached, err := w.storeCacheObject(ctx, asset, data)
+	if err != nil {
+		joblog.Warnf(ctx, "[Cache] could not cache %s: %v", key, err)
+		return
+	}
+
+	entry := &models.ProviderCacheEntry{
+		Key:      key,
+		Provider: kind,
+		AssetID:  cached.ID,
+		Metadata: metadata,
+	}
+	if err := w.db.PutProviderCacheEntry(ctx, entry, w.cacheTTL); err != nil {
+		joblog.Warnf(ctx, "[Cache] could not cache %s: %v", key, err)
+		return
+	}
+
+	if removed, err := w.db.EvictProviderCache(ctx, w.cacheMaxEntries); err != nil {
+		joblog.Warnf(ctx, "[Cache] eviction failed: %v", err)
+	} else if removed > 0 {
+		joblog.Printf(ctx, "[Cache] Evicted %d entries", removed)
+	}
+}
+
+// storeCacheObject uploads data as cache/<sha256>.<ext> and records it as a
+// project asset with no clip. Equal contents share one object, and it is
+// never overwritten with anything else.
+func (w *Worker) storeCacheObject(ctx context.Context, asset *models.Asset, data []byte) (*models.Asset, error) {
+	sum := sha256.Sum256(data)
+	cached := *asset
+	cached.ID = uuid.New()
+	cached.ClipID = nil
+	cached.StoragePath = "cache/" + hex.EncodeToString(sum[:]) + path.Ext(asset.StoragePath)
+	cached.ByteSize = int64Ptr(int64(len(data)))
+
+	contentType := "application/octet-stream"
+	if asset.ContentType != nil {
+		contentType = *asset.ContentType
+	}
+	if err := w.uploadWithLimit(ctx, fmt.Sprintf("cache_%s", asset.Type), func() error {
+		return w.storage.Upload(ctx, cached.StoragePath, data, contentType)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to upload cached %s: %w", asset.Type, err)
+	}
+	if err := w.db.CreateAsset(ctx, &cached); err != nil {
+		return nil, fmt.Errorf("failed to save cached %s asset: %w", asset.Type, err)
+	}
+	return &cached, nil
+}
+
+// reuseCachedAsset records a cached object as a new asset of this project's
+// clip. The storage object is shared (it is immutable); only the asset row
+// is new.
+func (w *Worker) reuseCachedAsset(ctx context.Context, cached *cachedOutput, projectID, clipID uuid.UUID) (*models.Asset, error) {
+	asset := *cached.Asset
+	asset.ID = uuid.New()
+	asset.ProjectID = projectID
+	asset.ClipID = &clipID
+	if err := w.db.CreateAsset(ctx, &asset); err != nil {
+		return nil, fmt.Errorf("failed to save cached %s asset: %w", asset.Type, err)
+	}
+	return &asset, nil
+}
+
+// storeAIVideo uploads a generated AI video as an ai_video asset of the clip
+// and caches it (key "" = not cacheable). The asset also lets a retried clip
+// skip the generation. Non-critical: the clip renders from the in-memory
+// bytes either way, so failures are only logged.
+func (w *Worker) storeAIVideo(ctx context.Context, projectID uuid.UUID, clip *models.Clip, key string, data []byte) {
+	asset := &models.Asset{
+		ID:            uuid.New(),
+		ProjectID:     projectID,
+		ClipID:        &clip.ID,
+		Type:          models.AssetTypeAIVideo,
+		StorageBucket: w.storage.Bucket,
+		StoragePath:   w.storage.GenerateStoragePath(projectID, clipFileName(clip, "ai_video.mp4")),
+		ContentType:   strPtr("video/mp4"),
+		ByteSize:      int64Ptr(int64(len(data))),
+	}
+
+	if err := w.uploadWithLimit(ctx, fmt.Sprintf("clip_%d_ai_video", clip.ClipIndex), func() error {
+		return w.storage.Upload(ctx, asset.StoragePath, data, "video/mp4")
+	}); err != nil {
+		joblog.Warnf(ctx, "Clip %d: could not store AI video: %v", clip.ClipIndex, err)
+		return
+	}
+	if err := w.db.CreateAsset(ctx, asset); err != nil {
+		joblog.Warnf(ctx, "Clip %d: could not save AI video asset: %v", clip.ClipIndex, err)
+		return
+	}
+	w.cacheStore(ctx, key, cacheKindVideo, asset, data, nil)
+}
+
+// ttsCacheMetadata is what a cached TTS response needs besides the audio bytes.
+type ttsCacheMetadata struct {
+	DurationMs        int                      `json:"duration_ms"`
+	DurationEstimated bool                     `json:"duration_estimated,omitempty"`
+	Format            string                   `json:"format"`
+	SampleRate        int                      `json:"sample_rate,omitempty"`
+	Channels          int                      `json:"channels,omitempty"`
+	WordTimestamps    []services.WordTimestamp `json:"word_timestamps,omitempty"`
+}
+
+// ttsCacheEntryMetadata encodes resp's metadata for the cache.
+func ttsCacheEntryMetadata(resp *services.TTSResponse) models.JSONB {
+	var metadata models.JSONB
+	if err := convertJSON(ttsCacheMetadata{
+		DurationMs:        resp.DurationMs,
+		DurationEstimated: resp.DurationEstimated,
+		Format:            resp.Format,
+		SampleRate:        resp.SampleRate,
+		Channels:          resp.Channels,
+		WordTimestamps:    resp.WordTimestamps,
+	}, &metadata); err != nil {
+		return nil
+	}
+	return metadata
+}
+
+// ttsResponseFromCache rebuilds a TTS response from a cache hit.
+func ttsResponseFromCache(cached *cachedOutput) (*services.TTSResponse, error) {
+	var metadata ttsCacheMetadata
+	if err := convertJSON(cached.Metadata, &metadata); err != nil {
+		return nil, fmt.Errorf("invalid cached TTS metadata: %w", err)
+	}
+	if metadata.DurationMs <= 0 {
+		return nil, fmt.Errorf("cached TTS metadata has no duration")
+	}
+	return &services.TTSResponse{
+		AudioData:         cached.Data,
+		DurationMs:        metadata.DurationMs,
+		DurationEstimated: metadata.DurationEstimated,
+		Format:            metadata.Format,
+		SampleRate:        metadata.SampleRate,
+		Channels:          metadata.Channels,
+		WordTimestamps:    metadata.WordTimestamps,
+	}, nil
+}
+
+// convertJSON copies src into dst through JSON (JSONB metadata comes back
+// from the database as generic maps and slices).
+func convertJSON(src, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
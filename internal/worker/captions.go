@@ -0,0 +1,160 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/services"
+	"github.com/google/uuid"
+)
+
+// clipSilenceMs is the silence buffer prepended to every clip's narration in
+// renderClip. Sidecar captions shift each clip's words by this amount.
+const clipSilenceMs = 500
+
+// storeWordTimestamps persists a clip's Whisper word timestamps as a JSON asset
+// so the final render can stitch them into project-level sidecar captions.
+func (w *Worker) storeWordTimestamps(ctx context.Context, projectID uuid.UUID, clip *models.Clip, words []services.WordTimestamp) error {
+	wordsJSON, err := json.Marshal(words)
+	if err != nil {
+		return fmt.Errorf("failed to marshal word timestamps: %w", err)
+	}
+
+	asset := &models.Asset{
+		ID:            uuid.New(),
+		ProjectID:     projectID,
+		ClipID:        &clip.ID,
+		Type:          models.AssetTypeWordTimestamps,
+		StorageBucket: w.storage.Bucket,
+		StoragePath:   w.storage.GenerateStoragePath(projectID, clipFileName(clip, "words.json")),
+		ContentType:   strPtr("application/json"),
+		ByteSize:      int64Ptr(int64(len(wordsJSON))),
+	}
+
+	if err := w.uploadWithLimit(ctx, fmt.Sprintf("clip_%d_words", clip.ClipIndex), func() error {
+		return w.storage.Upload(ctx, asset.StoragePath, wordsJSON, "application/json")
+	}); err != nil {
+		return fmt.Errorf("failed to upload word timestamps: %w", err)
+	}
+
+	if err := w.db.CreateAsset(ctx, asset); err != nil {
+		return fmt.Errorf("failed to save word timestamps asset: %w", err)
+	}
+
+	return nil
+}
+
+// loadWordTimestamps downloads a clip's stored word timestamps.
+// Returns nil (no error) when the clip has none — e.g. Whisper failed for it.
+func (w *Worker) loadWordTimestamps(ctx context.Context, clipID uuid.UUID) ([]services.WordTimestamp, error) {
+	asset, err := w.db.GetClipAssetByType(ctx, clipID, models.AssetTypeWordTimestamps)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := w.storage.Download(ctx, asset.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download word timestamps: %w", err)
+	}
+
+	var words []services.WordTimestamp
+	if err := json.Unmarshal(data, &words); err != nil {
+		return nil, fmt.Errorf("failed to parse word timestamps: %w", err)
+	}
+
+	return words, nil
+}
+
+// clipLeadInMs is the silence renderClip prepended to the clip's narration:
+// clipSilenceMs, or none when prepending failed and the raw audio was
+// rendered, which leaves the clip about as long as its narration.
+func clipLeadInMs(clip models.Clip) int {
+	if clip.RenderedDurationMs != nil && clip.AudioDurationMs != nil &&
+		*clip.RenderedDurationMs-*clip.AudioDurationMs < clipSilenceMs/2 {
+		return 0
+	}
+	return clipSilenceMs
+}
+
+// exportSubtitles stitches every clip's word timestamps onto the final video's
+// timeline and uploads SRT, WebVTT and full-project ASS sidecar files.
+//
+// clipDurationsMs must be aligned with clips (same order, same length) and hold
+// each rendered clip's duration — the offset of clip N is the sum of the
+// durations of clips 0..N-1, plus the silence prepended to clip N's narration
+// (see clipLeadInMs).
+// speakers orders dialogue roles for per-speaker ASS colors (nil = single narrator).
+func (w *Worker) exportSubtitles(ctx context.Context, projectID uuid.UUID, clips []models.Clip, clipDurationsMs []int, speakers []string) error {
+	var tracks []services.ClipWordTrack
+	elapsedMs := 0
+	for i, clip := range clips {
+		words, err := w.loadWordTimestamps(ctx, clip.ID)
+		if err != nil {
+			joblog.Warnf(ctx, "Clip %d word timestamps unavailable, captions will skip it: %v", clip.ClipIndex, err)
+		}
+		if len(words) > 0 {
+			tracks = append(tracks, services.ClipWordTrack{
+				Words:     words,
+				OffsetSec: float64(elapsedMs+clipLeadInMs(clip)) / 1000.0,
+			})
+		}
+		elapsedMs += clipDurationsMs[i]
+	}
+
+	if len(tracks) == 0 {
+		return fmt.Errorf("no clip has word timestamps")
+	}
+
+	cues := services.BuildCaptionCues(tracks)
+	subParams := services.SubtitleParamsForResolution(w.ffmpeg.Resolution)
+	subParams.Speakers = speakers
+	assContent, err := services.BuildASSSubtitles(services.MergeWordTracks(tracks), 0, subParams)
+	if err != nil {
+		return fmt.Errorf("failed to build ASS subtitles: %w", err)
+	}
+
+	exports := []struct {
+		assetType   models.AssetType
+		filename    string
+		contentType string
+		content     string
+	}{
+		{models.AssetTypeSubtitlesSRT, "final.srt", "application/x-subrip", services.FormatSRT(cues)},
+		{models.AssetTypeSubtitlesVTT, "final.vtt", "text/vtt", services.FormatWebVTT(cues)},
+		{models.AssetTypeSubtitlesASS, "final.ass", "text/x-ssa", assContent},
+	}
+
+	for _, export := range exports {
+		data := []byte(export.content)
+		asset := &models.Asset{
+			ID:            uuid.New(),
+			ProjectID:     projectID,
+			Type:          export.assetType,
+			StorageBucket: w.storage.Bucket,
+			StoragePath:   w.storage.GenerateStoragePath(projectID, export.filename),
+			ContentType:   strPtr(export.contentType),
+			ByteSize:      int64Ptr(int64(len(data))),
+		}
+
+		if err := w.uploadWithLimit(ctx, export.filename, func() error {
+			return w.storage.Upload(ctx, asset.StoragePath, data, export.contentType)
+		}); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", export.filename, err)
+		}
+
+		if err := w.db.CreateAsset(ctx, asset); err != nil {
+			return fmt.Errorf("failed to save %s asset: %w", export.filename, err)
+		}
+	}
+
+	joblog.Printf(ctx, "Exported sidecar subtitles for project %s (%d cues from %d clips)", projectID, len(cues), len(tracks))
+	return nil
+}
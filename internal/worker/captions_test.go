@@ -0,0 +1,25 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/bobarin/episod/internal/models"
+)
+
+func TestClipLeadInMs(t *testing.T) {
+	ms := func(n int) *int { return &n }
+	tests := []struct {
+		name string
+		clip models.Clip
+		want int
+	}{
+		{"silence prepended", models.Clip{AudioDurationMs: ms(4000), RenderedDurationMs: ms(4533)}, clipSilenceMs},
+		{"raw audio rendered", models.Clip{AudioDurationMs: ms(4000), RenderedDurationMs: ms(4033)}, 0},
+		{"not measured", models.Clip{AudioDurationMs: ms(4000)}, clipSilenceMs},
+	}
+	for _, tt := range tests {
+		if got := clipLeadInMs(tt.clip); got != tt.want {
+			t.Errorf("%s: clipLeadInMs = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/services"
+	"github.com/google/uuid"
+)
+
+// clipCheckpoint holds the outputs a previous attempt of a clip already
+// stored, so a retried process_clip only runs the missing steps. A nil field
+// means the step has to run (again).
+type clipCheckpoint struct {
+	image     *models.Asset
+	imageData []byte
+
+	aiVideoData []byte // Made from image; nil when the last attempt fell back to Ken Burns
+	kenBurns    bool   // Keep the Ken Burns fallback instead of generating an AI video (re-narration)
+
+	audio     *models.Asset
+	audioData []byte
+
+	words []services.WordTimestamp // Final subtitle words (aligned, speaker-tagged)
+
+	staged *clipNarration // Re-narration: outputs are collected here instead of switching the clip to them
+}
+
+// loadClipCheckpoint downloads the outputs stored by earlier attempts of
+// clip. Outputs that can't be loaded are logged and left to be regenerated.
+func (w *Worker) loadClipCheckpoint(ctx context.Context, clip *models.Clip) *clipCheckpoint {
+	cp := &clipCheckpoint{}
+
+	if clip.ImageAssetID != nil {
+		if asset, data, err := w.downloadAsset(ctx, *clip.ImageAssetID); err != nil {
+			joblog.Warnf(ctx, "Clip %d: stored image unusable, regenerating: %v", clip.ClipIndex, err)
+		} else {
+			cp.image, cp.imageData = asset, data
+		}
+	}
+
+	// An AI video only matches the image it was made from: one older than
+	// the image belongs to an earlier, replaced image
+	if cp.image != nil {
+		if asset, err := w.db.GetClipAssetByType(ctx, clip.ID, models.AssetTypeAIVideo); err == nil && !asset.CreatedAt.Before(cp.image.CreatedAt) {
+			if data, err := w.storage.Download(ctx, asset.StoragePath); err != nil {
+				joblog.Warnf(ctx, "Clip %d: stored AI video unusable, regenerating: %v", clip.ClipIndex, err)
+			} else {
+				cp.aiVideoData = data
+			}
+		}
+	}
+
+	if clip.AudioAssetID != nil && clip.AudioDurationMs != nil {
+		if asset, data, err := w.downloadAsset(ctx, *clip.AudioAssetID); err != nil {
+			joblog.Warnf(ctx, "Clip %d: stored audio unusable, regenerating: %v", clip.ClipIndex, err)
+		} else {
+			cp.audio, cp.audioData = asset, data
+		}
+	}
+
+	// Word timings belong to the audio they were transcribed from; older
+	// ones were made for an earlier, replaced narration
+	if cp.audio != nil {
+		if asset, err := w.db.GetClipAssetByType(ctx, clip.ID, models.AssetTypeWordTimestamps); err != nil || asset.CreatedAt.Before(cp.audio.CreatedAt) {
+			return cp
+		}
+		if words, err := w.loadWordTimestamps(ctx, clip.ID); err != nil {
+			joblog.Warnf(ctx, "Clip %d: stored word timestamps unusable, redoing them: %v", clip.ClipIndex, err)
+		} else if len(words) > 0 {
+			cp.words = words
+		}
+	}
+
+	return cp
+}
+
+// resumed reports whether any step can be skipped.
+func (cp *clipCheckpoint) resumed() bool {
+	return cp.image != nil || cp.audio != nil
+}
+
+// downloadAsset fetches an asset row and its bytes.
+func (w *Worker) downloadAsset(ctx context.Context, id uuid.UUID) (*models.Asset, []byte, error) {
+	asset, err := w.db.GetAsset(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := w.storage.Download(ctx, asset.StoragePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to download %s: %w", asset.Type, err)
+	}
+	return asset, data, nil
+}
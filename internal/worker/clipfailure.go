@@ -0,0 +1,184 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/services"
+	"github.com/google/uuid"
+)
+
+// ---------------------------------------------------------------------------
+// Partial-failure policy
+//
+// A project's clip_failure_policy decides what happens to a clip that fails,
+// so one bad clip doesn't keep the whole video from rendering:
+//
+//   - rewrite_prompt:  a failed image is retried once more with a prompt the
+//     planner LLM rewrote around the error (refusals are already rewritten
+//     up to IMAGE_REWRITE_ATTEMPTS times whatever the policy)
+//   - fallback_visual: a failed image is replaced by the nearest clip's
+//     image, or by a text card of the script when no neighbour has one
+//   - skip:            a clip that fails for any reason is dropped and the
+//     video renders without it
+//   - fail (default):  the clip stays failed
+//
+// The recovery used and the error behind it are recorded on the clip.
+// ---------------------------------------------------------------------------
+
+// clipFailurePolicy returns the project's policy, defaulting to fail.
+func clipFailurePolicy(project *models.Project) models.ClipFailurePolicy {
+	if project.ClipFailurePolicy == nil {
+		return models.ClipFailurePolicyFail
+	}
+	return models.ClipFailurePolicy(*project.ClipFailurePolicy)
+}
+
+// recoverImage applies the project's policy to a failed image generation and
+// returns the stored replacement image and the recovery used. The returned
+// error is genErr itself when the policy doesn't cover image failures.
+func (w *Worker) recoverImage(ctx context.Context, project *models.Project, clip *models.Clip, preset *models.GraphicsPreset, opts *services.ImageGenOptions, genErr error) (*models.Asset, []byte, models.ClipRecovery, error) {
+	if ctx.Err() != nil {
+		return nil, nil, "", genErr // The other pipeline failed; nothing to recover
+	}
+
+	var (
+		asset    *models.Asset
+		data     []byte
+		recovery models.ClipRecovery
+		err      error
+	)
+	switch clipFailurePolicy(project) {
+	case models.ClipFailurePolicyRewritePrompt:
+		recovery = models.ClipRecoveryRewrittenPrompt
+		asset, data, err = w.imageFromRewrittenPrompt(ctx, project.ID, clip, preset, opts, genErr)
+	case models.ClipFailurePolicyFallbackVisual:
+		recovery = models.ClipRecoveryNeighbourImage
+		if asset, data, err = w.neighbourImage(ctx, clip); err != nil {
+			joblog.Printf(ctx, "Clip %d: %v, using a text card", clip.ClipIndex, err)
+			recovery = models.ClipRecoveryTextCard
+			asset, data, err = w.textCardImage(ctx, project.ID, clip)
+		}
+	default:
+		return nil, nil, "", genErr
+	}
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("%w (%s recovery failed: %v)", genErr, recovery, err)
+	}
+
+	joblog.Event(ctx, fmt.Sprintf("Clip %d: image generation failed, recovered with %s", clip.ClipIndex, recovery), map[string]interface{}{
+		"clip_id":  clip.ID,
+		"recovery": recovery,
+		"reason":   genErr.Error(),
+	})
+	if err := w.db.SetClipRecovery(ctx, clip.ID, recovery, genErr.Error()); err != nil {
+		joblog.Warnf(ctx, "Clip %d: could not record recovery: %v", clip.ClipIndex, err)
+	}
+	return asset, data, recovery, nil
+}
+
+// imageFromRewrittenPrompt has the image prompt rewritten around the failure
+// and generates the image once more.
+func (w *Worker) imageFromRewrittenPrompt(ctx context.Context, projectID uuid.UUID, clip *models.Clip, preset *models.GraphicsPreset, opts *services.ImageGenOptions, genErr error) (*models.Asset, []byte, error) {
+	prompt, err := w.openai.RewriteImagePrompt(ctx, clip.ImagePrompt, genErr.Error())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to rewrite image prompt: %w", err)
+	}
+	joblog.Printf(ctx, "Clip %d: retrying image with rewritten prompt: %s", clip.ClipIndex, prompt)
+
+	var data []byte
+	err = w.withSemaphore(ctx, w.geminiSem, fmt.Sprintf("Gemini:clip_%d_rewritten", clip.ClipIndex), func() error {
+		var genErr error
+		data, genErr = w.gemini.GenerateImage(ctx, prompt, preset, opts)
+		return genErr
+	})
+	w.recordPromptRewrite(ctx, clip, prompt, genErr.Error(), err == nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	asset, err := w.storeClipImage(ctx, projectID, clip, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return asset, data, nil
+}
+
+// neighbourImage reuses the image of the nearest clip that has one (the
+// earlier clip on a tie). Text cards are skipped — they show another clip's
+// script. Neighbours still being generated don't count yet.
+func (w *Worker) neighbourImage(ctx context.Context, clip *models.Clip) (*models.Asset, []byte, error) {
+	clips, err := w.db.GetProjectClips(ctx, clip.ProjectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get clips: %w", err)
+	}
+
+	distance := func(c models.Clip) int {
+		if c.ClipIndex < clip.ClipIndex {
+			return 2 * (clip.ClipIndex - c.ClipIndex)
+		}
+		return 2*(c.ClipIndex-clip.ClipIndex) + 1
+	}
+	sort.Slice(clips, func(i, j int) bool { return distance(clips[i]) < distance(clips[j]) })
+
+	for _, neighbour := range clips {
+		if neighbour.ID == clip.ID || neighbour.ImageAssetID == nil ||
+			(neighbour.Recovery != nil && models.ClipRecovery(*neighbour.Recovery) == models.ClipRecoveryTextCard) {
+			continue
+		}
+		source, data, err := w.downloadAsset(ctx, *neighbour.ImageAssetID)
+		if err != nil {
+			joblog.Warnf(ctx, "Clip %d: image of clip %d unusable: %v", clip.ClipIndex, neighbour.ClipIndex, err)
+			continue
+		}
+
+		// A new asset row for this clip; the storage object is shared
+		asset := *source
+		asset.ID = uuid.New()
+		asset.ClipID = &clip.ID
+		if err := w.db.CreateAsset(ctx, &asset); err != nil {
+			return nil, nil, fmt.Errorf("failed to save image asset: %w", err)
+		}
+		joblog.Printf(ctx, "Clip %d: using the image of clip %d", clip.ClipIndex, neighbour.ClipIndex)
+		return &asset, data, nil
+	}
+	return nil, nil, fmt.Errorf("no neighbouring clip has an image yet")
+}
+
+// textCardImage renders the clip's script as a text card and stores it as
+// the clip's image.
+func (w *Worker) textCardImage(ctx context.Context, projectID uuid.UUID, clip *models.Clip) (*models.Asset, []byte, error) {
+	cardPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("text_card_%s.png", clip.ID.String()))
+	defer w.ffmpeg.Cleanup(cardPath)
+
+	if err := w.ffmpeg.RenderTextCard(ctx, services.PlainScript(clip.Script), cardPath); err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(cardPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read text card: %w", err)
+	}
+
+	asset, err := w.storeClipImage(ctx, projectID, clip, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return asset, data, nil
+}
+
+// skipClip drops a failed clip from the video (skip policy).
+func (w *Worker) skipClip(ctx context.Context, clip *models.Clip, cause error) error {
+	joblog.Event(ctx, fmt.Sprintf("Clip %d: failed, skipping it", clip.ClipIndex), map[string]interface{}{
+		"clip_id":  clip.ID,
+		"recovery": models.ClipRecoverySkipped,
+		"reason":   cause.Error(),
+	})
+	if err := w.db.SkipClip(ctx, clip.ID, cause.Error()); err != nil {
+		return fmt.Errorf("failed to skip clip: %w", err)
+	}
+	return nil
+}
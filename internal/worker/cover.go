@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/services"
+	"github.com/google/uuid"
+)
+
+// coverAssetTypes maps each cover size to the asset type it's stored as.
+var coverAssetTypes = map[string]models.AssetType{
+	services.CoverLandscape.Name: models.AssetTypeCoverLandscape,
+	services.CoverPortrait.Name:  models.AssetTypeCoverPortrait,
+}
+
+// exportCovers renders and uploads the project's covers in every platform
+// size: the first clip's image (the hook) with the title overlaid in the
+// style of the project's graphics preset. clips are the clips of the final
+// video, in order.
+func (w *Worker) exportCovers(ctx context.Context, project *models.Project, clips []models.Clip) error {
+	var preset *models.GraphicsPreset
+	if project.GraphicsPresetID != nil {
+		var err error
+		if preset, err = w.db.GetGraphicsPreset(ctx, *project.GraphicsPresetID); err != nil {
+			joblog.Warnf(ctx, "Graphics preset unavailable, covers use the caption style: %v", err)
+		}
+	}
+	style := services.CoverStyleFor(preset)
+
+	imagePath := ""
+	if clip := coverClip(clips); clip != nil {
+		_, data, err := w.downloadAsset(ctx, *clip.ImageAssetID)
+		if err != nil {
+			joblog.Warnf(ctx, "Clip %d image unavailable, cover uses a plain background: %v", clip.ClipIndex, err)
+		} else {
+			imagePath = w.ffmpeg.CreateTempFile(fmt.Sprintf("cover_source_%s.png", project.ID))
+			if err := os.WriteFile(imagePath, data, 0644); err != nil {
+				return fmt.Errorf("failed to write cover image: %w", err)
+			}
+			defer w.ffmpeg.Cleanup(imagePath)
+		}
+	}
+
+	title := coverTitle(project)
+	for _, size := range services.CoverSizes {
+		filename := fmt.Sprintf("cover_%s.jpg", size.Name)
+		outputPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("cover_%s_%s.jpg", size.Name, project.ID))
+		defer w.ffmpeg.Cleanup(outputPath)
+
+		if err := w.ffmpeg.RenderCover(ctx, imagePath, title, size, style, outputPath); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(outputPath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+
+		asset := &models.Asset{
+			ID:            uuid.New(),
+			ProjectID:     project.ID,
+			Type:          coverAssetTypes[size.Name],
+			StorageBucket: w.storage.Bucket,
+			StoragePath:   w.storage.GenerateStoragePath(project.ID, filename),
+			ContentType:   strPtr("image/jpeg"),
+			ByteSize:      int64Ptr(int64(len(data))),
+		}
+
+		if err := w.uploadWithLimit(ctx, filename, func() error {
+			return w.storage.Upload(ctx, asset.StoragePath, data, "image/jpeg")
+		}); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", filename, err)
+		}
+
+		if err := w.db.CreateAsset(ctx, asset); err != nil {
+			return fmt.Errorf("failed to save %s asset: %w", filename, err)
+		}
+	}
+
+	joblog.Printf(ctx, "Exported %d covers titled %q", len(services.CoverSizes), title)
+	return nil
+}
+
+// coverClip returns the first clip with a generated image; text cards show
+// a script and make poor covers.
+func coverClip(clips []models.Clip) *models.Clip {
+	for i, clip := range clips {
+		if clip.ImageAssetID == nil ||
+			(clip.Recovery != nil && models.ClipRecovery(*clip.Recovery) == models.ClipRecoveryTextCard) {
+			continue
+		}
+		return &clips[i]
+	}
+	return nil
+}
+
+// coverTitle is the first platform title planning wrote, or the topic.
+func coverTitle(project *models.Project) string {
+	for _, platform := range models.PublishingPlatforms {
+		if title := project.Publishing[platform].Title; title != "" {
+			return title
+		}
+	}
+	return project.Topic
+}
@@ -0,0 +1,154 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/services"
+	"github.com/google/uuid"
+)
+
+// dialogueGapMs is the pause inserted between consecutive dialogue lines,
+// long enough to read as a change of speaker without dragging the pacing.
+const dialogueGapMs = 300
+
+// resolveSpeakerVoices returns the speaker role → voice ID map for a project.
+// The series' default_voice_profile "speakers" map is applied first, then the
+// project's own speaker_voices override it role by role. Returns nil when no
+// roles are mapped (single-narrator project).
+func (w *Worker) resolveSpeakerVoices(ctx context.Context, project *models.Project) map[string]string {
+	voices := make(map[string]string)
+
+	if project.SeriesID != nil {
+		series, err := w.db.GetSeries(ctx, *project.SeriesID)
+		if err != nil {
+			joblog.Warnf(ctx, "Could not load series %s voice profile: %v", *project.SeriesID, err)
+		} else if speakers, ok := series.DefaultVoiceProfile["speakers"].(map[string]interface{}); ok {
+			mergeSpeakerVoices(voices, speakers)
+		}
+	}
+	mergeSpeakerVoices(voices, project.SpeakerVoices)
+
+	if len(voices) == 0 {
+		return nil
+	}
+	return voices
+}
+
+// mergeSpeakerVoices copies string-valued role → voice ID entries from a JSONB map.
+func mergeSpeakerVoices(dst map[string]string, src map[string]interface{}) {
+	for role, v := range src {
+		if voiceID, ok := v.(string); ok && role != "" && voiceID != "" {
+			dst[role] = voiceID
+		}
+	}
+}
+
+// synthesizeDialogue voices each of a clip's speaker-tagged lines with the
+// speaker's voice and joins the segments into one MP3 with dialogueGapMs pauses.
+//
+// base carries the clip-level request (style, project voice, language,
+// lexicon, settings); each line overrides its text, and its voice and style
+// when set. Lines whose speaker has no mapped voice keep base.VoiceID (the
+// project voice, or the provider default when empty). If every line came back with provider
+// word timings, they are shifted onto the joined track and returned; otherwise
+// WordTimestamps is nil and the caller transcribes the joined audio.
+func (w *Worker) synthesizeDialogue(ctx context.Context, tts services.TTSService, clip *models.Clip, base services.SpeechRequest, voices map[string]string) (*services.TTSResponse, error) {
+	var (
+		segmentPaths []string
+		words        []services.WordTimestamp
+		allTimed     = true
+		offsetMs     = 0
+	)
+	defer func() { w.ffmpeg.Cleanup(segmentPaths...) }()
+
+	for i, line := range clip.ScriptLines {
+		lineReq := base
+		lineReq.Text = line.Text
+		if line.VoiceStyleInstruction != "" {
+			lineReq.VoiceStyle = line.VoiceStyleInstruction
+		}
+		if voiceID, ok := voices[line.Speaker]; ok {
+			lineReq.VoiceID = voiceID
+		} else {
+			joblog.Printf(ctx, "Clip %d: no voice mapped for speaker %q, using the project voice", clip.ClipIndex, line.Speaker)
+		}
+
+		var resp *services.TTSResponse
+		if err := w.withSemaphore(ctx, w.ttsSem, fmt.Sprintf("TTS:clip_%d_line_%d", clip.ClipIndex, i), func() error {
+			var genErr error
+			resp, genErr = tts.GenerateSpeech(ctx, lineReq)
+			return genErr
+		}); err != nil {
+			return nil, fmt.Errorf("line %d (%s): %w", i, line.Speaker, err)
+		}
+
+		segmentPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("line_%s_%d.%s", clip.ID.String(), i, resp.Format))
+		segmentPaths = append(segmentPaths, segmentPath)
+		if err := os.WriteFile(segmentPath, resp.AudioData, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write line %d audio: %w", i, err)
+		}
+
+		if i > 0 {
+			offsetMs += dialogueGapMs
+		}
+		if len(resp.WordTimestamps) == 0 {
+			allTimed = false
+		}
+		for _, wt := range resp.WordTimestamps {
+			words = append(words, services.WordTimestamp{
+				Word:  wt.Word,
+				Start: wt.Start + float64(offsetMs)/1000.0,
+				End:   wt.End + float64(offsetMs)/1000.0,
+			})
+		}
+		offsetMs += resp.DurationMs
+
+		joblog.Printf(ctx, "Clip %d: line %d (%s) voiced (%dms)", clip.ClipIndex, i, line.Speaker, resp.DurationMs)
+	}
+
+	joinedPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("dialogue_%s.mp3", clip.ID.String()))
+	defer w.ffmpeg.Cleanup(joinedPath)
+	if err := w.ffmpeg.ConcatenateAudioWithGaps(ctx, segmentPaths, joinedPath, dialogueGapMs); err != nil {
+		return nil, err
+	}
+	audioData, err := os.ReadFile(joinedPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read joined dialogue audio: %w", err)
+	}
+
+	resp := &services.TTSResponse{
+		AudioData:  audioData,
+		DurationMs: offsetMs,
+		Format:     "mp3",
+	}
+	if info, err := services.ProbeAudio(audioData, "mp3"); err != nil {
+		joblog.Printf(ctx, "Clip %d: could not measure joined dialogue audio, using segment sum: %v", clip.ClipIndex, err)
+	} else {
+		resp.DurationMs = info.DurationMs
+		resp.SampleRate = info.SampleRate
+		resp.Channels = info.Channels
+	}
+	if allTimed {
+		resp.WordTimestamps = words
+	}
+
+	return resp, nil
+}
+
+// projectSpeakers returns a project's ordered dialogue speaker roles, or nil
+// for single-narrator projects (or if the project can't be loaded).
+func (w *Worker) projectSpeakers(ctx context.Context, projectID uuid.UUID) []string {
+	project, err := w.db.GetProject(ctx, projectID)
+	if err != nil {
+		return nil
+	}
+	voices := w.resolveSpeakerVoices(ctx, project)
+	if len(voices) < 2 {
+		return nil
+	}
+	return services.OrderSpeakers(voices)
+}
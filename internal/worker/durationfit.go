@@ -0,0 +1,289 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/queue"
+	"github.com/bobarin/episod/internal/services"
+	"golang.org/x/sync/errgroup"
+)
+
+// ---------------------------------------------------------------------------
+// Duration fitting
+//
+// The plan sizes scripts from an estimated speech rate; the real narration
+// often comes out noticeably longer or shorter, and so does the video. Once
+// every clip is rendered, the final render compares the video's length with
+// target_duration_seconds and, outside the tolerance:
+//
+//   - re-times the narration when a speech speed change within
+//     MaxSpeedChange closes the gap (every clip, so the pace stays even)
+//   - otherwise has the planner shorten or extend the scripts of the
+//     longest (or shortest) clips, by at most a third each
+//
+// Only the changed clips are re-synthesized and re-rendered over their
+// existing image and video, then the length is measured again, for up to
+// MaxRounds rounds. A re-narration is stored under new names and the clip
+// only switches to it once it is complete, so a clip whose re-narration
+// fails keeps its old version.
+// ---------------------------------------------------------------------------
+
+// DurationFitConfig controls duration fitting.
+type DurationFitConfig struct {
+	// Tolerance is how far the video may be from its target length, as a
+	// fraction of it (0 = fitting disabled).
+	Tolerance float64
+
+	// MaxSpeedChange bounds the speech pace, as a fraction of the voice's
+	// normal speed (0.1 = 0.9x to 1.1x).
+	MaxSpeedChange float64
+
+	// MaxRounds is how often clips are adjusted and the video re-measured.
+	MaxRounds int
+}
+
+// maxScriptChange is the largest share of a clip's narration a script
+// rewrite may add or remove.
+const maxScriptChange = 1.0 / 3
+
+// clipFit is the adjustment of one clip.
+type clipFit struct {
+	clip      int     // Position in the clips slice
+	pace      float64 // New speech pace (1 = normal)
+	targetSec float64 // Narration length to rewrite the script to (0 = keep the script)
+}
+
+// clipNarration holds the outputs of a re-narration until the clip is
+// switched to them.
+type clipNarration struct {
+	audio              *models.Asset
+	audioDurationMs    int
+	words              []services.WordTimestamp
+	video              *models.Asset
+	renderedDurationMs *int // nil when the render couldn't be measured
+}
+
+// fitDuration brings the clips' total length within the tolerance of the
+// project's target and returns the clips as rendered afterwards. Failures
+// are logged and leave clips as they were; only a cancelled ctx is an error.
+func (w *Worker) fitDuration(ctx context.Context, job *queue.Job, project *models.Project, clips []models.Clip) ([]models.Clip, error) {
+	cfg := w.durationFit
+	if cfg.Tolerance <= 0 || cfg.MaxRounds <= 0 || project.TargetDurationSeconds <= 0 {
+		return clips, nil
+	}
+	targetMs := project.TargetDurationSeconds * 1000
+	toleranceMs := int(float64(targetMs) * cfg.Tolerance)
+
+	for round := 1; ; round++ {
+		totalMs := clipsDurationMs(clips)
+		diffMs := totalMs - targetMs
+		if abs(diffMs) <= toleranceMs {
+			joblog.Printf(ctx, "Video length %.1fs is within %.0f%% of the %ds target", float64(totalMs)/1000, cfg.Tolerance*100, project.TargetDurationSeconds)
+			return clips, nil
+		}
+		if round > cfg.MaxRounds {
+			joblog.Warnf(ctx, "Video length %.1fs is still %+.1fs off the %ds target after %d rounds, rendering anyway",
+				float64(totalMs)/1000, float64(diffMs)/1000, project.TargetDurationSeconds, cfg.MaxRounds)
+			return clips, nil
+		}
+
+		fits := planDurationFit(clips, diffMs, cfg.MaxSpeedChange)
+		if len(fits) == 0 {
+			joblog.Warnf(ctx, "Video length %.1fs is %+.1fs off the %ds target but no clip can be adjusted",
+				float64(totalMs)/1000, float64(diffMs)/1000, project.TargetDurationSeconds)
+			return clips, nil
+		}
+
+		changes := make([]string, len(fits))
+		for i, fit := range fits {
+			changes[i] = fmt.Sprintf("clip %d: pace %.2f", clips[fit.clip].ClipIndex, fit.pace)
+			if fit.targetSec > 0 {
+				changes[i] = fmt.Sprintf("clip %d: script to %.1fs", clips[fit.clip].ClipIndex, fit.targetSec)
+			}
+		}
+		joblog.Event(ctx, fmt.Sprintf("Video length %.1fs is %+.1fs off the %ds target, adjusting %d clips (round %d/%d)",
+			float64(totalMs)/1000, float64(diffMs)/1000, project.TargetDurationSeconds, len(fits), round, cfg.MaxRounds),
+			map[string]interface{}{"total_ms": totalMs, "target_ms": targetMs, "changes": changes})
+
+		// Clips are re-narrated in parallel; the provider semaphores bound
+		// the load. Each clip's failure only affects that clip.
+		var (
+			g  errgroup.Group
+			mu sync.Mutex
+		)
+		adjusted := append([]models.Clip(nil), clips...)
+		for _, fit := range fits {
+			g.Go(func() error {
+				refitted, err := w.refitClip(ctx, job, project, clips[fit.clip], fit)
+				if err != nil {
+					joblog.Warnf(ctx, "Clip %d: duration fit failed, keeping it as it was: %v", clips[fit.clip].ClipIndex, err)
+					return nil
+				}
+				mu.Lock()
+				adjusted[fit.clip] = *refitted
+				mu.Unlock()
+				return nil
+			})
+		}
+		g.Wait()
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		clips = adjusted
+	}
+}
+
+// planDurationFit picks the clip adjustments that remove diffMs (positive:
+// the video is too long) from the narration.
+func planDurationFit(clips []models.Clip, diffMs int, maxSpeedChange float64) []clipFit {
+	narrationMs := 0
+	for _, clip := range clips {
+		if clip.AudioDurationMs != nil {
+			narrationMs += *clip.AudioDurationMs
+		}
+	}
+	if narrationMs <= 0 || diffMs >= narrationMs {
+		return nil
+	}
+
+	// Re-time every clip when the speed change stays within limits
+	speedup := float64(narrationMs) / float64(narrationMs-diffMs)
+	var fits []clipFit
+	for i, clip := range clips {
+		if clip.AudioDurationMs == nil {
+			continue
+		}
+		pace := clipPace(clip) * speedup
+		if math.Abs(pace-1) > maxSpeedChange+1e-9 {
+			fits = nil
+			break
+		}
+		fits = append(fits, clipFit{clip: i, pace: pace})
+	}
+	if len(fits) > 0 {
+		return fits
+	}
+
+	// Otherwise rewrite scripts, longest clips first when shortening and
+	// shortest first when extending. Dialogue scripts come from their lines
+	// and are left alone.
+	order := make([]int, 0, len(clips))
+	for i, clip := range clips {
+		if clip.AudioDurationMs != nil && len(clip.ScriptLines) == 0 {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		di, dj := *clips[order[i]].AudioDurationMs, *clips[order[j]].AudioDurationMs
+		if diffMs > 0 {
+			return di > dj
+		}
+		return di < dj
+	})
+
+	remainingMs := abs(diffMs)
+	for _, i := range order {
+		if remainingMs <= 0 {
+			break
+		}
+		durationMs := *clips[i].AudioDurationMs
+		changeMs := int(float64(durationMs) * maxScriptChange)
+		if changeMs > remainingMs {
+			changeMs = remainingMs
+		}
+		remainingMs -= changeMs
+		if diffMs > 0 {
+			changeMs = -changeMs
+		}
+		fits = append(fits, clipFit{clip: i, pace: clipPace(clips[i]), targetSec: float64(durationMs+changeMs) / 1000})
+	}
+	return fits
+}
+
+// refitClip re-narrates one clip with its new pace or script over its
+// existing image and video, and returns the clip as stored afterwards. On
+// failure the clip keeps its previous narration and video.
+func (w *Worker) refitClip(ctx context.Context, job *queue.Job, project *models.Project, clip models.Clip, fit clipFit) (*models.Clip, error) {
+	updated := clip
+	if fit.targetSec > 0 {
+		currentSec := float64(*clip.AudioDurationMs) / 1000
+		language := ""
+		if project.Language != nil {
+			language = *project.Language
+		}
+		script, err := w.openai.FitClipScript(ctx, clip.Script, currentSec, fit.targetSec, language)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fit script: %w", err)
+		}
+		joblog.Printf(ctx, "Clip %d: script refitted from %.1fs to ~%.1fs: %s", clip.ClipIndex, currentSec, fit.targetSec, script)
+		updated.Script = script
+	}
+	if fit.pace != clipPace(clip) {
+		pace := fit.pace
+		updated.SpeechPace = &pace
+	}
+
+	// Reuse the image and AI video, synthesize the narration again
+	updated.AudioAssetID = nil
+	cp := w.loadClipCheckpoint(ctx, &updated)
+	cp.kenBurns = cp.aiVideoData == nil
+	cp.staged = &clipNarration{}
+	if err := w.generateClip(ctx, job, &updated, project, cp); err != nil {
+		// Clears the failure generateClip recorded on the clip
+		if restoreErr := w.db.SetClipNarration(context.WithoutCancel(ctx), &clip); restoreErr != nil {
+			joblog.Errorf(ctx, "Clip %d: could not restore narration: %v", clip.ClipIndex, restoreErr)
+		}
+		return nil, err
+	}
+
+	staged := cp.staged
+	updated.AudioAssetID, updated.AudioDurationMs = &staged.audio.ID, &staged.audioDurationMs
+	updated.ClipVideoAssetID, updated.RenderedDurationMs = &staged.video.ID, staged.renderedDurationMs
+	if err := w.db.SetClipNarration(ctx, &updated); err != nil {
+		return nil, fmt.Errorf("failed to switch clip narration: %w", err)
+	}
+	// Stored even when empty: the previous words belong to the old narration
+	if err := w.storeWordTimestamps(ctx, job.ProjectID, &updated, staged.words); err != nil {
+		joblog.Warnf(ctx, "Clip %d: could not store word timestamps, sidecar captions will skip this clip: %v", clip.ClipIndex, err)
+	}
+
+	return w.db.GetClip(ctx, clip.ID)
+}
+
+// clipsDurationMs is the length of the video the clips make up: their
+// rendered durations, or narration plus lead-in silence when not measured.
+func clipsDurationMs(clips []models.Clip) int {
+	total := 0
+	for _, clip := range clips {
+		switch {
+		case clip.RenderedDurationMs != nil:
+			total += *clip.RenderedDurationMs
+		case clip.AudioDurationMs != nil:
+			total += *clip.AudioDurationMs + clipSilenceMs
+		case clip.EstimatedDurationSec != nil:
+			total += *clip.EstimatedDurationSec * 1000
+		}
+	}
+	return total
+}
+
+// clipPace returns the clip's speech pace (1 = normal).
+func clipPace(clip models.Clip) float64 {
+	if clip.SpeechPace == nil {
+		return 1
+	}
+	return *clip.SpeechPace
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
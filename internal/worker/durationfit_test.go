@@ -0,0 +1,112 @@
+package worker
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bobarin/episod/internal/models"
+)
+
+// fitClip builds a clip with the given narration length (0 = not narrated)
+// and speech pace (0 = normal).
+func fitClip(audioMs int, pace float64) models.Clip {
+	clip := models.Clip{}
+	if audioMs > 0 {
+		clip.AudioDurationMs = &audioMs
+	}
+	if pace > 0 {
+		clip.SpeechPace = &pace
+	}
+	return clip
+}
+
+func TestPlanDurationFit(t *testing.T) {
+	dialogue := fitClip(20000, 0)
+	dialogue.ScriptLines = models.ScriptLines{{Speaker: "narrator", Text: "Hello."}}
+
+	tests := []struct {
+		name   string
+		clips  []models.Clip
+		diffMs int
+		want   string // clip:pace:targetSec per fit
+	}{
+		{
+			name:   "speed up every clip",
+			clips:  []models.Clip{fitClip(10000, 0), fitClip(10000, 0)},
+			diffMs: 1000,
+			want:   "0:1.053:0.0 1:1.053:0.0",
+		},
+		{
+			name:   "slow down every clip",
+			clips:  []models.Clip{fitClip(10000, 0), fitClip(0, 0), fitClip(10000, 0)},
+			diffMs: -1000,
+			want:   "0:0.952:0.0 2:0.952:0.0",
+		},
+		{
+			name:   "existing pace pushes past the limit",
+			clips:  []models.Clip{fitClip(10000, 1.05), fitClip(10000, 0)},
+			diffMs: 1000,
+			want:   "0:1.050:9.0",
+		},
+		{
+			name:   "shorten the longest clip",
+			clips:  []models.Clip{fitClip(10000, 0), fitClip(20000, 0), fitClip(5000, 0)},
+			diffMs: 6000,
+			want:   "1:1.000:14.0",
+		},
+		{
+			name:   "shorten several clips by at most a third",
+			clips:  []models.Clip{fitClip(9000, 0), fitClip(12000, 0), fitClip(6000, 0)},
+			diffMs: 8000,
+			want:   "1:1.000:8.0 0:1.000:6.0 2:1.000:5.0",
+		},
+		{
+			name:   "extend the shortest clips first",
+			clips:  []models.Clip{fitClip(9000, 0), fitClip(3000, 0), fitClip(6000, 0)},
+			diffMs: -9000,
+			want:   "1:1.000:4.0 2:1.000:8.0 0:1.000:12.0",
+		},
+		{
+			name:   "dialogue scripts are left alone",
+			clips:  []models.Clip{dialogue, fitClip(10000, 0)},
+			diffMs: 6000,
+			want:   "1:1.000:6.7",
+		},
+		{
+			name:   "no narration",
+			clips:  []models.Clip{fitClip(0, 0)},
+			diffMs: 1000,
+		},
+		{
+			name:   "gap longer than the narration",
+			clips:  []models.Clip{fitClip(10000, 0)},
+			diffMs: 10000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, fit := range planDurationFit(tt.clips, tt.diffMs, 0.1) {
+				got = append(got, fmt.Sprintf("%d:%.3f:%.1f", fit.clip, fit.pace, fit.targetSec))
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("got %q, want %q", strings.Join(got, " "), tt.want)
+			}
+		})
+	}
+}
+
+func TestClipsDurationMs(t *testing.T) {
+	rendered, audio, estimated := 4200, 3000, 5
+	clips := []models.Clip{
+		{RenderedDurationMs: &rendered, AudioDurationMs: &audio},
+		{AudioDurationMs: &audio},
+		{EstimatedDurationSec: &estimated},
+		{},
+	}
+	if got, want := clipsDurationMs(clips), 4200+3000+clipSilenceMs+5000; got != want {
+		t.Errorf("clipsDurationMs = %d, want %d", got, want)
+	}
+}
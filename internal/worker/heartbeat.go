@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/queue"
+	"github.com/google/uuid"
+)
+
+const (
+	heartbeatInterval = 10 * time.Second
+	heartbeatTTL      = 3 * heartbeatInterval // A worker missing 3 beats is gone
+)
+
+// runStatus is the worker's live state, reported by heartbeats and the
+// health endpoint.
+type runStatus struct {
+	id        string
+	hostname  string
+	startedAt time.Time
+	queues    atomic.Pointer[map[string]int] // Consumers per queue, set by Start
+
+	inFlight  atomic.Int64
+	jobs      sync.Map // In-flight job IDs
+	processed atomic.Int64
+	failed    atomic.Int64
+	draining  atomic.Bool
+	lastBeat  atomic.Int64 // Unix nanos of the last heartbeat written
+}
+
+func newRunStatus() *runStatus {
+	hostname, _ := os.Hostname()
+	return &runStatus{
+		id:        fmt.Sprintf("%s-%s", hostname, uuid.NewString()[:8]),
+		hostname:  hostname,
+		startedAt: time.Now(),
+	}
+}
+
+func (s *runStatus) concurrency() map[string]int {
+	if q := s.queues.Load(); q != nil {
+		return *q
+	}
+	return nil
+}
+
+// jobIDs lists the in-flight jobs.
+func (s *runStatus) jobIDs() []uuid.UUID {
+	var ids []uuid.UUID
+	s.jobs.Range(func(key, _ interface{}) bool {
+		ids = append(ids, key.(uuid.UUID))
+		return true
+	})
+	return ids
+}
+
+// heartbeat snapshots the status.
+func (s *runStatus) heartbeat() *queue.WorkerHeartbeat {
+	return &queue.WorkerHeartbeat{
+		ID:          s.id,
+		Hostname:    s.hostname,
+		Concurrency: s.concurrency(),
+		InFlight:    s.inFlight.Load(),
+		JobIDs:      s.jobIDs(),
+		Processed:   s.processed.Load(),
+		Failed:      s.failed.Load(),
+		Draining:    s.draining.Load(),
+		StartedAt:   s.startedAt,
+		LastSeen:    time.Now(),
+	}
+}
+
+// startHeartbeat writes a heartbeat every heartbeatInterval until the
+// returned stop is called, which also removes it. It keeps beating after
+// parent is cancelled, through the drain.
+func (w *Worker) startHeartbeat(parent context.Context) (stop func()) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(parent))
+	done := make(chan struct{})
+
+	beat := func() {
+		if err := w.queue.PutHeartbeat(ctx, w.status.heartbeat(), heartbeatTTL); err != nil {
+			joblog.Warnf(ctx, "Failed to write worker heartbeat: %v", err)
+			return
+		}
+		w.status.lastBeat.Store(time.Now().UnixNano())
+	}
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			beat()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+		delCtx, delCancel := context.WithTimeout(context.WithoutCancel(parent), 5*time.Second)
+		defer delCancel()
+		if err := w.queue.DeleteHeartbeat(delCtx, w.status.id); err != nil {
+			joblog.Warnf(delCtx, "Failed to remove worker heartbeat: %v", err)
+		}
+	}
+}
+
+// HealthHandler serves the worker's status as JSON: 200 while it is taking
+// jobs and reaching Redis, 503 while draining or when heartbeats fail (so
+// an orchestrator stops routing to, or restarts, the process).
+func (w *Worker) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		hb := w.status.heartbeat()
+		status, code := "ok", http.StatusOK
+		lastBeat := time.Unix(0, w.status.lastBeat.Load())
+		switch {
+		case hb.Draining:
+			status, code = "draining", http.StatusServiceUnavailable
+		case time.Since(lastBeat) > heartbeatTTL:
+			status, code = "unhealthy", http.StatusServiceUnavailable
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(code)
+		json.NewEncoder(rw).Encode(struct {
+			Status string `json:"status"`
+			*queue.WorkerHeartbeat
+		}{status, hb})
+	})
+}
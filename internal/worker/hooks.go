@@ -0,0 +1,142 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/queue"
+	"github.com/bobarin/episod/internal/services"
+	"github.com/google/uuid"
+)
+
+// ---------------------------------------------------------------------------
+// Hook variants
+//
+// The plan carries alternative versions of the first clip (the hook). They
+// are stored as clip rows with clip_index 0 and hook_variant 1, 2, ... but
+// are not generated with the video. Once the project is completed, a render
+// request (POST /v1/projects/{id}/hooks/{variant}/render) enqueues a
+// process_clip job for the variant, which generates that one clip and
+// composes the completed video again with it in place of clip 0. The rest
+// of the video is reused as rendered.
+// ---------------------------------------------------------------------------
+
+// clipFileName names a clip's stored file: clip_{index}_{suffix}, or
+// hook_{variant}_{suffix} for a hook variant so it doesn't replace clip 0's.
+func clipFileName(clip *models.Clip, suffix string) string {
+	if clip.HookVariant != nil {
+		return fmt.Sprintf("hook_%d_%s", *clip.HookVariant, suffix)
+	}
+	return fmt.Sprintf("clip_%d_%s", clip.ClipIndex, suffix)
+}
+
+// createHookVariants stores the plan's hook variants, numbered from 1.
+func (w *Worker) createHookVariants(ctx context.Context, projectID uuid.UUID, variants []services.ClipPlan, sources []services.SourceSnippet) error {
+	for i, variantPlan := range variants {
+		variant := &models.Clip{
+			ID:                    uuid.New(),
+			ProjectID:             projectID,
+			ClipIndex:             0,
+			Script:                variantPlan.Script,
+			ScriptLines:           variantPlan.Lines,
+			VoiceStyleInstruction: &variantPlan.VoiceStyleInstruction,
+			ImagePrompt:           variantPlan.ImagePrompt,
+			VideoPrompt:           &variantPlan.VideoPrompt,
+			EstimatedDurationSec:  intPtr(variantPlan.EstimatedDurationSec),
+			HookVariant:           intPtr(i + 1),
+			Citations:             services.CitationsFor(variantPlan.Citations, sources),
+			Status:                models.ClipStatusPending,
+		}
+		if err := w.db.CreateClip(ctx, variant); err != nil {
+			return fmt.Errorf("failed to create hook variant: %w", err)
+		}
+	}
+	if len(variants) > 0 {
+		joblog.Printf(ctx, "Stored %d hook variants", len(variants))
+	}
+	return nil
+}
+
+// processHookVariant generates a hook variant's clip, unless a previous
+// attempt already did, and renders the video with it. The project's skip
+// policy doesn't apply: a failed variant just fails its render request.
+func (w *Worker) processHookVariant(ctx context.Context, job *queue.Job, variant *models.Clip) error {
+	if variant.VariantVideoAssetID != nil {
+		joblog.Printf(ctx, "Hook variant %d: already rendered", *variant.HookVariant)
+		return nil
+	}
+
+	project, err := w.db.GetProject(ctx, job.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	clips, err := w.db.GetProjectClips(ctx, job.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to get clips: %w", err)
+	}
+
+	if variant.Status != models.ClipStatusRendered || variant.ClipVideoAssetID == nil {
+		// Narrate at the pace duration fitting gave the clip it replaces
+		for _, clip := range clips {
+			if clip.ClipIndex == 0 {
+				variant.SpeechPace = clip.SpeechPace
+			}
+		}
+		if err := w.generateClip(ctx, job, variant, project, w.loadClipCheckpoint(ctx, variant)); err != nil {
+			return err
+		}
+		if variant, err = w.db.GetClip(ctx, variant.ID); err != nil {
+			return fmt.Errorf("failed to get clip: %w", err)
+		}
+	}
+
+	return w.renderHookVariant(ctx, project, variant, clips)
+}
+
+// renderHookVariant composes the project's video with variant as its first
+// clip and stores it as the variant's video. Sidecar captions are only
+// exported for the main video.
+func (w *Worker) renderHookVariant(ctx context.Context, project *models.Project, variant *models.Clip, mainClips []models.Clip) error {
+	n := *variant.HookVariant
+	joblog.Printf(ctx, "Rendering the video with hook variant %d", n)
+
+	// The main video's clips with clip 0 replaced; a skipped clip 0 is
+	// put back as the variant
+	clips := []models.Clip{*variant}
+	for _, clip := range mainClips {
+		if clip.ClipIndex != 0 && clip.Status != models.ClipStatusSkipped {
+			clips = append(clips, clip)
+		}
+	}
+
+	videoData, _, err := w.composeVideo(ctx, project, fmt.Sprintf("%s_hook_%d", project.ID, n), clips)
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("final_hook_%d.mp4", n)
+	asset := &models.Asset{
+		ID:            uuid.New(),
+		ProjectID:     project.ID,
+		ClipID:        &variant.ID,
+		Type:          models.AssetTypeFinalVideo,
+		StorageBucket: w.storage.Bucket,
+		StoragePath:   w.storage.GenerateStoragePath(project.ID, filename),
+		ContentType:   strPtr("video/mp4"),
+		ByteSize:      int64Ptr(int64(len(videoData))),
+	}
+
+	if err := w.uploadWithLimit(ctx, filename, func() error {
+		return w.storage.Upload(ctx, asset.StoragePath, videoData, "video/mp4")
+	}); err != nil {
+		return fmt.Errorf("failed to upload hook variant video: %w", err)
+	}
+
+	if err := w.db.CreateAsset(ctx, asset); err != nil {
+		return fmt.Errorf("failed to save hook variant video asset: %w", err)
+	}
+
+	return w.db.SetClipVariantVideo(ctx, variant.ID, asset.ID)
+}
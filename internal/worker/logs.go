@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/queue"
+	"github.com/google/uuid"
+)
+
+// storeJobLog uploads a finished job's captured log as a logs asset
+// (projects/{project_id}/logs/{type}_{job_id}_{asset_id}.ndjson, one per
+// attempt of the job) and links it to the job.
+// Non-critical: failures are only written to the process log.
+func (w *Worker) storeJobLog(ctx context.Context, job *queue.Job, jobLog *joblog.Log) {
+	if jobLog.Len() == 0 {
+		return
+	}
+
+	data, err := jobLog.NDJSON()
+	if err != nil {
+		joblog.Warnf(ctx, "Job %s: could not encode job log: %v", job.ID, err)
+		return
+	}
+
+	assetID := uuid.New()
+	asset := &models.Asset{
+		ID:            assetID,
+		ProjectID:     job.ProjectID,
+		ClipID:        job.ClipID,
+		Type:          models.AssetTypeLogs,
+		StorageBucket: w.storage.Bucket,
+		StoragePath:   w.storage.GenerateStoragePath(job.ProjectID, fmt.Sprintf("logs/%s_%s_%s.ndjson", job.Type, job.ID, assetID)),
+		ContentType:   strPtr("application/x-ndjson"),
+		ByteSize:      int64Ptr(int64(len(data))),
+	}
+
+	if err := w.uploadWithLimit(ctx, fmt.Sprintf("job_%s_logs", job.ID), func() error {
+		return w.storage.Upload(ctx, asset.StoragePath, data, "application/x-ndjson")
+	}); err != nil {
+		joblog.Warnf(ctx, "Job %s: could not upload job log: %v", job.ID, err)
+		return
+	}
+	if err := w.db.CreateAsset(ctx, asset); err != nil {
+		joblog.Warnf(ctx, "Job %s: could not save job log asset: %v", job.ID, err)
+		return
+	}
+	if err := w.db.UpdateJobLogs(ctx, job.ID, asset.ID); err != nil {
+		joblog.Warnf(ctx, "Job %s: could not link job log: %v", job.ID, err)
+	}
+}
@@ -0,0 +1,288 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/queue"
+	"github.com/google/uuid"
+)
+
+// ---------------------------------------------------------------------------
+// Stalled-project reaper
+//
+// A project can stop progressing without any job failing: a worker dies
+// after rendering the last clip but before enqueueing render_final, a job is
+// popped from Redis and lost with its worker, ... The reaper periodically
+// finds projects with no activity (project, clip or job changes) for longer
+// than their status's timeout, works out from the clip rows and assets which
+// steps are missing, and re-enqueues exactly those. Projects that keep
+// stalling are failed with ErrorCode "stalled".
+//
+// A job that is merely slow (a long final render or duration fit) is not
+// lost: while a live worker's heartbeat reports it in flight, its project is
+// left alone however long it has been idle.
+// ---------------------------------------------------------------------------
+
+// ReaperConfig controls stalled-project recovery.
+type ReaperConfig struct {
+	// Interval between reaper passes (0 = reaper disabled). One pass runs
+	// per interval across all workers.
+	Interval time.Duration
+
+	// How long a project may show no activity in each status before it
+	// counts as stalled.
+	PlanningTimeout   time.Duration // queued and planning
+	GeneratingTimeout time.Duration
+	RenderingTimeout  time.Duration
+
+	// MaxRecoveries is how often one project is recovered before it is
+	// failed instead.
+	MaxRecoveries int
+}
+
+// runReaper runs reaper passes until ctx is cancelled.
+func (w *Worker) runReaper(ctx context.Context) {
+	if w.reaper.Interval <= 0 {
+		return
+	}
+	joblog.Printf(ctx, "Reaper started (interval: %s, timeouts: planning %s, generating %s, rendering %s)",
+		w.reaper.Interval, w.reaper.PlanningTimeout, w.reaper.GeneratingTimeout, w.reaper.RenderingTimeout)
+
+	ticker := time.NewTicker(w.reaper.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.reapStalledProjects(ctx)
+		}
+	}
+}
+
+// reapStalledProjects runs one pass over all in-progress statuses.
+func (w *Worker) reapStalledProjects(ctx context.Context) {
+	// Only one worker runs each pass, or a project would be recovered twice
+	locked, err := w.queue.TryLock(ctx, "reaper", w.reaper.Interval)
+	if err != nil {
+		joblog.Errorf(ctx, "[Reaper] Failed to take lock: %v", err)
+		return
+	}
+	if !locked {
+		return
+	}
+
+	// Jobs still waiting in Redis aren't lost, just behind a backlog
+	pending, err := w.queue.PendingJobIDs(ctx, queue.QueueGeneratePlan, queue.QueueProcessClip, queue.QueueRenderFinal)
+	if err != nil {
+		joblog.Errorf(ctx, "[Reaper] Failed to read queues: %v", err)
+		return
+	}
+
+	// Jobs live workers are running aren't lost, just slow
+	running, err := w.runningJobIDs(ctx)
+	if err != nil {
+		joblog.Errorf(ctx, "[Reaper] Failed to read worker heartbeats: %v", err)
+		return
+	}
+
+	for _, stage := range []struct {
+		status  models.ProjectStatus
+		timeout time.Duration
+	}{
+		{models.ProjectStatusQueued, w.reaper.PlanningTimeout},
+		{models.ProjectStatusPlanning, w.reaper.PlanningTimeout},
+		{models.ProjectStatusGenerating, w.reaper.GeneratingTimeout},
+		{models.ProjectStatusRendering, w.reaper.RenderingTimeout},
+	} {
+		ids, err := w.db.ListStalledProjects(ctx, stage.status, stage.timeout)
+		if err != nil {
+			joblog.Errorf(ctx, "[Reaper] %v", err)
+			continue
+		}
+		for _, id := range ids {
+			projectCtx := joblog.With(ctx, "project_id", id)
+			if err := w.recoverProject(projectCtx, id, stage.status, stage.timeout, pending, running); err != nil {
+				joblog.Errorf(projectCtx, "[Reaper] Failed to recover project %s: %v", id, err)
+			}
+		}
+	}
+}
+
+// runningJobIDs returns the jobs in flight on live workers, from their
+// heartbeats.
+func (w *Worker) runningJobIDs(ctx context.Context) (map[uuid.UUID]bool, error) {
+	heartbeats, err := w.queue.ListHeartbeats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	running := make(map[uuid.UUID]bool)
+	for _, hb := range heartbeats {
+		for _, id := range hb.JobIDs {
+			running[id] = true
+		}
+	}
+	return running, nil
+}
+
+// recoverProject re-enqueues the missing steps of a stalled project, or
+// fails it once it has used up its recoveries. Projects with a job still
+// queued in Redis or running on a live worker are left alone.
+func (w *Worker) recoverProject(ctx context.Context, projectID uuid.UUID, status models.ProjectStatus, idle time.Duration, pending, running map[uuid.UUID]bool) error {
+	jobs, err := w.db.GetProjectJobs(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if job.Status == models.JobStatusQueued && pending[job.ID] {
+			return nil // Waiting for a free worker
+		}
+		if job.Status == models.JobStatusRunning && running[job.ID] {
+			joblog.Printf(ctx, "[Reaper] Project %s idle for %s but its %s job %s is still running, leaving it", projectID, idle, job.Type, job.ID)
+			return nil
+		}
+	}
+
+	attempts, ok, err := w.db.RecordProjectRecovery(ctx, projectID, status)
+	if err != nil || !ok {
+		return err // !ok: the project moved on since it was listed
+	}
+
+	reason := fmt.Sprintf("no progress for %s while %s", idle, status)
+	if attempts > w.reaper.MaxRecoveries {
+		joblog.Errorf(ctx, "[Reaper] Project %s stalled (%s) after %d recoveries, failing it", projectID, reason, w.reaper.MaxRecoveries)
+		w.db.FailUnfinishedJobs(ctx, projectID, "Stalled: "+reason)
+		return w.db.UpdateProjectError(ctx, projectID, "stalled",
+			fmt.Sprintf("Project made no progress for %s while %s, even after %d recovery attempts", idle, status, w.reaper.MaxRecoveries))
+	}
+
+	joblog.Warnf(ctx, "[Reaper] Project %s stalled (%s), recovering (attempt %d/%d)", projectID, reason, attempts, w.reaper.MaxRecoveries)
+	if n, err := w.db.FailUnfinishedJobs(ctx, projectID, "Lost: "+reason+"; replaced by recovery"); err != nil {
+		return err
+	} else if n > 0 {
+		joblog.Printf(ctx, "[Reaper] Marked %d lost jobs of project %s as failed", n, projectID)
+	}
+
+	switch status {
+	case models.ProjectStatusQueued, models.ProjectStatusPlanning:
+		return w.recoverPlan(ctx, projectID)
+	default:
+		return w.recoverClips(ctx, projectID, status)
+	}
+}
+
+// recoverPlan generates the plan again. Clips of an interrupted plan are
+// removed first — the plan only continues once all its clips exist.
+func (w *Worker) recoverPlan(ctx context.Context, projectID uuid.UUID) error {
+	count, err := w.db.GetProjectClipCount(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to count clips: %w", err)
+	}
+	if count > 0 {
+		joblog.Printf(ctx, "[Reaper] Plan of project %s was interrupted after %d clips, regenerating it", projectID, count)
+		if err := w.db.DeleteProjectClips(ctx, projectID); err != nil {
+			return err
+		}
+		// The new plan gets its own version (and its own render_final)
+		if err := w.db.IncrementPlanVersion(ctx, projectID); err != nil {
+			return fmt.Errorf("failed to increment plan version: %w", err)
+		}
+	}
+
+	if err := w.db.UpdateProjectStatus(ctx, projectID, models.ProjectStatusQueued); err != nil {
+		return fmt.Errorf("failed to update project status: %w", err)
+	}
+	return w.enqueueRecoveryJob(ctx, projectID, nil, "generate_plan")
+}
+
+// recoverClips re-enqueues process_clip for every clip without a rendered
+// video, or render_final when all clips are rendered (or skipped).
+func (w *Worker) recoverClips(ctx context.Context, projectID uuid.UUID, status models.ProjectStatus) error {
+	clips, err := w.db.GetProjectClips(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to get clips: %w", err)
+	}
+	if len(clips) == 0 {
+		return w.recoverPlan(ctx, projectID)
+	}
+
+	var missing []models.Clip
+	for _, clip := range clips {
+		if steps := missingClipSteps(clip); len(steps) > 0 {
+			fields := map[string]interface{}{"clip_id": clip.ID, "clip_index": clip.ClipIndex, "status": clip.Status, "missing": steps}
+			if clip.ErrorMessage != nil {
+				fields["error"] = *clip.ErrorMessage
+			}
+			joblog.Event(ctx, fmt.Sprintf("[Reaper] Clip %d incomplete", clip.ClipIndex), fields)
+			missing = append(missing, clip)
+		}
+	}
+
+	if len(missing) == 0 {
+		// Same claim as the last clip makes: the plan version's single
+		// render_final job is requeued, never duplicated
+		finalJobID, claimed, err := w.db.ClaimFinalRender(ctx, projectID, status)
+		if err != nil || !claimed {
+			return err
+		}
+		joblog.Printf(ctx, "[Reaper] All %d clips of project %s are rendered, re-enqueueing final render", len(clips), projectID)
+		return w.queue.EnqueueRenderFinal(ctx, projectID, finalJobID)
+	}
+
+	if err := w.db.UpdateProjectStatus(ctx, projectID, models.ProjectStatusGenerating); err != nil {
+		return fmt.Errorf("failed to update project status: %w", err)
+	}
+	for _, clip := range missing {
+		if err := w.enqueueRecoveryJob(ctx, projectID, &clip.ID, "process_clip"); err != nil {
+			return err
+		}
+	}
+	joblog.Printf(ctx, "[Reaper] Re-enqueued %d/%d clips of project %s", len(missing), len(clips), projectID)
+	return nil
+}
+
+// missingClipSteps lists what a clip still lacks: "audio", "image" and/or
+// "render". Empty for a rendered or skipped clip.
+func missingClipSteps(clip models.Clip) []string {
+	if (clip.Status == models.ClipStatusRendered && clip.ClipVideoAssetID != nil) || clip.Status == models.ClipStatusSkipped {
+		return nil
+	}
+	var steps []string
+	if clip.AudioAssetID == nil {
+		steps = append(steps, "audio")
+	}
+	if clip.ImageAssetID == nil {
+		steps = append(steps, "image")
+	}
+	return append(steps, "render")
+}
+
+// enqueueRecoveryJob creates and enqueues a replacement job.
+func (w *Worker) enqueueRecoveryJob(ctx context.Context, projectID uuid.UUID, clipID *uuid.UUID, jobType string) error {
+	job := &models.Job{
+		ID:        uuid.New(),
+		ProjectID: projectID,
+		ClipID:    clipID,
+		Type:      jobType,
+		Status:    models.JobStatusQueued,
+	}
+	if err := w.db.CreateJob(ctx, job); err != nil {
+		return fmt.Errorf("failed to create %s job: %w", jobType, err)
+	}
+
+	var err error
+	switch jobType {
+	case "generate_plan":
+		err = w.queue.EnqueueGeneratePlan(ctx, projectID, job.ID)
+	case "process_clip":
+		err = w.queue.EnqueueProcessClip(ctx, projectID, *clipID, job.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to enqueue %s job: %w", jobType, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/bobarin/episod/internal/models"
+)
+
+// sourcesCardMs is how long the sources end card is shown.
+const sourcesCardMs = 5000
+
+// renderSourcesCard renders the end card listing the sources the clips cite
+// as a silent clip, and returns its path ("" when nothing is cited).
+func (w *Worker) renderSourcesCard(ctx context.Context, name string, clips []models.Clip) (string, error) {
+	entries := citedSources(clips)
+	if len(entries) == 0 {
+		return "", nil
+	}
+
+	cardPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("sources_%s.png", name))
+	defer w.ffmpeg.Cleanup(cardPath)
+	if err := w.ffmpeg.RenderSourcesCard(ctx, entries, cardPath); err != nil {
+		return "", err
+	}
+
+	clipPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("sources_%s.mp4", name))
+	if err := w.ffmpeg.RenderStillClip(ctx, cardPath, sourcesCardMs, clipPath); err != nil {
+		return "", err
+	}
+	return clipPath, nil
+}
+
+// citedSources lists the sources the clips cite, in source order, as
+// "{n}. {title} ({host})".
+func citedSources(clips []models.Clip) []string {
+	bySource := make(map[int]models.Citation)
+	for _, clip := range clips {
+		for _, citation := range clip.Citations {
+			bySource[citation.Source] = citation
+		}
+	}
+
+	numbers := make([]int, 0, len(bySource))
+	for n := range bySource {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	entries := make([]string, len(numbers))
+	for i, n := range numbers {
+		citation := bySource[n]
+		entries[i] = fmt.Sprintf("%d. %s", n, citation.Title)
+		if u, err := url.Parse(citation.URL); err == nil && u.Host != "" {
+			host := strings.TrimPrefix(u.Host, "www.")
+			if !strings.EqualFold(host, citation.Title) {
+				entries[i] += fmt.Sprintf(" (%s)", host)
+			}
+		}
+	}
+	return entries
+}
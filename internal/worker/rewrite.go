@@ -0,0 +1,73 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/metrics"
+	"github.com/bobarin/episod/internal/models"
+	"github.com/bobarin/episod/internal/services"
+)
+
+// generateClipImage generates a clip's image (bounded by geminiSem). When
+// Gemini refuses the prompt, the planner LLM rewrites it around the refusal
+// — same scene, compliant wording — and the image is retried, up to
+// imageRewrites times. Every rewrite is recorded on the clip.
+func (w *Worker) generateClipImage(ctx context.Context, clip *models.Clip, preset *models.GraphicsPreset, opts *services.ImageGenOptions) ([]byte, error) {
+	prompt := clip.ImagePrompt
+	var reason string
+	for attempt := 0; ; attempt++ {
+		label := fmt.Sprintf("Gemini:clip_%d", clip.ClipIndex)
+		if attempt > 0 {
+			label = fmt.Sprintf("Gemini:clip_%d_rewrite_%d", clip.ClipIndex, attempt)
+		}
+
+		var data []byte
+		err := w.withSemaphore(ctx, w.geminiSem, label, func() error {
+			var genErr error
+			data, genErr = w.gemini.GenerateImage(ctx, prompt, preset, opts)
+			return genErr
+		})
+		if attempt > 0 {
+			w.recordPromptRewrite(ctx, clip, prompt, reason, err == nil)
+		}
+
+		var refusal *services.ImageRefusalError
+		if !errors.As(err, &refusal) {
+			return data, err
+		}
+		metrics.IncImageRefusal(refusal.Reason)
+		if attempt >= w.imageRewrites {
+			if attempt > 0 {
+				return nil, fmt.Errorf("%w (after %d prompt rewrites)", err, attempt)
+			}
+			return nil, err
+		}
+
+		joblog.Warnf(ctx, "Clip %d: %v — rewriting the prompt (%d/%d)", clip.ClipIndex, refusal, attempt+1, w.imageRewrites)
+		reason = refusal.Error()
+		rewritten, rewriteErr := w.openai.RewriteImagePrompt(ctx, prompt, reason)
+		if rewriteErr != nil {
+			return nil, fmt.Errorf("%w (prompt rewrite failed: %v)", err, rewriteErr)
+		}
+		joblog.Printf(ctx, "Clip %d: rewritten image prompt: %s", clip.ClipIndex, rewritten)
+		prompt = rewritten
+	}
+}
+
+// recordPromptRewrite appends a rewritten image prompt and its outcome to
+// the clip's audit trail. Non-critical: failures are only logged.
+func (w *Worker) recordPromptRewrite(ctx context.Context, clip *models.Clip, prompt, reason string, succeeded bool) {
+	rewrite := models.PromptRewrite{
+		Prompt:    prompt,
+		Reason:    reason,
+		Succeeded: succeeded,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := w.db.AppendClipPromptRewrite(context.WithoutCancel(ctx), clip.ID, rewrite); err != nil {
+		joblog.Warnf(ctx, "Clip %d: could not record prompt rewrite: %v", clip.ClipIndex, err)
+	}
+}
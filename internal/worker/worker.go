@@ -2,18 +2,28 @@ package worker
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"math"
 	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/bobarin/episod/internal/db"
+	"github.com/bobarin/episod/internal/joblog"
+	"github.com/bobarin/episod/internal/metrics"
 	"github.com/bobarin/episod/internal/models"
 	"github.com/bobarin/episod/internal/queue"
 	"github.com/bobarin/episod/internal/services"
 	"github.com/bobarin/episod/internal/storage"
+	"github.com/bobarin/episod/internal/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -28,6 +38,16 @@ type Worker struct {
 	xaiVideo            *services.XAIVideoService // Optional: nil when XAI_VIDEO_ENABLED=false
 	ffmpeg              *services.FFmpegService
 	backgroundMusicPath string // Path to background music file (empty = no music)
+	subtitleAlignment   services.AlignmentMode
+	imageRewrites       int           // Rewrites of a refused image prompt (0 = none)
+	cacheEnabled        bool          // Reuse provider outputs for identical inputs
+	cacheTTL            time.Duration // 0 = cached outputs never expire
+	cacheMaxEntries     int           // 0 = unlimited
+	drainTimeout        time.Duration // How long in-flight jobs may finish during shutdown
+	status              *runStatus    // Heartbeat and health state
+	reaper              ReaperConfig  // Stalled-project recovery
+	durationFit         DurationFitConfig
+	sourceFetcher       services.SourceFetcher // Research mode source fetching
 
 	// Per-service semaphores — prevents rate-limit errors and resource exhaustion
 	// when multiple clips process concurrently. Each semaphore bounds the number
@@ -39,6 +59,45 @@ type Worker struct {
 	renderSem chan struct{} // FFmpeg render processes (bound: 2 — CPU intensive)
 }
 
+// Config holds worker settings that come from env config rather than services.
+// Passed from main.go alongside the service instances.
+type Config struct {
+	// BackgroundMusicPath is the music file mixed under the final video (empty = no music).
+	BackgroundMusicPath string
+
+	// SubtitleAlignment controls how Whisper timings become subtitle words:
+	// mapped onto the exact script (default) or used as transcribed.
+	SubtitleAlignment services.AlignmentMode
+
+	// ImageRewriteAttempts is how often a refused image prompt is rewritten
+	// and retried before the image counts as failed (0 = never).
+	ImageRewriteAttempts int
+
+	// CacheEnabled reuses earlier Gemini/TTS/xAI outputs for identical inputs.
+	// CacheTTL bounds how long an output is reused (0 = forever) and
+	// CacheMaxEntries how many are kept (0 = unlimited).
+	CacheEnabled    bool
+	CacheTTL        time.Duration
+	CacheMaxEntries int
+
+	// DrainTimeout is how long in-flight jobs may keep running after shutdown
+	// starts before they are cancelled and requeued (0 = 2 minutes).
+	DrainTimeout time.Duration
+
+	// Reaper recovers projects that stopped progressing (see reaper.go).
+	Reaper ReaperConfig
+
+	// DurationFit re-times or rewrites narration so videos land near their
+	// target length (see durationfit.go).
+	DurationFit DurationFitConfig
+
+	// SourceFetcher fetches research-mode source URLs (nil = over HTTP).
+	SourceFetcher services.SourceFetcher
+}
+
+// defaultDrainTimeout applies when Config.DrainTimeout is unset.
+const defaultDrainTimeout = 2 * time.Minute
+
 func New(
 	database *db.DB,
 	q *queue.Queue,
@@ -49,8 +108,16 @@ func New(
 	veoSvc *services.VeoService,
 	xaiVideoSvc *services.XAIVideoService,
 	ffmpegSvc *services.FFmpegService,
-	backgroundMusicPath string,
+	cfg Config,
 ) *Worker {
+	drainTimeout := cfg.DrainTimeout
+	if drainTimeout <= 0 {
+		drainTimeout = defaultDrainTimeout
+	}
+	sourceFetcher := cfg.SourceFetcher
+	if sourceFetcher == nil {
+		sourceFetcher = services.NewHTTPSourceFetcher()
+	}
 	return &Worker{
 		db:                  database,
 		queue:               q,
@@ -61,7 +128,17 @@ func New(
 		veo:                 veoSvc,
 		xaiVideo:            xaiVideoSvc,
 		ffmpeg:              ffmpegSvc,
-		backgroundMusicPath: backgroundMusicPath,
+		backgroundMusicPath: cfg.BackgroundMusicPath,
+		subtitleAlignment:   cfg.SubtitleAlignment,
+		imageRewrites:       cfg.ImageRewriteAttempts,
+		cacheEnabled:        cfg.CacheEnabled,
+		cacheTTL:            cfg.CacheTTL,
+		cacheMaxEntries:     cfg.CacheMaxEntries,
+		drainTimeout:        drainTimeout,
+		status:              newRunStatus(),
+		reaper:              cfg.Reaper,
+		durationFit:         cfg.DurationFit,
+		sourceFetcher:       sourceFetcher,
 		uploadSem:           make(chan struct{}, 3), // Supabase concurrent uploads
 		geminiSem:           make(chan struct{}, 2), // Gemini image gen (heavy, rate-limited)
 		ttsSem:              make(chan struct{}, 4), // TTS calls (lightweight, higher throughput)
@@ -73,18 +150,42 @@ func New(
 // withSemaphore wraps a function call with a semaphore to bound concurrency.
 // It acquires a slot, runs fn, and releases the slot when done.
 // If the context is cancelled while waiting, it returns immediately.
-func (w *Worker) withSemaphore(ctx context.Context, sem chan struct{}, label string, fn func() error) error {
-	log.Printf("[%s] waiting for slot...", label)
+func (w *Worker) withSemaphore(ctx context.Context, sem chan struct{}, label string, fn func() error) (err error) {
+	// One span per pipeline stage, covering the wait for a slot and the work
+	_, span := tracing.Start(ctx, "stage "+semaphoreName(label), attribute.String("step", label))
+	defer func() { tracing.End(span, err) }()
+
+	joblog.Printf(ctx, "[%s] waiting for slot...", label)
+	waitStart := time.Now()
 	select {
 	case sem <- struct{}{}:
 		// Acquired slot
+		wait := time.Since(waitStart)
+		metrics.ObserveSemaphoreWait(semaphoreName(label), wait)
+		span.SetAttributes(attribute.Int64("wait_ms", wait.Milliseconds()))
 	case <-ctx.Done():
 		return fmt.Errorf("%s cancelled while waiting for slot: %w", label, ctx.Err())
 	}
 	defer func() { <-sem }()
 
-	log.Printf("[%s] acquired slot, running...", label)
-	return fn()
+	joblog.Printf(ctx, "[%s] acquired slot, running...", label)
+	start := time.Now()
+	err = fn()
+	fields := map[string]interface{}{"step": label, "duration_ms": time.Since(start).Milliseconds()}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	joblog.Event(ctx, fmt.Sprintf("[%s] finished", label), fields)
+	return err
+}
+
+// semaphoreName is the metrics label for a withSemaphore label: its prefix
+// before ":" ("Gemini", "TTS", "xAI", "Render", "Upload").
+func semaphoreName(label string) string {
+	if i := strings.Index(label, ":"); i >= 0 {
+		return label[:i]
+	}
+	return label
 }
 
 // uploadWithLimit wraps an upload call with the upload semaphore.
@@ -92,22 +193,88 @@ func (w *Worker) uploadWithLimit(ctx context.Context, label string, fn func() er
 	return w.withSemaphore(ctx, w.uploadSem, "Upload:"+label, fn)
 }
 
-// Start begins processing jobs from all queues
-func (w *Worker) Start(ctx context.Context, concurrency int) {
-	log.Printf("Worker started with concurrency: %d", concurrency)
+// Concurrency is the number of jobs each queue processes at once. Render-heavy
+// workers can run more process_clip/render_final consumers and light
+// planning workers more generate_plan ones; 0 leaves a queue unconsumed.
+type Concurrency struct {
+	GeneratePlan int
+	ProcessClip  int
+	RenderFinal  int
+}
+
+// UniformConcurrency consumes every queue with n jobs at once.
+func UniformConcurrency(n int) Concurrency {
+	return Concurrency{GeneratePlan: n, ProcessClip: n, RenderFinal: n}
+}
 
-	// Start workers for each queue type
-	for i := 0; i < concurrency; i++ {
-		go w.processQueue(ctx, queue.QueueGeneratePlan, w.handleGeneratePlan)
-		go w.processQueue(ctx, queue.QueueProcessClip, w.handleProcessClip)
-		go w.processQueue(ctx, queue.QueueRenderFinal, w.handleRenderFinal)
+// byQueue maps each queue to its consumer count.
+func (c Concurrency) byQueue() map[string]int {
+	return map[string]int{
+		queue.QueueGeneratePlan: c.GeneratePlan,
+		queue.QueueProcessClip:  c.ProcessClip,
+		queue.QueueRenderFinal:  c.RenderFinal,
 	}
+}
+
+// Start processes jobs until ctx is cancelled, then drains: no new jobs are
+// taken, and in-flight jobs get the drain timeout to finish. Jobs still
+// running after that are cancelled and put back at the head of their queue
+// for another worker. Start returns once every job has finished or been
+// requeued.
+func (w *Worker) Start(ctx context.Context, concurrency Concurrency) {
+	joblog.Printf(ctx, "Worker %s started with concurrency: generate_plan=%d process_clip=%d render_final=%d",
+		w.status.id, concurrency.GeneratePlan, concurrency.ProcessClip, concurrency.RenderFinal)
+	queues := concurrency.byQueue()
+	w.status.queues.Store(&queues)
+
+	// In-flight jobs run on their own context so shutdown doesn't abort
+	// them; it is only cancelled when the drain timeout runs out
+	jobsCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+
+	handlers := map[string]func(context.Context, *queue.Job) error{
+		queue.QueueGeneratePlan: w.handleGeneratePlan,
+		queue.QueueProcessClip:  w.handleProcessClip,
+		queue.QueueRenderFinal:  w.handleRenderFinal,
+	}
+	var wg sync.WaitGroup
+	for queueName, n := range queues {
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w.processQueue(ctx, jobsCtx, queueName, handlers[queueName])
+			}()
+		}
+	}
+
+	stopHeartbeat := w.startHeartbeat(ctx)
+	defer stopHeartbeat()
+
+	go w.runReaper(ctx)
 
 	<-ctx.Done()
-	log.Println("Worker shutting down...")
+	w.status.draining.Store(true)
+	joblog.Printf(ctx, "Worker shutting down, draining %d in-flight jobs (timeout %s)...", w.status.inFlight.Load(), w.drainTimeout)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(w.drainTimeout):
+		joblog.Warnf(ctx, "Drain timeout reached, requeueing %d in-flight jobs", w.status.inFlight.Load())
+		cancelJobs()
+		<-done
+	}
+	joblog.Printf(ctx, "Worker drained")
 }
 
-func (w *Worker) processQueue(ctx context.Context, queueName string, handler func(context.Context, *queue.Job) error) {
+// processQueue takes jobs from queueName until ctx is cancelled. Jobs run on
+// jobsCtx, which outlives ctx during a drain.
+func (w *Worker) processQueue(ctx, jobsCtx context.Context, queueName string, handler func(context.Context, *queue.Job) error) {
 	for {
 		select {
 		case <-ctx.Done():
@@ -115,7 +282,10 @@ func (w *Worker) processQueue(ctx context.Context, queueName string, handler fun
 		default:
 			job, err := w.queue.Dequeue(ctx, queueName, 5*time.Second)
 			if err != nil {
-				log.Printf("Error dequeuing from %s: %v", queueName, err)
+				if ctx.Err() != nil {
+					return // Shutting down
+				}
+				joblog.Errorf(ctx, "Error dequeuing from %s: %v", queueName, err)
 				continue
 			}
 
@@ -123,29 +293,102 @@ func (w *Worker) processQueue(ctx context.Context, queueName string, handler fun
 				continue // No job available, retry
 			}
 
-			log.Printf("Processing job %s (type: %s, project: %s)", job.ID, job.Type, job.ProjectID)
+			w.runJob(jobsCtx, queueName, job, handler)
+		}
+	}
+}
 
-			// Update job status to running
-			if err := w.db.UpdateJobStatus(ctx, job.ID, models.JobStatusRunning); err != nil {
-				log.Printf("Failed to update job status: %v", err)
-			}
+// runJob runs one job and records its outcome. A job cut short by the drain
+// timeout is requeued rather than failed.
+func (w *Worker) runJob(ctx context.Context, queueName string, job *queue.Job, handler func(context.Context, *queue.Job) error) {
+	w.status.inFlight.Add(1)
+	w.status.jobs.Store(job.ID, struct{}{})
+	defer func() {
+		w.status.jobs.Delete(job.ID)
+		w.status.inFlight.Add(-1)
+	}()
+
+	// The job's span continues the trace of whoever enqueued it
+	spanCtx, span := tracing.StartKind(tracing.Extract(ctx, job.Trace), "job "+job.Type, trace.SpanKindConsumer,
+		attribute.String("job.id", job.ID.String()),
+		attribute.String("project.id", job.ProjectID.String()),
+	)
+	if job.ClipID != nil {
+		span.SetAttributes(attribute.String("clip.id", job.ClipID.String()))
+	}
 
-			// Handle the job
-			if err := handler(ctx, job); err != nil {
-				log.Printf("Job %s failed: %v", job.ID, err)
-				w.db.UpdateJobError(ctx, job.ID, err.Error())
-			} else {
-				log.Printf("Job %s completed successfully", job.ID)
-				w.db.UpdateJobStatus(ctx, job.ID, models.JobStatusSucceeded)
-			}
+	// Everything the job logs (including in services) is also captured
+	// in its job log, stored as a logs asset when the job ends
+	tagged := w.jobContext(spanCtx, job)
+	jobLog := joblog.New()
+	jobCtx := joblog.WithLog(tagged, jobLog)
+	started := time.Now()
+
+	joblog.Printf(jobCtx, "Processing job %s (type: %s, project: %s)", job.ID, job.Type, job.ProjectID)
+
+	// Update job status to running
+	if err := w.db.UpdateJobStatus(ctx, job.ID, models.JobStatusRunning); err != nil {
+		joblog.Warnf(jobCtx, "Failed to update job status: %v", err)
+	}
+
+	// Handle the job
+	err := handler(jobCtx, job)
+
+	// Bookkeeping must happen even if the drain timeout cancelled the job
+	finishCtx := context.WithoutCancel(jobCtx)
+	if err != nil && ctx.Err() != nil {
+		joblog.Warnf(finishCtx, "Job %s interrupted by shutdown after %dms, requeueing: %v", job.ID, time.Since(started).Milliseconds(), err)
+		w.storeJobLog(context.WithoutCancel(tagged), job, jobLog)
+		if requeueErr := w.queue.Requeue(finishCtx, queueName, job); requeueErr != nil {
+			joblog.Errorf(finishCtx, "Failed to requeue job %s: %v", job.ID, requeueErr)
+			w.db.UpdateJobError(finishCtx, job.ID, err.Error())
+		} else {
+			w.db.UpdateJobStatus(finishCtx, job.ID, models.JobStatusQueued)
 		}
+		tracing.End(span, err)
+		return
+	}
+
+	metrics.ObserveJob(job.Type, time.Since(started), err)
+	if err != nil {
+		w.status.failed.Add(1)
+		joblog.Errorf(jobCtx, "Job %s failed after %dms: %v", job.ID, time.Since(started).Milliseconds(), err)
+	} else {
+		w.status.processed.Add(1)
+		joblog.Event(jobCtx, fmt.Sprintf("Job %s completed successfully", job.ID), map[string]interface{}{"duration_ms": time.Since(started).Milliseconds()})
+	}
+
+	// Store the log first so it's linked by the time the job shows as finished
+	w.storeJobLog(tagged, job, jobLog)
+
+	if err != nil {
+		w.db.UpdateJobError(ctx, job.ID, err.Error())
+	} else {
+		w.db.UpdateJobStatus(ctx, job.ID, models.JobStatusSucceeded)
 	}
+	tracing.End(span, err)
+}
+
+// jobContext tags ctx with the job's correlation IDs — request, project,
+// clip, job and attempt — so every log line of the job carries them.
+func (w *Worker) jobContext(ctx context.Context, job *queue.Job) context.Context {
+	ctx = joblog.WithRequestID(ctx, job.RequestID)
+	ctx = joblog.With(ctx, "project_id", job.ProjectID, "job_id", job.ID, "job_type", job.Type)
+	if job.ClipID != nil {
+		ctx = joblog.With(ctx, "clip_id", *job.ClipID)
+	}
+
+	// Failed runs increment jobs.attempts, so this run is attempts+1
+	if dbJob, err := w.db.GetJob(ctx, job.ID); err == nil {
+		ctx = joblog.With(ctx, "attempt", dbJob.Attempts+1)
+	}
+	return ctx
 }
 
 // handleGeneratePlan generates the video plan, creates clip records,
 // and enqueues process_clip jobs for each clip (images generated independently per clip)
 func (w *Worker) handleGeneratePlan(ctx context.Context, job *queue.Job) error {
-	log.Printf("Generating plan for project %s", job.ProjectID)
+	joblog.Printf(ctx, "Generating plan for project %s", job.ProjectID)
 
 	// Update project status
 	if err := w.db.UpdateProjectStatus(ctx, job.ProjectID, models.ProjectStatusPlanning); err != nil {
@@ -181,6 +424,21 @@ func (w *Worker) handleGeneratePlan(ctx context.Context, job *queue.Job) error {
 		CTA:         project.CTA,
 		Language:    project.Language,
 	}
+	// Two or more mapped speaker roles switch the planner to dialogue mode
+	if voices := w.resolveSpeakerVoices(ctx, project); len(voices) >= 2 {
+		planOpts.Speakers = services.OrderSpeakers(voices)
+		joblog.Printf(ctx, "Project %s: dialogue mode with speakers %v", job.ProjectID, planOpts.Speakers)
+	}
+
+	// Research mode: the plan is grounded in snippets of the project's sources
+	if len(project.Sources) > 0 {
+		snippets, err := services.Research(ctx, w.sourceFetcher, project.Topic, project.Sources)
+		if err != nil {
+			w.db.UpdateProjectError(ctx, job.ProjectID, "research_failed", err.Error())
+			return fmt.Errorf("failed to research sources: %w", err)
+		}
+		planOpts.Sources = snippets
+	}
 
 	// Generate plan with OpenAI
 	plan, err := w.openai.GeneratePlan(ctx, project.Topic, project.TargetDurationSeconds, seriesGuidance, planOpts)
@@ -189,6 +447,17 @@ func (w *Worker) handleGeneratePlan(ctx context.Context, job *queue.Job) error {
 		return fmt.Errorf("failed to generate plan: %w", err)
 	}
 
+	// Record what plan validation couldn't get repaired (cleared on a clean plan)
+	if len(plan.ValidationWarnings) > 0 {
+		joblog.Warnf(ctx, "Plan has %d validation warnings: %v", len(plan.ValidationWarnings), plan.ValidationWarnings)
+	}
+	if err := w.db.SetProjectPlanWarnings(ctx, job.ProjectID, plan.ValidationWarnings); err != nil {
+		joblog.Warnf(ctx, "Could not record plan warnings: %v", err)
+	}
+	if err := w.db.SetProjectPublishing(ctx, job.ProjectID, plan.Publishing); err != nil {
+		joblog.Warnf(ctx, "Could not record publishing metadata: %v", err)
+	}
+
 	// Store plan as JSON asset
 	planJSON, _ := json.MarshalIndent(plan, "", "  ")
 	planAsset := &models.Asset{
@@ -218,10 +487,12 @@ func (w *Worker) handleGeneratePlan(ctx context.Context, job *queue.Job) error {
 			ProjectID:             job.ProjectID,
 			ClipIndex:             i,
 			Script:                clipPlan.Script,
+			ScriptLines:           clipPlan.Lines,
 			VoiceStyleInstruction: &clipPlan.VoiceStyleInstruction,
 			ImagePrompt:           clipPlan.ImagePrompt,
 			VideoPrompt:           &clipPlan.VideoPrompt,
 			EstimatedDurationSec:  intPtr(clipPlan.EstimatedDurationSec),
+			Citations:             services.CitationsFor(clipPlan.Citations, planOpts.Sources),
 			Status:                models.ClipStatusPending,
 		}
 
@@ -247,7 +518,12 @@ func (w *Worker) handleGeneratePlan(ctx context.Context, job *queue.Job) error {
 			return fmt.Errorf("failed to enqueue clip job: %w", err)
 		}
 
-		log.Printf("Enqueued process_clip for clip %d/%d (id: %s)", i+1, len(plan.Clips), clip.ID)
+		joblog.Printf(ctx, "Enqueued process_clip for clip %d/%d (id: %s)", i+1, len(plan.Clips), clip.ID)
+	}
+
+	// Hook variants are only generated when a render is requested
+	if err := w.createHookVariants(ctx, job.ProjectID, plan.HookVariants, planOpts.Sources); err != nil {
+		return err
 	}
 
 	// Update project status to generating
@@ -260,7 +536,7 @@ func (w *Worker) handleProcessClip(ctx context.Context, job *queue.Job) error {
 		return fmt.Errorf("clip ID missing")
 	}
 
-	log.Printf("Processing clip %s for project %s", *job.ClipID, job.ProjectID)
+	joblog.Printf(ctx, "Processing clip %s for project %s", *job.ClipID, job.ProjectID)
 
 	// Get clip
 	clip, err := w.db.GetClip(ctx, *job.ClipID)
@@ -268,12 +544,41 @@ func (w *Worker) handleProcessClip(ctx context.Context, job *queue.Job) error {
 		return fmt.Errorf("failed to get clip: %w", err)
 	}
 
+	if clip.HookVariant != nil {
+		return w.processHookVariant(ctx, job, clip)
+	}
+
+	// A retry of a clip that did render or was skipped (e.g. the final-render
+	// claim failed) only has the claim left to do
+	if (clip.Status == models.ClipStatusRendered && clip.ClipVideoAssetID != nil) || clip.Status == models.ClipStatusSkipped {
+		joblog.Printf(ctx, "Clip %d: already %s", clip.ClipIndex, clip.Status)
+		return w.triggerFinalRender(ctx, job.ProjectID)
+	}
+
 	// Get project
 	project, err := w.db.GetProject(ctx, job.ProjectID)
 	if err != nil {
 		return fmt.Errorf("failed to get project: %w", err)
 	}
 
+	if err := w.generateClip(ctx, job, clip, project, w.loadClipCheckpoint(ctx, clip)); err != nil {
+		// Jobs interrupted by shutdown are requeued, never skipped
+		if ctx.Err() != nil || clipFailurePolicy(project) != models.ClipFailurePolicySkip {
+			return err
+		}
+		if skipErr := w.skipClip(ctx, clip, err); skipErr != nil {
+			return skipErr
+		}
+	}
+
+	return w.triggerFinalRender(ctx, job.ProjectID)
+}
+
+// generateClip produces a clip's image, narration and subtitle timings and
+// renders its video, resuming from the outputs in cp (see loadClipCheckpoint).
+func (w *Worker) generateClip(ctx context.Context, job *queue.Job, clip *models.Clip, project *models.Project, cp *clipCheckpoint) error {
+	var err error
+
 	// Get graphics preset
 	var preset *models.GraphicsPreset
 	if project.GraphicsPresetID != nil {
@@ -296,6 +601,17 @@ func (w *Worker) handleProcessClip(ctx context.Context, job *queue.Job) error {
 	//   - goroutine lifecycle management
 	// ─────────────────────────────────────────────────────────────────────
 
+	// Outputs stored by earlier attempts of this clip: only missing steps run
+	if cp.resumed() {
+		joblog.Event(ctx, fmt.Sprintf("Clip %d: resuming from previous attempt", clip.ClipIndex), map[string]interface{}{
+			"clip_id":  clip.ID,
+			"image":    cp.image != nil,
+			"ai_video": cp.aiVideoData != nil,
+			"audio":    cp.audio != nil,
+			"words":    cp.words != nil,
+		})
+	}
+
 	// Shared results — written by one goroutine each, read only after g.Wait()
 	var (
 		imageData      []byte
@@ -308,6 +624,10 @@ func (w *Worker) handleProcessClip(ctx context.Context, job *queue.Job) error {
 
 	g, gctx := errgroup.WithContext(ctx)
 
+	// Pipeline B hands the measured narration length to Pipeline A, which uses
+	// it to size the AI video. Buffered so B never blocks on A.
+	audioDurationCh := make(chan int, 1)
+
 	// Build per-project options from the project's customization fields
 	imageOpts := &services.ImageGenOptions{
 		AspectRatio: project.AspectRatio,
@@ -321,85 +641,153 @@ func (w *Worker) handleProcessClip(ctx context.Context, job *queue.Job) error {
 	if project.VoiceID != nil && *project.VoiceID != "" {
 		projectVoiceID = *project.VoiceID
 	}
-	// Per-project language for Whisper transcription
-	whisperLanguage := "en"
+	// TTS provider: the project's pinned provider, or the fallback chain
+	tts, err := w.ttsForProject(project)
+	if err != nil {
+		w.db.UpdateClipError(ctx, clip.ID, err.Error())
+		return err
+	}
+	// Dialogue clips voice each line with its speaker's voice
+	var speakerVoices map[string]string
+	var speakers []string
+	if len(clip.ScriptLines) > 0 {
+		speakerVoices = w.resolveSpeakerVoices(ctx, project)
+		speakers = services.OrderSpeakers(speakerVoices)
+		// The speakers' voice IDs belong to the first provider; a fallback
+		// would voice them all alike, so the clip fails with it instead
+		if chain, ok := tts.(*services.ChainTTSService); ok {
+			tts = chain.Primary()
+		}
+	}
+	// Per-project language for TTS (model and voice selection) and Whisper transcription
+	language := "en"
 	if project.Language != nil && *project.Language != "" {
-		whisperLanguage = *project.Language
+		language = *project.Language
 	}
+	// Pronunciations and script markup change what TTS says, not what viewers
+	// read: subtitles are always mapped back onto the plain script text
+	lexicon := w.projectLexicon(ctx, project)
+	// Series-level base voice settings; each clip's style instruction adjusts them
+	voiceSettings := w.projectVoiceSettings(ctx, project)
+	plainScript := services.PlainScript(clip.Script)
+	spokenDiffers := services.HasScriptMarkup(clip.Script) || lexicon.Matches(plainScript)
 
 	// ── Pipeline A: Visual (image → upload → AI video) ─────────────────
-	g.Go(func() error {
-		// A1: Generate image (bounded by geminiSem)
-		log.Printf("Clip %d: generating image...", clip.ClipIndex)
-		if err := w.withSemaphore(gctx, w.geminiSem, fmt.Sprintf("Gemini:clip_%d", clip.ClipIndex), func() error {
-			var genErr error
-			imageData, genErr = w.gemini.GenerateImage(gctx, clip.ImagePrompt, preset, imageOpts)
-			return genErr
-		}); err != nil {
-			w.db.UpdateClipError(gctx, clip.ID, fmt.Sprintf("Image generation failed: %v", err))
-			return fmt.Errorf("failed to generate image: %w", err)
-		}
-		log.Printf("Clip %d: image generated (%d bytes), uploading...", clip.ClipIndex, len(imageData))
-
-		// A2: Upload image to Supabase
-		imageAsset = &models.Asset{
-			ID:            uuid.New(),
-			ProjectID:     job.ProjectID,
-			ClipID:        &clip.ID,
-			Type:          models.AssetTypeImage,
-			StorageBucket: w.storage.Bucket,
-			StoragePath:   w.storage.GenerateStoragePath(job.ProjectID, fmt.Sprintf("clip_%d_image.png", clip.ClipIndex)),
-			ContentType:   strPtr("image/png"),
-			ByteSize:      int64Ptr(int64(len(imageData))),
-		}
-
-		if err := w.uploadWithLimit(gctx, fmt.Sprintf("clip_%d_image", clip.ClipIndex), func() error {
-			return w.storage.Upload(gctx, imageAsset.StoragePath, imageData, "image/png")
-		}); err != nil {
-			return fmt.Errorf("failed to upload image: %w", err)
-		}
-
-		if err := w.db.CreateAsset(gctx, imageAsset); err != nil {
-			return fmt.Errorf("failed to save image asset: %w", err)
+	g.Go(func() (pipelineErr error) {
+		gctx, span := tracing.Start(gctx, "pipeline.visual")
+		defer func() { tracing.End(span, pipelineErr) }()
+
+		// Set when the failure policy replaced a failed image
+		var imageRecovery models.ClipRecovery
+
+		// A1: Reuse this clip's image from a previous attempt or a cached image
+		// for identical inputs, or generate one (bounded by geminiSem)
+		imageKey := providerCacheKey(gctx, cacheKindImage, w.gemini.Model(), clip.ImagePrompt, preset, project.AspectRatio)
+		if cp.image != nil {
+			imageAsset, imageData = cp.image, cp.imageData
+			if clip.Recovery != nil {
+				imageRecovery = models.ClipRecovery(*clip.Recovery)
+			}
+			joblog.Printf(ctx, "Clip %d: image reused from previous attempt (%d bytes)", clip.ClipIndex, len(imageData))
+		} else if cached := w.cacheLookup(gctx, project, imageKey); cached != nil {
+			imageData = cached.Data
+			var reuseErr error
+			if imageAsset, reuseErr = w.reuseCachedAsset(gctx, cached, job.ProjectID, clip.ID); reuseErr != nil {
+				return reuseErr
+			}
+			joblog.Printf(ctx, "Clip %d: image reused from cache (%d bytes)", clip.ClipIndex, len(imageData))
+		} else {
+			joblog.Printf(ctx, "Clip %d: generating image...", clip.ClipIndex)
+			var err error
+			if imageData, err = w.generateClipImage(gctx, clip, preset, imageOpts); err != nil {
+				// The project's failure policy may provide a stand-in image
+				var recoverErr error
+				if imageAsset, imageData, imageRecovery, recoverErr = w.recoverImage(gctx, project, clip, preset, imageOpts, err); recoverErr != nil {
+					w.db.UpdateClipError(gctx, clip.ID, fmt.Sprintf("Image generation failed: %v", recoverErr))
+					return fmt.Errorf("failed to generate image: %w", recoverErr)
+				}
+			} else {
+				joblog.Printf(ctx, "Clip %d: image generated (%d bytes), uploading...", clip.ClipIndex, len(imageData))
+
+				// A2: Upload image to Supabase
+				var storeErr error
+				if imageAsset, storeErr = w.storeClipImage(gctx, job.ProjectID, clip, imageData); storeErr != nil {
+					return storeErr
+				}
+				w.cacheStore(gctx, imageKey, cacheKindImage, imageAsset, imageData, nil)
+			}
 		}
 		if err := w.db.UpdateClipImage(gctx, clip.ID, imageAsset.ID); err != nil {
 			return fmt.Errorf("failed to update clip image: %w", err)
 		}
 
 		// A3: AI video generation (non-critical — failure falls back to Ken Burns)
-		if w.xaiVideo != nil && clip.VideoPrompt != nil && *clip.VideoPrompt != "" {
+		if cp.aiVideoData != nil {
+			aiVideoData = cp.aiVideoData
+			joblog.Printf(ctx, "Clip %d: AI video reused from previous attempt (%d bytes)", clip.ClipIndex, len(aiVideoData))
+		} else if cp.kenBurns {
+			joblog.Printf(ctx, "Clip %d: keeping Ken Burns effects", clip.ClipIndex)
+		} else if imageRecovery == models.ClipRecoveryNeighbourImage || imageRecovery == models.ClipRecoveryTextCard {
+			// The video prompt describes a scene the stand-in image doesn't show
+			joblog.Printf(ctx, "Clip %d: stand-in image (%s), using Ken Burns effects", clip.ClipIndex, imageRecovery)
+		} else if w.xaiVideo != nil && clip.VideoPrompt != nil && *clip.VideoPrompt != "" {
 			// Use the public URL for xAI image-to-video generation.
 			// The Supabase bucket must be set to "public" in the dashboard.
 			// Signed URLs can fail with 404 due to format/policy mismatches.
 			imagePublicURL := w.storage.GetPublicURL(imageAsset.StoragePath)
 
-			// Use estimated_duration_sec from the plan to control xAI video length.
-			// This prevents generating video longer than needed (wasting xAI tokens).
-			// xAI clamps this to 1-15s internally; 0 means use default (8s).
+			// Size the xAI video to the measured narration (plus the prepended silence)
+			// so we don't generate video longer than needed (wasting xAI tokens).
+			// Falls back to estimated_duration_sec from the plan if the audio
+			// duration isn't available. xAI clamps this to 1-15s internally; 0 means use default (8s).
 			xaiDuration := 0
 			if clip.EstimatedDurationSec != nil {
 				xaiDuration = *clip.EstimatedDurationSec
 			}
+			select {
+			case audioMs := <-audioDurationCh:
+				if audioMs > 0 {
+					xaiDuration = int(math.Ceil(float64(audioMs+clipSilenceMs) / 1000.0))
+				}
+			case <-gctx.Done():
+				return gctx.Err()
+			}
 
-			log.Printf("Clip %d: generating xAI video from image (url=%s, duration=%ds)...", clip.ClipIndex, imagePublicURL, xaiDuration)
-			if xaiErr := w.withSemaphore(gctx, w.xaiSem, fmt.Sprintf("xAI:clip_%d", clip.ClipIndex), func() error {
-				var genErr error
-				aiVideoData, genErr = w.xaiVideo.GenerateVideo(gctx, *clip.VideoPrompt, imagePublicURL, xaiDuration, videoOpts)
-				return genErr
-			}); xaiErr != nil {
-				log.Printf("Clip %d: xAI video generation failed, falling back to Ken Burns effects: %v", clip.ClipIndex, xaiErr)
-				aiVideoData = nil
+			// The source image is keyed by content: a regenerated image with the
+			// same prompt would otherwise hit a video made from a different frame
+			imageSum := sha256.Sum256(imageData)
+			videoKey := providerCacheKey(gctx, cacheKindVideo, w.xaiVideo.Model(), *clip.VideoPrompt, videoOpts, hex.EncodeToString(imageSum[:]), xaiDuration)
+			if cached := w.cacheLookup(gctx, project, videoKey); cached != nil {
+				aiVideoData = cached.Data
+				if _, reuseErr := w.reuseCachedAsset(gctx, cached, job.ProjectID, clip.ID); reuseErr != nil {
+					joblog.Warnf(ctx, "Clip %d: %v", clip.ClipIndex, reuseErr)
+				}
+				joblog.Printf(ctx, "Clip %d: xAI video reused from cache (%d bytes)", clip.ClipIndex, len(aiVideoData))
 			} else {
-				log.Printf("Clip %d: xAI video generated (%d bytes)", clip.ClipIndex, len(aiVideoData))
+				joblog.Printf(ctx, "Clip %d: generating xAI video from image (url=%s, duration=%ds)...", clip.ClipIndex, imagePublicURL, xaiDuration)
+				if xaiErr := w.withSemaphore(gctx, w.xaiSem, fmt.Sprintf("xAI:clip_%d", clip.ClipIndex), func() error {
+					var genErr error
+					aiVideoData, genErr = w.xaiVideo.GenerateVideo(gctx, *clip.VideoPrompt, imagePublicURL, xaiDuration, videoOpts)
+					return genErr
+				}); xaiErr != nil {
+					joblog.Warnf(ctx, "Clip %d: xAI video generation failed, falling back to Ken Burns effects: %v", clip.ClipIndex, xaiErr)
+					metrics.IncAIVideoFallback("xai")
+					aiVideoData = nil
+				} else {
+					joblog.Printf(ctx, "Clip %d: xAI video generated (%d bytes)", clip.ClipIndex, len(aiVideoData))
+					w.storeAIVideo(gctx, job.ProjectID, clip, videoKey, aiVideoData)
+				}
 			}
 		} else if w.veo != nil && clip.VideoPrompt != nil && *clip.VideoPrompt != "" {
-			log.Printf("Clip %d: generating Veo video from image...", clip.ClipIndex)
+			joblog.Printf(ctx, "Clip %d: generating Veo video from image...", clip.ClipIndex)
 			aiVideoData, err = w.veo.GenerateVideo(gctx, *clip.VideoPrompt, imageData, "image/png")
 			if err != nil {
-				log.Printf("Clip %d: Veo video generation failed, falling back to Ken Burns effects: %v", clip.ClipIndex, err)
+				joblog.Warnf(ctx, "Clip %d: Veo video generation failed, falling back to Ken Burns effects: %v", clip.ClipIndex, err)
+				metrics.IncAIVideoFallback("veo")
 				aiVideoData = nil
 			} else {
-				log.Printf("Clip %d: Veo video generated (%d bytes)", clip.ClipIndex, len(aiVideoData))
+				joblog.Printf(ctx, "Clip %d: Veo video generated (%d bytes)", clip.ClipIndex, len(aiVideoData))
+				w.storeAIVideo(gctx, job.ProjectID, clip, "", aiVideoData)
 			}
 		}
 
@@ -407,59 +795,163 @@ func (w *Worker) handleProcessClip(ctx context.Context, job *queue.Job) error {
 	})
 
 	// ── Pipeline B: Audio (TTS → upload → Whisper) ─────────────────────
-	g.Go(func() error {
+	g.Go(func() (pipelineErr error) {
+		gctx, span := tracing.Start(gctx, "pipeline.audio")
+		defer func() { tracing.End(span, pipelineErr) }()
+
 		// B1: Generate audio
 		voiceStyle := "natural and engaging"
 		if clip.VoiceStyleInstruction != nil {
 			voiceStyle = *clip.VoiceStyleInstruction
 		}
 
-		log.Printf("Clip %d: generating audio...", clip.ClipIndex)
+		speechReq := services.SpeechRequest{
+			Text:       clip.Script,
+			VoiceStyle: voiceStyle,
+			VoiceID:    projectVoiceID,
+			Language:   language,
+			Lexicon:    lexicon,
+			Settings:   voiceSettings,
+		}
+		if clip.SpeechPace != nil {
+			speechReq.Pace = *clip.SpeechPace
+		}
+
+		// Everything that determines the narration: model(s), provider pin,
+		// text, voice, language, lexicon, settings and dialogue voices
+		audioKey := providerCacheKey(gctx, cacheKindAudio, services.TTSModelName(tts), project.TTSProvider, speechReq, clip.ScriptLines, speakerVoices)
+
 		var audioResp *services.TTSResponse
-		if err := w.withSemaphore(gctx, w.ttsSem, fmt.Sprintf("TTS:clip_%d", clip.ClipIndex), func() error {
-			var genErr error
-			audioResp, genErr = w.tts.GenerateSpeech(gctx, clip.Script, voiceStyle, projectVoiceID)
-			return genErr
-		}); err != nil {
-			w.db.UpdateClipError(gctx, clip.ID, fmt.Sprintf("TTS failed: %v", err))
-			return fmt.Errorf("failed to generate audio: %w", err)
+		var cachedAudio *cachedOutput
+		var ttsErr error
+		if cp.audio != nil {
+			// Provider word timings aren't stored with the audio; cp.words
+			// (or Whisper) covers subtitles
+			audioResp = &services.TTSResponse{AudioData: cp.audioData, DurationMs: *clip.AudioDurationMs}
+		} else {
+			cachedAudio = w.cacheLookup(gctx, project, audioKey)
+		}
+		if cachedAudio != nil {
+			var cacheErr error
+			if audioResp, cacheErr = ttsResponseFromCache(cachedAudio); cacheErr != nil {
+				joblog.Warnf(ctx, "Clip %d: %v, regenerating audio", clip.ClipIndex, cacheErr)
+				cachedAudio = nil
+			} else {
+				joblog.Printf(ctx, "Clip %d: audio reused from cache", clip.ClipIndex)
+			}
+		}
+		if audioResp == nil && len(clip.ScriptLines) > 0 {
+			joblog.Printf(ctx, "Clip %d: generating dialogue audio (%d lines)...", clip.ClipIndex, len(clip.ScriptLines))
+			audioResp, ttsErr = w.synthesizeDialogue(gctx, tts, clip, speechReq, speakerVoices)
+		} else if audioResp == nil {
+			joblog.Printf(ctx, "Clip %d: generating audio...", clip.ClipIndex)
+			ttsErr = w.withSemaphore(gctx, w.ttsSem, fmt.Sprintf("TTS:clip_%d", clip.ClipIndex), func() error {
+				var genErr error
+				audioResp, genErr = tts.GenerateSpeech(gctx, speechReq)
+				return genErr
+			})
+		}
+		if ttsErr != nil {
+			w.db.UpdateClipError(gctx, clip.ID, fmt.Sprintf("TTS failed: %v", ttsErr))
+			return fmt.Errorf("failed to generate audio: %w", ttsErr)
 		}
 		audioData = audioResp.AudioData
-		log.Printf("Clip %d: audio generated (%d bytes)", clip.ClipIndex, len(audioData))
-
-		// B2: Upload audio to Supabase
-		audioAsset = &models.Asset{
-			ID:            uuid.New(),
-			ProjectID:     job.ProjectID,
-			ClipID:        &clip.ID,
-			Type:          models.AssetTypeAudio,
-			StorageBucket: w.storage.Bucket,
-			StoragePath:   w.storage.GenerateStoragePath(job.ProjectID, fmt.Sprintf("clip_%d_audio.mp3", clip.ClipIndex)),
-			ContentType:   strPtr("audio/mpeg"),
-			ByteSize:      int64Ptr(int64(len(audioData))),
+		audioDurationCh <- audioResp.DurationMs
+		if cp.audio != nil {
+			joblog.Printf(ctx, "Clip %d: audio reused from previous attempt (%d bytes, %dms)", clip.ClipIndex, len(audioData), audioResp.DurationMs)
+		} else if audioResp.DurationEstimated {
+			joblog.Printf(ctx, "Clip %d: audio generated (%d bytes, ~%dms estimated)", clip.ClipIndex, len(audioData), audioResp.DurationMs)
+		} else {
+			joblog.Printf(ctx, "Clip %d: audio generated (%d bytes, %dms, %dHz/%dch)", clip.ClipIndex, len(audioData), audioResp.DurationMs, audioResp.SampleRate, audioResp.Channels)
 		}
 
-		if err := w.uploadWithLimit(gctx, fmt.Sprintf("clip_%d_audio", clip.ClipIndex), func() error {
-			return w.storage.Upload(gctx, audioAsset.StoragePath, audioData, "audio/mpeg")
-		}); err != nil {
-			return fmt.Errorf("failed to upload audio: %w", err)
-		}
+		// B2: Upload audio to Supabase (a reused or cached response is already stored)
+		if cp.audio != nil {
+			audioAsset = cp.audio
+		} else if cachedAudio != nil {
+			var reuseErr error
+			if audioAsset, reuseErr = w.reuseCachedAsset(gctx, cachedAudio, job.ProjectID, clip.ID); reuseErr != nil {
+				return reuseErr
+			}
+		} else {
+			// Named by asset ID: a re-narration must not replace the audio
+			// the clip still points at
+			audioID := uuid.New()
+			audioAsset = &models.Asset{
+				ID:            audioID,
+				ProjectID:     job.ProjectID,
+				ClipID:        &clip.ID,
+				Type:          models.AssetTypeAudio,
+				StorageBucket: w.storage.Bucket,
+				StoragePath:   w.storage.GenerateStoragePath(job.ProjectID, clipFileName(clip, fmt.Sprintf("audio_%s.mp3", audioID))),
+				ContentType:   strPtr("audio/mpeg"),
+				ByteSize:      int64Ptr(int64(len(audioData))),
+			}
+
+			if err := w.uploadWithLimit(gctx, fmt.Sprintf("clip_%d_audio", clip.ClipIndex), func() error {
+				return w.storage.Upload(gctx, audioAsset.StoragePath, audioData, "audio/mpeg")
+			}); err != nil {
+				return fmt.Errorf("failed to upload audio: %w", err)
+			}
 
-		if err := w.db.CreateAsset(gctx, audioAsset); err != nil {
-			return fmt.Errorf("failed to save audio asset: %w", err)
+			if err := w.db.CreateAsset(gctx, audioAsset); err != nil {
+				return fmt.Errorf("failed to save audio asset: %w", err)
+			}
+			// The key names the whole chain: a fallback's audio isn't what
+			// those inputs ask for once the primary provider is back
+			if !audioResp.Fallback {
+				w.cacheStore(gctx, audioKey, cacheKindAudio, audioAsset, audioData, ttsCacheEntryMetadata(audioResp))
+			}
 		}
-		if err := w.db.UpdateClipAudio(gctx, clip.ID, audioAsset.ID, audioResp.DurationMs); err != nil {
+		if cp.staged != nil {
+			cp.staged.audio, cp.staged.audioDurationMs = audioAsset, audioResp.DurationMs
+		} else if err := w.db.UpdateClipAudio(gctx, clip.ID, audioAsset.ID, audioResp.DurationMs); err != nil {
 			return fmt.Errorf("failed to update clip audio: %w", err)
 		}
 
-		// B3: Whisper transcription for subtitles (non-critical — failure is OK)
-		log.Printf("Clip %d: transcribing audio for subtitles (lang=%s)...", clip.ClipIndex, whisperLanguage)
-		wordTimestamps, err = w.openai.TranscribeAudio(gctx, audioData, whisperLanguage)
-		if err != nil {
-			log.Printf("Clip %d: WARNING — Whisper transcription failed, rendering without subtitles: %v", clip.ClipIndex, err)
-			wordTimestamps = nil
+		// B3: Subtitle timings (non-critical — failure is OK).
+		// Prefer provider-supplied timings (on the text sent to the provider,
+		// so mapped back onto the plain script when markup or pronunciations
+		// changed it); otherwise transcribe with Whisper and, in script mode or
+		// when the spoken text differs, map the transcribed timings back onto
+		// the script tokens. Timings stored by a previous attempt are final.
+		if cp.words != nil {
+			wordTimestamps = cp.words
+			joblog.Printf(ctx, "Clip %d: reusing %d subtitle words from previous attempt", clip.ClipIndex, len(wordTimestamps))
+		} else if len(audioResp.WordTimestamps) > 0 {
+			wordTimestamps = audioResp.WordTimestamps
+			if spokenDiffers {
+				wordTimestamps = services.AlignWordsToScript(plainScript, wordTimestamps)
+			}
+			joblog.Printf(ctx, "Clip %d: using %d provider-aligned words for subtitles", clip.ClipIndex, len(wordTimestamps))
 		} else {
-			log.Printf("Clip %d: transcribed %d words for subtitles", clip.ClipIndex, len(wordTimestamps))
+			joblog.Printf(ctx, "Clip %d: transcribing audio for subtitles (lang=%s)...", clip.ClipIndex, language)
+			wordTimestamps, err = w.openai.TranscribeAudio(gctx, audioData, language)
+			if err != nil {
+				joblog.Warnf(ctx, "Clip %d: Whisper transcription failed, rendering without subtitles: %v", clip.ClipIndex, err)
+				wordTimestamps = nil
+			} else if w.subtitleAlignment == services.AlignmentScript || spokenDiffers {
+				wordTimestamps = services.AlignWordsToScript(plainScript, wordTimestamps)
+				joblog.Printf(ctx, "Clip %d: aligned transcription to %d script words for subtitles", clip.ClipIndex, len(wordTimestamps))
+			} else {
+				joblog.Printf(ctx, "Clip %d: transcribed %d words for subtitles", clip.ClipIndex, len(wordTimestamps))
+			}
+		}
+
+		// Dialogue: tag each word with its line's speaker for per-speaker subtitle colors
+		if cp.words == nil && len(clip.ScriptLines) > 0 && len(wordTimestamps) > 0 {
+			if !services.TagSpeakers(wordTimestamps, clip.ScriptLines) {
+				joblog.Printf(ctx, "Clip %d: subtitle words don't match dialogue lines, rendering single-color subtitles", clip.ClipIndex)
+			}
+		}
+
+		if cp.staged != nil {
+			cp.staged.words = wordTimestamps
+		} else if cp.words == nil && len(wordTimestamps) > 0 {
+			// Persist word timings for project-level sidecar captions (non-critical)
+			if storeErr := w.storeWordTimestamps(gctx, job.ProjectID, clip, wordTimestamps); storeErr != nil {
+				joblog.Warnf(ctx, "Clip %d: could not store word timestamps, sidecar captions will skip this clip: %v", clip.ClipIndex, storeErr)
+			}
 		}
 
 		return nil
@@ -471,43 +963,60 @@ func (w *Worker) handleProcessClip(ctx context.Context, job *queue.Job) error {
 	}
 
 	// ── Render: needs results from both pipelines (bounded by renderSem) ─
-	log.Printf("Clip %d: both pipelines complete, rendering video...", clip.ClipIndex)
+	joblog.Printf(ctx, "Clip %d: both pipelines complete, rendering video...", clip.ClipIndex)
 
 	if err := w.withSemaphore(ctx, w.renderSem, fmt.Sprintf("Render:clip_%d", clip.ClipIndex), func() error {
-		return w.renderClip(ctx, job.ProjectID, clip.ID, audioData, imageData, aiVideoData, wordTimestamps)
+		return w.renderClip(ctx, job.ProjectID, clip.ID, audioData, imageData, aiVideoData, wordTimestamps, speakers, cp.staged)
 	}); err != nil {
 		w.db.UpdateClipError(ctx, clip.ID, fmt.Sprintf("Render failed: %v", err))
 		return fmt.Errorf("failed to render clip: %w", err)
 	}
 
-	log.Printf("Clip %d: rendering complete", clip.ClipIndex)
+	joblog.Printf(ctx, "Clip %d: rendering complete", clip.ClipIndex)
 
-	// Check if all clips are rendered, trigger final render
-	allRendered, err := w.db.AreAllClipsRendered(ctx, job.ProjectID)
-	if err != nil {
-		return fmt.Errorf("failed to check clip status: %w", err)
+	return nil
+}
+
+// storeClipImage uploads a clip's image and saves it as an image asset.
+func (w *Worker) storeClipImage(ctx context.Context, projectID uuid.UUID, clip *models.Clip, data []byte) (*models.Asset, error) {
+	asset := &models.Asset{
+		ID:            uuid.New(),
+		ProjectID:     projectID,
+		ClipID:        &clip.ID,
+		Type:          models.AssetTypeImage,
+		StorageBucket: w.storage.Bucket,
+		StoragePath:   w.storage.GenerateStoragePath(projectID, clipFileName(clip, "image.png")),
+		ContentType:   strPtr("image/png"),
+		ByteSize:      int64Ptr(int64(len(data))),
 	}
 
-	if allRendered {
-		log.Printf("All clips rendered for project %s, enqueuing final render", job.ProjectID)
+	if err := w.uploadWithLimit(ctx, fmt.Sprintf("clip_%d_image", clip.ClipIndex), func() error {
+		return w.storage.Upload(ctx, asset.StoragePath, data, "image/png")
+	}); err != nil {
+		return nil, fmt.Errorf("failed to upload image: %w", err)
+	}
 
-		finalJobID := uuid.New()
-		finalJob := &models.Job{
-			ID:        finalJobID,
-			ProjectID: job.ProjectID,
-			Type:      "render_final",
-			Status:    models.JobStatusQueued,
-		}
+	if err := w.db.CreateAsset(ctx, asset); err != nil {
+		return nil, fmt.Errorf("failed to save image asset: %w", err)
+	}
+	return asset, nil
+}
 
-		if err := w.db.CreateJob(ctx, finalJob); err != nil {
-			return fmt.Errorf("failed to create final render job: %w", err)
-		}
+// triggerFinalRender enqueues the final render if all clips are rendered.
+// Clips finishing together race here; exactly one claims the move to
+// rendering.
+func (w *Worker) triggerFinalRender(ctx context.Context, projectID uuid.UUID) error {
+	finalJobID, claimed, err := w.db.ClaimFinalRender(ctx, projectID, models.ProjectStatusGenerating)
+	if err != nil {
+		return fmt.Errorf("failed to check clip status: %w", err)
+	}
 
-		if err := w.queue.EnqueueRenderFinal(ctx, job.ProjectID, finalJobID); err != nil {
+	if claimed {
+		joblog.Printf(ctx, "All clips rendered for project %s, enqueuing final render", projectID)
+
+		if err := w.queue.EnqueueRenderFinal(ctx, projectID, finalJobID); err != nil {
 			return fmt.Errorf("failed to enqueue final render: %w", err)
 		}
-
-		w.db.UpdateProjectStatus(ctx, job.ProjectID, models.ProjectStatusRendering)
 	}
 
 	return nil
@@ -525,7 +1034,10 @@ func (w *Worker) handleProcessClip(ctx context.Context, job *queue.Job) error {
 // In both paths:
 //   - A 500ms silence buffer is prepended to the audio for natural pauses.
 //   - If word timestamps are available, TikTok-style subtitles are burned into the video.
-func (w *Worker) renderClip(ctx context.Context, projectID, clipID uuid.UUID, audioData, imageData, aiVideoData []byte, wordTimestamps []services.WordTimestamp) error {
+//     speakers (dialogue clips only) orders the roles for per-speaker subtitle colors.
+//
+// A staged re-narration receives the video and its duration instead of the clip.
+func (w *Worker) renderClip(ctx context.Context, projectID, clipID uuid.UUID, audioData, imageData, aiVideoData []byte, wordTimestamps []services.WordTimestamp, speakers []string, staged *clipNarration) error {
 	// Create temp file paths
 	audioRawPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("audio_raw_%s.mp3", clipID.String()))
 	audioPaddedPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("audio_padded_%s.mp3", clipID.String()))
@@ -542,10 +1054,10 @@ func (w *Worker) renderClip(ctx context.Context, projectID, clipID uuid.UUID, au
 
 	// Prepend 500ms silence buffer so the first word isn't clipped
 	// and there's a natural breathing pause between clips
-	const silenceMs = 500
+	const silenceMs = clipSilenceMs
 	silenceUsed := true
 	if err := w.ffmpeg.PrependSilence(ctx, audioRawPath, audioPaddedPath, silenceMs); err != nil {
-		log.Printf("Warning: could not prepend silence, using raw audio: %v", err)
+		joblog.Warnf(ctx, "Could not prepend silence, using raw audio: %v", err)
 		audioPaddedPath = audioRawPath
 		silenceUsed = false
 	}
@@ -559,17 +1071,18 @@ func (w *Worker) renderClip(ctx context.Context, projectID, clipID uuid.UUID, au
 			silenceOffsetSec = float64(silenceMs) / 1000.0
 		}
 		subParams := services.SubtitleParamsForResolution(w.ffmpeg.Resolution)
+		subParams.Speakers = speakers
 		if err := services.GenerateASSSubtitles(wordTimestamps, subtitlePath, silenceOffsetSec, subParams); err != nil {
-			log.Printf("Warning: failed to generate subtitles, rendering without: %v", err)
+			joblog.Warnf(ctx, "Failed to generate subtitles, rendering without: %v", err)
 		} else {
 			subtitleFile = subtitlePath
-			log.Printf("Generated TikTok-style subtitles (%d words, offset=%.1fs)", len(wordTimestamps), silenceOffsetSec)
+			joblog.Printf(ctx, "Generated TikTok-style subtitles (%d words, offset=%.1fs)", len(wordTimestamps), silenceOffsetSec)
 		}
 	}
 
 	if aiVideoData != nil {
 		// ── AI video path: xAI/Veo generated video + narration audio ───
-		log.Printf("Rendering clip with AI video (%d bytes)", len(aiVideoData))
+		joblog.Printf(ctx, "Rendering clip with AI video (%d bytes)", len(aiVideoData))
 
 		aiVideoPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("aivideo_%s.mp4", clipID.String()))
 		defer w.ffmpeg.Cleanup(aiVideoPath)
@@ -585,12 +1098,12 @@ func (w *Worker) renderClip(ctx context.Context, projectID, clipID uuid.UUID, au
 		// ── Ken Burns path: still image + motion effects ────────────────
 		audioDurationMs, err := w.ffmpeg.GetAudioDuration(ctx, audioPaddedPath)
 		if err != nil {
-			log.Printf("Warning: could not get audio duration, estimating 10s: %v", err)
+			joblog.Warnf(ctx, "Could not get audio duration, estimating 10s: %v", err)
 			audioDurationMs = 10000
 		}
 
 		effect := services.RandomEffect()
-		log.Printf("Rendering clip with Ken Burns effect=%s, audioDuration=%dms", effect, audioDurationMs)
+		joblog.Printf(ctx, "Rendering clip with Ken Burns effect=%s, audioDuration=%dms", effect, audioDurationMs)
 
 		imagePath := w.ffmpeg.CreateTempFile(fmt.Sprintf("image_%s.png", clipID.String()))
 		defer w.ffmpeg.Cleanup(imagePath)
@@ -607,11 +1120,13 @@ func (w *Worker) renderClip(ctx context.Context, projectID, clipID uuid.UUID, au
 	// Measure actual rendered clip duration (for analytics — compare vs estimated to optimize xAI token usage)
 	renderedDurationMs, err := w.ffmpeg.GetVideoDuration(ctx, outputPath)
 	if err != nil {
-		log.Printf("Warning: could not measure rendered clip duration: %v", err)
+		joblog.Warnf(ctx, "Could not measure rendered clip duration: %v", err)
 	} else {
-		log.Printf("Clip rendered: actual duration = %dms", renderedDurationMs)
-		if dbErr := w.db.UpdateClipRenderedDuration(ctx, clipID, renderedDurationMs); dbErr != nil {
-			log.Printf("Warning: could not store rendered clip duration: %v", dbErr)
+		joblog.Printf(ctx, "Clip rendered: actual duration = %dms", renderedDurationMs)
+		if staged != nil {
+			staged.renderedDurationMs = &renderedDurationMs
+		} else if dbErr := w.db.UpdateClipRenderedDuration(ctx, clipID, renderedDurationMs); dbErr != nil {
+			joblog.Warnf(ctx, "Could not store rendered clip duration: %v", dbErr)
 		}
 	}
 
@@ -621,14 +1136,15 @@ func (w *Worker) renderClip(ctx context.Context, projectID, clipID uuid.UUID, au
 		return fmt.Errorf("failed to read rendered video: %w", err)
 	}
 
-	// Upload video
+	// Upload video, named by asset ID so the clip's current video stays intact
+	videoID := uuid.New()
 	videoAsset := &models.Asset{
-		ID:            uuid.New(),
+		ID:            videoID,
 		ProjectID:     projectID,
 		ClipID:        &clipID,
 		Type:          models.AssetTypeClipVideo,
 		StorageBucket: w.storage.Bucket,
-		StoragePath:   w.storage.GenerateStoragePath(projectID, fmt.Sprintf("clip_%s.mp4", clipID.String())),
+		StoragePath:   w.storage.GenerateStoragePath(projectID, fmt.Sprintf("clip_%s_%s.mp4", clipID, videoID)),
 		ContentType:   strPtr("video/mp4"),
 		ByteSize:      int64Ptr(int64(len(videoData))),
 	}
@@ -643,66 +1159,169 @@ func (w *Worker) renderClip(ctx context.Context, projectID, clipID uuid.UUID, au
 		return fmt.Errorf("failed to save video asset: %w", err)
 	}
 
+	if staged != nil {
+		staged.video = videoAsset
+		return nil
+	}
 	return w.db.UpdateClipVideo(ctx, clipID, videoAsset.ID)
 }
 
 // handleRenderFinal concatenates all clips into final video
 func (w *Worker) handleRenderFinal(ctx context.Context, job *queue.Job) error {
-	log.Printf("Rendering final video for project %s", job.ProjectID)
+	joblog.Printf(ctx, "Rendering final video for project %s", job.ProjectID)
 
-	// Get all clips ordered by index
-	clips, err := w.db.GetProjectClips(ctx, job.ProjectID)
+	// A redelivered job (drain requeue, recovery) must not render twice
+	project, err := w.db.GetProject(ctx, job.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to get project: %w", err)
+	}
+	if project.Status == models.ProjectStatusCompleted && project.FinalVideoAssetID != nil {
+		joblog.Printf(ctx, "Project %s already has its final video, skipping", job.ProjectID)
+		return nil
+	}
+
+	// Get all clips ordered by index, without those dropped by the skip policy
+	allClips, err := w.db.GetProjectClips(ctx, job.ProjectID)
 	if err != nil {
 		return fmt.Errorf("failed to get clips: %w", err)
 	}
+	clips := make([]models.Clip, 0, len(allClips))
+	for _, clip := range allClips {
+		if clip.Status != models.ClipStatusSkipped {
+			clips = append(clips, clip)
+		}
+	}
+	if len(clips) == 0 {
+		w.db.UpdateProjectError(ctx, job.ProjectID, "all_clips_skipped", "Every clip failed and was skipped")
+		return fmt.Errorf("all %d clips were skipped", len(allClips))
+	}
+	if skipped := len(allClips) - len(clips); skipped > 0 {
+		joblog.Printf(ctx, "Rendering %d clips, %d skipped after failing", len(clips), skipped)
+	}
+
+	// Re-time or rewrite narration so the video lands near its target length
+	if clips, err = w.fitDuration(ctx, job, project, clips); err != nil {
+		return err
+	}
+
+	videoData, clipDurationsMs, err := w.composeVideo(ctx, project, job.ProjectID.String(), clips)
+	if err != nil {
+		if errors.Is(err, errConcatFailed) {
+			w.db.UpdateProjectError(ctx, job.ProjectID, "concat_failed", err.Error())
+		}
+		return err
+	}
 
-	// Collect clip video paths
+	// Upload final video
+	finalAsset := &models.Asset{
+		ID:            uuid.New(),
+		ProjectID:     job.ProjectID,
+		Type:          models.AssetTypeFinalVideo,
+		StorageBucket: w.storage.Bucket,
+		StoragePath:   w.storage.GenerateStoragePath(job.ProjectID, "final.mp4"),
+		ContentType:   strPtr("video/mp4"),
+		ByteSize:      int64Ptr(int64(len(videoData))),
+	}
+
+	if err := w.uploadWithLimit(ctx, "final_video", func() error {
+		return w.storage.Upload(ctx, finalAsset.StoragePath, videoData, "video/mp4")
+	}); err != nil {
+		w.db.UpdateProjectError(ctx, job.ProjectID, "upload_failed", err.Error())
+		return fmt.Errorf("failed to upload final video: %w", err)
+	}
+
+	if err := w.db.CreateAsset(ctx, finalAsset); err != nil {
+		return fmt.Errorf("failed to save final video asset: %w", err)
+	}
+
+	// Export sidecar captions (SRT/WebVTT/ASS) — non-critical, the video is still usable without them
+	if err := w.exportSubtitles(ctx, job.ProjectID, clips, clipDurationsMs, w.projectSpeakers(ctx, job.ProjectID)); err != nil {
+		joblog.Warnf(ctx, "Sidecar subtitle export failed for project %s: %v", job.ProjectID, err)
+	}
+
+	// Render the covers (thumbnails) — non-critical as well
+	if err := w.exportCovers(ctx, project, clips); err != nil {
+		joblog.Warnf(ctx, "Cover export failed for project %s: %v", job.ProjectID, err)
+	}
+
+	// Update project
+	return w.db.SetProjectFinalVideo(ctx, job.ProjectID, finalAsset.ID)
+}
+
+// errConcatFailed marks a composeVideo failure in the concat step.
+var errConcatFailed = errors.New("failed to concatenate clips")
+
+// composeVideo concatenates the rendered clips, in order, and mixes in the
+// background music, ending on the sources card when the project asks for
+// one. It returns the video and the clips' rendered durations. name keeps
+// the temp files of concurrent compositions apart.
+func (w *Worker) composeVideo(ctx context.Context, project *models.Project, name string, clips []models.Clip) ([]byte, []int, error) {
+	// Collect clip video paths and rendered durations (durations drive sidecar caption offsets)
 	var clipPaths []string
+	clipDurationsMs := make([]int, 0, len(clips))
 	for _, clip := range clips {
 		if clip.ClipVideoAssetID == nil {
-			return fmt.Errorf("clip %d has no video", clip.ClipIndex)
+			return nil, nil, fmt.Errorf("clip %d has no video", clip.ClipIndex)
 		}
 
 		asset, err := w.db.GetAsset(ctx, *clip.ClipVideoAssetID)
 		if err != nil {
-			return fmt.Errorf("failed to get clip video asset: %w", err)
+			return nil, nil, fmt.Errorf("failed to get clip video asset: %w", err)
 		}
 
 		// Download clip video from storage
 		videoData, err := w.storage.Download(ctx, asset.StoragePath)
 		if err != nil {
-			return fmt.Errorf("failed to download clip video: %w", err)
+			return nil, nil, fmt.Errorf("failed to download clip video: %w", err)
 		}
 
 		// Write to temp file
-		tempPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("clip_%d.mp4", clip.ClipIndex))
+		tempPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("final_%s_clip_%d.mp4", name, clip.ClipIndex))
 		if err := os.WriteFile(tempPath, videoData, 0644); err != nil {
-			return fmt.Errorf("failed to write clip video file: %w", err)
+			return nil, nil, fmt.Errorf("failed to write clip video file: %w", err)
 		}
 
 		clipPaths = append(clipPaths, tempPath)
+
+		durationMs := 0
+		if clip.RenderedDurationMs != nil {
+			durationMs = *clip.RenderedDurationMs
+		} else if probed, err := w.ffmpeg.GetVideoDuration(ctx, tempPath); err == nil {
+			durationMs = probed
+		} else {
+			joblog.Warnf(ctx, "Could not determine clip %d duration, captions after it may drift: %v", clip.ClipIndex, err)
+		}
+		clipDurationsMs = append(clipDurationsMs, durationMs)
+	}
+
+	// Research mode: end on the sources the script cites
+	if project.CitationEndCard {
+		if cardPath, err := w.renderSourcesCard(ctx, name, clips); err != nil {
+			joblog.Warnf(ctx, "Sources end card failed, rendering without it: %v", err)
+		} else if cardPath != "" {
+			clipPaths = append(clipPaths, cardPath)
+		}
 	}
 
 	defer w.ffmpeg.Cleanup(clipPaths...)
 
 	// Step 1: Concatenate all clips into one video
-	concatPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("concat_%s.mp4", job.ProjectID.String()))
+	concatPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("concat_%s.mp4", name))
 	defer w.ffmpeg.Cleanup(concatPath)
 
 	if err := w.ffmpeg.ConcatenateClips(ctx, clipPaths, concatPath); err != nil {
-		w.db.UpdateProjectError(ctx, job.ProjectID, "concat_failed", err.Error())
-		return fmt.Errorf("failed to concatenate clips: %w", err)
+		return nil, nil, fmt.Errorf("%w: %w", errConcatFailed, err)
 	}
 
 	// Step 2: Mix background music into the concatenated video
 	// Music loops if shorter than video, and ends when the video ends
-	outputPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("final_%s.mp4", job.ProjectID.String()))
+	outputPath := w.ffmpeg.CreateTempFile(fmt.Sprintf("final_%s.mp4", name))
 	defer w.ffmpeg.Cleanup(outputPath)
 
 	if w.backgroundMusicPath != "" {
 		if err := w.ffmpeg.MixBackgroundMusic(ctx, concatPath, w.backgroundMusicPath, outputPath); err != nil {
 			// Music mixing failed — fall back to the concatenated video without music
-			log.Printf("Warning: background music mixing failed, using video without music: %v", err)
+			joblog.Warnf(ctx, "Background music mixing failed, using video without music: %v", err)
 			outputPath = concatPath
 		}
 	} else {
@@ -713,33 +1332,64 @@ func (w *Worker) handleRenderFinal(ctx context.Context, job *queue.Job) error {
 	// Read final video
 	videoData, err := os.ReadFile(outputPath)
 	if err != nil {
-		return fmt.Errorf("failed to read final video: %w", err)
+		return nil, nil, fmt.Errorf("failed to read final video: %w", err)
 	}
 
-	// Upload final video
-	finalAsset := &models.Asset{
-		ID:            uuid.New(),
-		ProjectID:     job.ProjectID,
-		Type:          models.AssetTypeFinalVideo,
-		StorageBucket: w.storage.Bucket,
-		StoragePath:   w.storage.GenerateStoragePath(job.ProjectID, "final.mp4"),
-		ContentType:   strPtr("video/mp4"),
-		ByteSize:      int64Ptr(int64(len(videoData))),
+	return videoData, clipDurationsMs, nil
+}
+
+// ttsForProject returns the TTS service for a project. Projects that pin a
+// provider get only that provider (its voice IDs mean nothing to the others);
+// everyone else uses the configured fallback chain.
+func (w *Worker) ttsForProject(project *models.Project) (services.TTSService, error) {
+	if project.TTSProvider == nil || *project.TTSProvider == "" {
+		return w.tts, nil
 	}
 
-	if err := w.uploadWithLimit(ctx, "final_video", func() error {
-		return w.storage.Upload(ctx, finalAsset.StoragePath, videoData, "video/mp4")
-	}); err != nil {
-		w.db.UpdateProjectError(ctx, job.ProjectID, "upload_failed", err.Error())
-		return fmt.Errorf("failed to upload final video: %w", err)
+	selector, ok := w.tts.(services.TTSProviderSelector)
+	if !ok {
+		return nil, fmt.Errorf("project requests TTS provider %q but the worker has a single fixed provider", *project.TTSProvider)
 	}
+	return selector.WithProvider(*project.TTSProvider)
+}
 
-	if err := w.db.CreateAsset(ctx, finalAsset); err != nil {
-		return fmt.Errorf("failed to save final video asset: %w", err)
+// projectLexicon loads the pronunciation lexicon for a project (global, user
+// and series entries). A lookup failure is logged and yields an empty lexicon —
+// narration still works, just with the provider's default pronunciations.
+func (w *Worker) projectLexicon(ctx context.Context, project *models.Project) services.Lexicon {
+	entries, err := w.db.ListPronunciations(ctx, project.UserID, project.SeriesID)
+	if err != nil {
+		joblog.Warnf(ctx, "Could not load pronunciation lexicon for project %s: %v", project.ID, err)
+		return nil
 	}
 
-	// Update project
-	return w.db.SetProjectFinalVideo(ctx, job.ProjectID, finalAsset.ID)
+	lexicon := make(services.Lexicon, 0, len(entries))
+	for _, e := range entries {
+		rule := services.PronunciationRule{Term: e.Term}
+		if e.Alias != nil {
+			rule.Alias = *e.Alias
+		}
+		if e.IPA != nil {
+			rule.IPA = *e.IPA
+		}
+		lexicon = append(lexicon, rule)
+	}
+	return lexicon
+}
+
+// projectVoiceSettings returns the base voice settings from the project's
+// series default_voice_profile (stability, similarity_boost, style, speed,
+// volume), or nil to use provider defaults.
+func (w *Worker) projectVoiceSettings(ctx context.Context, project *models.Project) *services.VoiceSettings {
+	if project.SeriesID == nil {
+		return nil
+	}
+	series, err := w.db.GetSeries(ctx, *project.SeriesID)
+	if err != nil {
+		joblog.Warnf(ctx, "Could not load series %s voice profile: %v", *project.SeriesID, err)
+		return nil
+	}
+	return services.VoiceSettingsFromProfile(series.DefaultVoiceProfile)
 }
 
 // Helper functions
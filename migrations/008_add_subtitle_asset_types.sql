@@ -0,0 +1,16 @@
+-- Migration 008: Subtitle asset types
+--
+-- Whisper word timestamps used to be discarded after burning captions into each
+-- clip. They are now persisted per clip (word_timestamps, JSON) and stitched at
+-- final render into project-level sidecar captions:
+--   subtitles_srt — SubRip, for YouTube and most upload platforms
+--   subtitles_vtt — WebVTT, for HTML5 <track> playback
+--   subtitles_ass — full-project ASS with the same styling as the burned-in captions
+--
+-- ALTER TYPE ... ADD VALUE cannot run inside a transaction block on older
+-- Postgres versions, so run this file without wrapping it in BEGIN/COMMIT.
+
+ALTER TYPE asset_type ADD VALUE IF NOT EXISTS 'word_timestamps';
+ALTER TYPE asset_type ADD VALUE IF NOT EXISTS 'subtitles_srt';
+ALTER TYPE asset_type ADD VALUE IF NOT EXISTS 'subtitles_vtt';
+ALTER TYPE asset_type ADD VALUE IF NOT EXISTS 'subtitles_ass';
@@ -0,0 +1,13 @@
+-- Migration 009: Multi-voice dialogue narration
+--
+-- Projects can map speaker roles to voices. When two or more roles are mapped
+-- (on the project, or via the series' default_voice_profile.speakers), the
+-- planner writes speaker-tagged lines and each line is voiced separately.
+--
+-- speaker_voices example: {"narrator": "pNInz6obpgDQGcFmaJgB", "character_a": "EXAVITQu4vr4xnSDxMaL"}
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS speaker_voices JSONB;
+
+-- Speaker-tagged lines for a clip, in spoken order. NULL = single narrator.
+-- script_lines example: [{"speaker": "narrator", "text": "..."}, {"speaker": "character_a", "text": "..."}]
+-- clips.script still holds the full text (all lines joined) for subtitles and search.
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS script_lines JSONB;
@@ -0,0 +1,9 @@
+-- Migration 010: Per-project TTS provider selection
+--
+-- Voice IDs are provider-specific: a Cartesia voice ID means nothing to
+-- ElevenLabs and vice versa. Projects that set voice_id (or speaker_voices)
+-- for one provider pin it here so the worker never sends them elsewhere.
+--
+-- NULL = use the configured fallback chain (TTS_PROVIDERS).
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS tts_provider TEXT
+    CHECK (tts_provider IN ('elevenlabs', 'cartesia'));
@@ -0,0 +1,35 @@
+-- Migration 011: Pronunciation lexicon
+--
+-- Tells TTS how to say names, brands and jargon. Each entry maps a term
+-- (matched case-sensitively as a whole word) to a phonetic respelling (alias)
+-- and/or an IPA transcription. Only the synthesized speech changes — scripts
+-- and subtitles keep the original spelling.
+--
+-- Scope:
+--   user_id and series_id NULL → global entry
+--   user_id set               → applies to that user's projects
+--   series_id set             → applies to projects in that series
+-- When the same term is defined at several levels, series beats user beats global.
+
+CREATE TABLE IF NOT EXISTS pronunciations (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id    UUID REFERENCES users(id) ON DELETE CASCADE,
+    series_id  UUID REFERENCES series(id) ON DELETE CASCADE,
+    term       TEXT NOT NULL,
+    alias      TEXT,                                      -- e.g. "Kee-AH-no"
+    ipa        TEXT,                                      -- e.g. "kiˈɑːnoʊ"
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (alias IS NOT NULL OR ipa IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pronunciations_user_id ON pronunciations(user_id);
+CREATE INDEX IF NOT EXISTS idx_pronunciations_series_id ON pronunciations(series_id);
+
+-- Auto-update updated_at on changes
+CREATE TRIGGER update_pronunciations_updated_at BEFORE UPDATE ON pronunciations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- RLS with no policies: only the backend (service_role) can read or write (see 004)
+ALTER TABLE pronunciations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE pronunciations FORCE ROW LEVEL SECURITY;
@@ -0,0 +1,40 @@
+-- Migration 012: Provider output cache
+--
+-- Retries, regenerations and duplicate topics used to re-call Gemini, TTS and
+-- xAI with identical inputs. provider_cache maps a content hash of everything
+-- that determines a provider's output (provider, model, prompt, voice,
+-- settings, ...) to the asset that holds the result, so the worker can reuse
+-- the asset instead of paying for the call again.
+--
+--   expires_at   NULL = never expires (PROVIDER_CACHE_TTL_HOURS=0)
+--   last_used_at drives LRU eviction down to PROVIDER_CACHE_MAX_ENTRIES
+--
+-- Evicting an entry only forgets it — the asset stays with the project that
+-- produced it.
+--
+-- ALTER TYPE ... ADD VALUE cannot run inside a transaction block on older
+-- Postgres versions, so run this file without wrapping it in BEGIN/COMMIT.
+
+-- AI-generated clip video (xAI), stored so it can be served from the cache
+ALTER TYPE asset_type ADD VALUE IF NOT EXISTS 'ai_video';
+
+CREATE TABLE IF NOT EXISTS provider_cache (
+    cache_key    TEXT PRIMARY KEY,                  -- "<kind>:<sha256 of inputs>"
+    provider     TEXT NOT NULL,                     -- "gemini", "tts", "xai"
+    asset_id     UUID NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    metadata     JSONB,                             -- e.g. audio duration and word timings
+    hit_count    INTEGER NOT NULL DEFAULT 0,
+    created_at   TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    expires_at   TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_provider_cache_last_used_at ON provider_cache(last_used_at);
+CREATE INDEX IF NOT EXISTS idx_provider_cache_expires_at ON provider_cache(expires_at);
+
+-- RLS with no policies: only the backend (service_role) can read or write (see 004)
+ALTER TABLE provider_cache ENABLE ROW LEVEL SECURITY;
+ALTER TABLE provider_cache FORCE ROW LEVEL SECURITY;
+
+-- Per-project opt-out: always call the providers (results still refresh the cache)
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS bypass_cache BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,7 @@
+-- Migration 013: Stalled-project recovery
+--
+-- The worker's reaper re-enqueues the missing steps of projects that stopped
+-- progressing (a worker died mid-job, a job was lost from Redis, ...). Each
+-- recovery is counted here; past the limit the project is failed instead of
+-- retried forever.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS recovery_attempts INTEGER NOT NULL DEFAULT 0;
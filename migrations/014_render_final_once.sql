@@ -0,0 +1,11 @@
+-- Migration 014: Exactly-once final render
+--
+-- Clips finishing at the same moment could each see "all clips rendered" and
+-- each enqueue render_final, producing duplicate final videos. The move to
+-- rendering is now a conditional update (see db.ClaimFinalRender), and each
+-- plan version of a project gets at most one render_final job.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS plan_version INTEGER;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_render_final_once
+    ON jobs(project_id, plan_version)
+    WHERE type = 'render_final';
@@ -0,0 +1,26 @@
+-- Migration 015: Partial-failure policy
+--
+-- A single failed clip (e.g. an image refusal) used to leave its project
+-- stuck before the final render. Projects now choose what happens to a clip
+-- that fails:
+--
+--   fail            the clip stays failed (default; the reaper retries it)
+--   rewrite_prompt  image failures retry once with a rewritten, safer prompt
+--   fallback_visual image failures use a neighbouring clip's image, or a
+--                   text card of the script when no neighbour has one
+--   skip            the clip is dropped and the video renders without it
+--
+-- The recovery applied to a clip is recorded on it (recovery) together with
+-- the error that triggered it (recovery_reason).
+--
+-- ALTER TYPE ... ADD VALUE cannot run inside a transaction block on older
+-- Postgres versions, so run this file without wrapping it in BEGIN/COMMIT.
+
+ALTER TYPE clip_status ADD VALUE IF NOT EXISTS 'skipped';
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS clip_failure_policy TEXT
+    CHECK (clip_failure_policy IN ('fail', 'rewrite_prompt', 'fallback_visual', 'skip'));
+
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS recovery TEXT
+    CHECK (recovery IN ('rewritten_prompt', 'neighbour_image', 'text_card', 'skipped'));
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS recovery_reason TEXT;
@@ -0,0 +1,11 @@
+-- Migration 016: Image prompt rewrites
+--
+-- When the image model refuses a clip's prompt (safety block, text-only
+-- answer, ...), the worker has the planner LLM rewrite it and retries, up to
+-- IMAGE_REWRITE_ATTEMPTS times. Every rewrite is appended here for auditing:
+--
+--   [{"prompt": "...", "reason": "gemini refused the image (IMAGE_SAFETY)",
+--     "succeeded": false, "created_at": "..."}, ...]
+--
+-- image_prompt keeps the planner's original prompt.
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS image_prompt_rewrites JSONB;
@@ -0,0 +1,11 @@
+-- Migration 017: Plan validation warnings
+--
+-- Generated plans are validated (clip count and durations against the
+-- target, script length against narration pace, required fields, the CTA in
+-- the last clip) and sent back to the planner for repair. Issues a repair
+-- didn't fix are kept on the project as a JSON array of strings:
+--
+--   ["clip 2: the script has 41 words (~18 seconds spoken) but ..."]
+--
+-- NULL means the plan passed validation (or predates it).
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS plan_warnings JSONB;
@@ -0,0 +1,10 @@
+-- Migration 018: Duration fitting
+--
+-- Before the final render the worker compares the narration's real length
+-- with target_duration_seconds. Outside the tolerance it re-times the
+-- narration (a speech speed change within limits, stored here so retries
+-- keep it) or has the planner shorten or extend some clip scripts, and
+-- re-synthesizes only the clips it changed.
+--
+-- NULL means the voice's normal pace (1.0).
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS speech_pace REAL;
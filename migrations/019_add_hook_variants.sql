@@ -0,0 +1,22 @@
+-- Migration 019: Hook variants and publishing metadata
+--
+-- Planning now also produces alternative versions of the first clip (the
+-- hook) and per-platform titles, descriptions and hashtags.
+--
+-- Hook variants are clip rows with clip_index 0 and hook_variant 1, 2, ...
+-- (NULL = the main video's clips). They are only generated when requested
+-- (POST /v1/projects/{id}/hooks/{variant}/render); the full video rendered
+-- with the variant in place of the first clip is variant_video_asset_id, so
+-- a hook can be A/B tested without paying for the rest of the video twice.
+--
+-- publishing holds {"tiktok": {"title": ..., "description": ...,
+-- "hashtags": [...]}, "youtube_shorts": {...}, "instagram_reels": {...}}.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS publishing JSONB;
+
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS hook_variant INTEGER;
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS variant_video_asset_id UUID;
+
+-- Variants share clip_index 0 with the main first clip
+ALTER TABLE clips DROP CONSTRAINT IF EXISTS clips_project_id_clip_index_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_clips_project_index_variant
+    ON clips(project_id, clip_index, COALESCE(hook_variant, 0));
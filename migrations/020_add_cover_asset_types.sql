@@ -0,0 +1,13 @@
+-- Migration 020: Cover asset types
+--
+-- The project list used clip 0's raw image as its thumbnail. The final render
+-- now also produces dedicated covers: a still from the video with the title
+-- overlaid in the caption style, in the platform sizes:
+--   cover_landscape — 1280x720, YouTube thumbnail
+--   cover_portrait  — 1080x1920, TikTok / Shorts / Reels cover
+--
+-- ALTER TYPE ... ADD VALUE cannot run inside a transaction block on older
+-- Postgres versions, so run this file without wrapping it in BEGIN/COMMIT.
+
+ALTER TYPE asset_type ADD VALUE IF NOT EXISTS 'cover_landscape';
+ALTER TYPE asset_type ADD VALUE IF NOT EXISTS 'cover_portrait';
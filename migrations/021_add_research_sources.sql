@@ -0,0 +1,12 @@
+-- Migration 021: Research mode
+--
+-- A project can be given source documents (URLs to fetch, or text) to ground
+-- its script in. Planning is shown snippets of the sources and each clip keeps
+-- the snippets backing its claims as citations:
+--   [{"source": 1, "title": "...", "url": "...", "snippet": "..."}]
+--
+-- citation_end_card appends a card listing the cited sources to the video.
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS sources JSONB;
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS citation_end_card BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE clips ADD COLUMN IF NOT EXISTS citations JSONB;